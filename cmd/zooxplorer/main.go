@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"math"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/jowiho/zooxplorer/internal/format"
+	"github.com/jowiho/zooxplorer/internal/policy"
 	"github.com/jowiho/zooxplorer/internal/snapshot"
 	"github.com/jowiho/zooxplorer/internal/tui"
 )
@@ -22,16 +31,63 @@ type loadDoneMsg struct {
 	err  error
 }
 
+// parseGroup tracks the snapshot parses running in the background, the
+// first load and reloads, so that quitting can wait for them to wind down.
+type parseGroup struct {
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// start registers a parse, or reports false once the group is stopped.
+func (g *parseGroup) start() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopped {
+		return false
+	}
+	g.wg.Add(1)
+	return true
+}
+
+// stop keeps new parses from starting and waits for the running ones.
+func (g *parseGroup) stop() {
+	g.mu.Lock()
+	g.stopped = true
+	g.mu.Unlock()
+	g.wg.Wait()
+}
+
+// spillThreshold is the node data size above which data is kept in a temp
+// file instead of memory until it is viewed.
+const spillThreshold = 16 * 1024 * 1024
+
 type appModel struct {
+	// sourcePath is the snapshot file or data directory given on the
+	// command line, which reloading resolves again.
+	sourcePath   string
 	snapshotPath string
+	startPath    string
+	strictACLs   bool
+	verifySum    bool
+	maxDataLen   int32
+	policy       *policy.Policy
+	baseline     *snapshot.Baseline
+	viewState    *tui.ViewState
+	tree         *snapshot.Tree
 	events       chan tea.Msg
-	loading      bool
-	loadErr      error
-	readBytes    int64
-	totalBytes   int64
-	width        int
-	height       int
-	ui           tea.Model
+	parses       *parseGroup
+	// cancelLoad aborts the parse, or the reload, when quitting while it
+	// still runs.
+	loadCtx    context.Context
+	cancelLoad context.CancelFunc
+	loading    bool
+	loadErr    error
+	readBytes  int64
+	totalBytes int64
+	width      int
+	height     int
+	ui         tea.Model
 }
 
 var (
@@ -43,27 +99,48 @@ var (
 )
 
 func newAppModel(snapshotPath string) appModel {
+	ctx, cancel := context.WithCancel(context.Background())
 	return appModel{
 		snapshotPath: snapshotPath,
 		events:       make(chan tea.Msg, 256),
+		parses:       &parseGroup{},
+		loadCtx:      ctx,
+		cancelLoad:   cancel,
 		loading:      true,
 	}
 }
 
 func (m appModel) Init() tea.Cmd {
-	return tea.Batch(startLoadCmd(m.snapshotPath, m.events), waitLoadEventCmd(m.events))
+	return tea.Batch(startLoadCmd(m.loadCtx, m.parses, m.snapshotPath, m.loadOptions(), m.events), waitLoadEventCmd(m.events))
+}
+
+// loadOptions returns the parse options for the snapshot, without progress
+// reporting.
+func (m appModel) loadOptions() snapshot.ParseOptions {
+	return snapshot.ParseOptions{
+		StrictACLSchemes: m.strictACLs,
+		VerifyChecksum:   m.verifySum,
+		SpillThreshold:   spillThreshold,
+		MaxBufferLen:     m.maxDataLen,
+	}
 }
 
-func startLoadCmd(path string, events chan tea.Msg) tea.Cmd {
+func startLoadCmd(ctx context.Context, parses *parseGroup, path string, opts snapshot.ParseOptions, events chan tea.Msg) tea.Cmd {
 	return func() tea.Msg {
+		if !parses.start() {
+			return nil
+		}
 		go func() {
-			tree, err := snapshot.ParseFileWithProgress(path, func(readBytes, totalBytes int64) {
+			defer parses.wg.Done()
+			opts.Progress = func(readBytes, totalBytes int64) {
 				msg := loadProgressMsg{read: readBytes, total: totalBytes}
 				select {
 				case events <- msg:
 				default:
 				}
-			})
+			}
+			opts.Context = ctx
+			tree, err := snapshot.ParseFileWithOptions(path, opts)
 			events <- loadDoneMsg{tree: tree, err: err}
 		}()
 		return nil
@@ -78,6 +155,18 @@ func waitLoadEventCmd(events chan tea.Msg) tea.Cmd {
 
 func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if !m.loading && m.loadErr == nil && m.ui != nil {
+		switch msg := msg.(type) {
+		case tea.WindowSizeMsg:
+			// Kept for the model that a reload opens.
+			m.width = msg.Width
+			m.height = msg.Height
+		case tui.ReloadMsg:
+			return m.startReload()
+		case loadProgressMsg:
+			return m, waitLoadEventCmd(m.events)
+		case loadDoneMsg:
+			return m.finishReload(msg)
+		}
 		var cmd tea.Cmd
 		m.ui, cmd = m.ui.Update(msg)
 		return m, cmd
@@ -89,7 +178,8 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 	case tea.KeyMsg:
 		switch msg.String() {
-		case "ctrl+q":
+		case "ctrl+q", "ctrl+c":
+			m.cancelLoad()
 			return m, tea.Quit
 		}
 		if m.loadErr != nil {
@@ -101,18 +191,12 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, waitLoadEventCmd(m.events)
 	case loadDoneMsg:
 		m.loading = false
-		if msg.err != nil {
-			m.loadErr = msg.err
+		warning, err := loadWarning(msg)
+		if err != nil {
+			m.loadErr = err
 			return m, nil
 		}
-		ui := tui.NewModel(msg.tree)
-		m.ui = ui
-		if m.width > 0 && m.height > 0 {
-			var cmd tea.Cmd
-			m.ui, cmd = m.ui.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
-			return m, cmd
-		}
-		return m, nil
+		return m.openTree(msg.tree, warning, m.startPath, m.viewState)
 	}
 
 	if m.loading {
@@ -121,6 +205,102 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// loadWarning returns the warning to show for a parse that only read part of
+// the snapshot, or the error of a parse that failed.
+func loadWarning(msg loadDoneMsg) (string, error) {
+	if errors.Is(msg.err, snapshot.ErrTruncated) && msg.tree != nil {
+		// Let the nodes read before the snapshot ended be explored.
+		return "Snapshot truncated, showing the nodes read before the cut (see A)", nil
+	}
+	return "", msg.err
+}
+
+// openTree replaces the UI with one exploring tree.
+func (m appModel) openTree(tree *snapshot.Tree, warning, startPath string, state *tui.ViewState) (tea.Model, tea.Cmd) {
+	m.tree = tree
+	m.ui = tui.NewModelWithOptions(tree, tui.Options{
+		SnapshotPath: m.snapshotPath,
+		StartPath:    startPath,
+		Policy:       m.policy,
+		Baseline:     m.baseline,
+		Warning:      warning,
+		State:        state,
+	})
+	titleCmd := windowTitleCmd(m.snapshotPath)
+	if m.width > 0 && m.height > 0 {
+		var cmd tea.Cmd
+		m.ui, cmd = m.ui.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		return m, tea.Batch(cmd, titleCmd)
+	}
+	return m, titleCmd
+}
+
+// startReload parses the snapshot again, picking the latest one again when
+// a data directory was given, while the current tree stays explorable.
+func (m appModel) startReload() (tea.Model, tea.Cmd) {
+	source := m.sourcePath
+	if source == "" {
+		source = m.snapshotPath
+	}
+	path, err := resolveSnapshotPath(source)
+	if err != nil {
+		return m.failReload(err)
+	}
+	m.snapshotPath = path
+	return m, tea.Batch(startLoadCmd(m.loadCtx, m.parses, path, m.loadOptions(), m.events), waitLoadEventCmd(m.events))
+}
+
+// finishReload swaps in the reloaded tree, keeping the expanded nodes and
+// the selection where they still exist.
+func (m appModel) finishReload(msg loadDoneMsg) (tea.Model, tea.Cmd) {
+	warning, err := loadWarning(msg)
+	if err != nil {
+		msg.tree.Close()
+		return m.failReload(err)
+	}
+	var state *tui.ViewState
+	if ui, ok := m.ui.(tui.Model); ok {
+		s := ui.ViewState()
+		state = &s
+	}
+	m.tree.Close()
+	return m.openTree(msg.tree, warning, "", state)
+}
+
+func (m appModel) failReload(err error) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.ui, cmd = m.ui.Update(tui.ReloadFailedMsg{Err: err})
+	return m, cmd
+}
+
+// stopLoading cancels the parses still running when the program ends and
+// waits for them, closing the trees they produced that were never opened
+// so that their spill files do not outlive the program.
+func (m appModel) stopLoading() {
+	m.cancelLoad()
+	m.parses.stop()
+	for {
+		select {
+		case msg := <-m.events:
+			if done, ok := msg.(loadDoneMsg); ok {
+				done.tree.Close()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// saveViewState records where the user is in the snapshot in file, unless
+// the snapshot never opened.
+func (m appModel) saveViewState(file string) error {
+	ui, ok := m.ui.(tui.Model)
+	if !ok {
+		return nil
+	}
+	return tui.SaveViewState(file, m.snapshotPath, ui.ViewState())
+}
+
 func (m appModel) View() string {
 	if !m.loading && m.loadErr == nil && m.ui != nil {
 		return m.ui.View()
@@ -172,6 +352,10 @@ func (m appModel) View() string {
 	details := "Loading snapshot"
 	if m.totalBytes > 0 {
 		details = fmt.Sprintf("Loading snapshot %s / %s", humanBytes(m.readBytes), humanBytes(m.totalBytes))
+	} else if m.readBytes > 0 {
+		// The size is unknown, e.g. for a pipe, so only the bytes read so
+		// far can be shown.
+		details = fmt.Sprintf("Loading snapshot %s", humanBytes(m.readBytes))
 	}
 
 	box := lipgloss.NewStyle().
@@ -188,6 +372,36 @@ func (m appModel) View() string {
 	return lipgloss.Place(width, m.height, lipgloss.Center, lipgloss.Center, box)
 }
 
+// XTWINOPS sequences that save and restore the terminal title. Terminals that
+// do not support them ignore them.
+const (
+	pushTitleSeq = "\x1b[22;0t"
+	popTitleSeq  = "\x1b[23;0t"
+)
+
+func windowTitle(snapshotPath string) string {
+	return "zooxplorer — " + filepath.Base(snapshotPath)
+}
+
+func windowTitleCmd(snapshotPath string) tea.Cmd {
+	return tea.SetWindowTitle(windowTitle(snapshotPath))
+}
+
+// saveTerminalTitle pushes the current title when f is a terminal and returns
+// a function that restores it.
+func saveTerminalTitle(f *os.File) func() {
+	if !isTerminal(f) {
+		return func() {}
+	}
+	fmt.Fprint(f, pushTitleSeq)
+	return func() { fmt.Fprint(f, popTitleSeq) }
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 func humanBytes(v int64) string {
 	if v < 1024 {
 		return fmt.Sprintf("%d B", v)
@@ -202,14 +416,403 @@ func humanBytes(v int64) string {
 	return fmt.Sprintf("%.1f %s", size, units[u])
 }
 
+type cliOptions struct {
+	snapshotPath string
+	startPath    string
+	strictACLs   bool
+	verifySum    bool
+	policyPath   string
+	baselinePath string
+	saveBaseline string
+	maxDataLen   int
+	exportJSON   bool
+	dump         bool
+}
+
+// parseArgs parses the command line. Flags may come before or after the
+// snapshot file so that copied deep links can be pasted as-is.
+func parseArgs(args []string) (cliOptions, error) {
+	fs := flag.NewFlagSet("zooxplorer", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	startPath := fs.String("path", "", "znode to select on startup")
+	strictACLs := fs.Bool("strict-acls", false, "report ACLs with unknown schemes in the audit report")
+	verifySum := fs.Bool("verify-checksum", false, "fail when the snapshot's checksum does not match its data")
+	policyPath := fs.String("policy", "", "JSON ACL policy to check nodes against")
+	baselinePath := fs.String("baseline", "", "baseline file to mark added and changed nodes against")
+	saveBaseline := fs.String("save-baseline", "", "write the snapshot's baseline to this file and exit")
+	exportJSON := fs.Bool("json", false, "write the snapshot's nodes to stdout as JSON and exit")
+	dump := fs.Bool("dump", false, "write each node's path, size and a data preview to stdout and exit")
+	maxDataLen := fs.Int("max-data-len", 0, "largest node data to accept, in bytes (default 256MB)")
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return cliOptions{}, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) != 1 {
+		return cliOptions{}, errors.New("expected exactly one snapshot file")
+	}
+	if *maxDataLen < 0 || *maxDataLen > math.MaxInt32 {
+		return cliOptions{}, fmt.Errorf("-max-data-len must be between 0 and %d", math.MaxInt32)
+	}
+	return cliOptions{
+		snapshotPath: positional[0],
+		startPath:    *startPath,
+		strictACLs:   *strictACLs,
+		verifySum:    *verifySum,
+		policyPath:   *policyPath,
+		baselinePath: *baselinePath,
+		saveBaseline: *saveBaseline,
+		maxDataLen:   *maxDataLen,
+		exportJSON:   *exportJSON,
+		dump:         *dump,
+	}, nil
+}
+
+// resolveSnapshotPath returns path, or the latest snapshot in it when path
+// is a data directory.
+func resolveSnapshotPath(path string) (string, error) {
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return path, nil
+	}
+	return snapshot.LatestInDir(path)
+}
+
+// parseForExport parses the snapshot for the modes that write it out
+// instead of opening the UI.
+func parseForExport(snapshotPath string, maxDataLen int32) (*snapshot.Tree, error) {
+	tree, err := snapshot.ParseFileWithOptions(snapshotPath, snapshot.ParseOptions{
+		SpillThreshold: spillThreshold,
+		MaxBufferLen:   maxDataLen,
+	})
+	if err != nil {
+		tree.Close()
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return tree, nil
+}
+
+// writeBaseline parses the snapshot and saves its baseline to file.
+func writeBaseline(snapshotPath, file string, maxDataLen int32) error {
+	tree, err := parseForExport(snapshotPath, maxDataLen)
+	if err != nil {
+		return err
+	}
+	defer tree.Close()
+	return snapshot.SaveBaseline(file, tree.Baseline())
+}
+
+// writeJSON parses the snapshot and exports its nodes to w.
+func writeJSON(w io.Writer, snapshotPath string, maxDataLen int32) error {
+	tree, err := parseForExport(snapshotPath, maxDataLen)
+	if err != nil {
+		return err
+	}
+	defer tree.Close()
+	return snapshot.ExportJSON(w, tree)
+}
+
+// writeDump parses the snapshot and dumps its nodes to w.
+func writeDump(w io.Writer, snapshotPath string, maxDataLen int32) error {
+	tree, err := parseForExport(snapshotPath, maxDataLen)
+	if err != nil {
+		return err
+	}
+	defer tree.Close()
+	return snapshot.Dump(w, tree)
+}
+
+// parseGetArgs parses the arguments of the get command: the snapshot file
+// and the path of the node to print.
+func parseGetArgs(args []string) (snapshotPath, path string, err error) {
+	if len(args) != 2 {
+		return "", "", errors.New("expected a snapshot file and a node path")
+	}
+	if !strings.HasPrefix(args[1], "/") {
+		return "", "", fmt.Errorf("node path %q must start with /", args[1])
+	}
+	return args[0], args[1], nil
+}
+
+// writeNodeContent parses the snapshot and writes the content of the node at
+// path to w.
+func writeNodeContent(w io.Writer, snapshotPath, path string, color bool) error {
+	tree, err := parseForExport(snapshotPath, 0)
+	if err != nil {
+		return err
+	}
+	defer tree.Close()
+	content, err := nodeContent(tree, path, color)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, content)
+	return err
+}
+
+// nodeContent returns the decoded content of the node at path as lines,
+// highlighted when color is set. Nodes without data have no content.
+func nodeContent(tree *snapshot.Tree, path string, color bool) (string, error) {
+	node, ok := tree.NodesByPath[path]
+	if !ok {
+		return "", fmt.Errorf("no node at %s", path)
+	}
+	if node.DataLen() == 0 {
+		return "", nil
+	}
+	theme := format.Theme{}
+	if color {
+		theme = format.DefaultTheme
+	}
+	return format.ZNodeContentTheme(node.Bytes(), theme) + "\n", nil
+}
+
+// runGet prints a single node's content, like zkCli's get, and exits.
+func runGet(args []string) {
+	snapshotPath, path, err := parseGetArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\nusage: %s get <snapshot-file> <znode>\n", err, os.Args[0])
+		os.Exit(2)
+	}
+	snapshotPath, err = resolveSnapshotPath(snapshotPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := writeNodeContent(os.Stdout, snapshotPath, path, isTerminal(os.Stdout)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// lsOptions are the arguments of the ls command.
+type lsOptions struct {
+	snapshotPath string
+	path         string
+	long         bool
+}
+
+// parseLsArgs parses the arguments of the ls command: the snapshot file, the
+// path of the node to list and -l, which may come anywhere.
+func parseLsArgs(args []string) (lsOptions, error) {
+	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	long := fs.Bool("l", false, "print each child's data size and child count")
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return lsOptions{}, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) != 2 {
+		return lsOptions{}, errors.New("expected a snapshot file and a node path")
+	}
+	if !strings.HasPrefix(positional[1], "/") {
+		return lsOptions{}, fmt.Errorf("node path %q must start with /", positional[1])
+	}
+	return lsOptions{snapshotPath: positional[0], path: positional[1], long: *long}, nil
+}
+
+// childLines lists the names of the children of the node at path, sorted,
+// or with long set, tab-separated lines of name, data size and child count.
+func childLines(tree *snapshot.Tree, path string, long bool) ([]string, error) {
+	node, ok := tree.NodesByPath[path]
+	if !ok {
+		return nil, fmt.Errorf("no node at %s", path)
+	}
+	children := append([]*snapshot.Node(nil), node.Children...)
+	sort.Slice(children, func(i, j int) bool { return children[i].ID < children[j].ID })
+	lines := make([]string, len(children))
+	for i, child := range children {
+		lines[i] = child.ID
+		if long {
+			lines[i] = fmt.Sprintf("%s\t%d\t%d", child.ID, child.DataLen(), len(child.Children))
+		}
+	}
+	return lines, nil
+}
+
+// runLs prints the children of a node, like zkCli's ls, and exits.
+func runLs(args []string) {
+	opts, err := parseLsArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\nusage: %s ls [-l] <snapshot-file> <znode>\n", err, os.Args[0])
+		os.Exit(2)
+	}
+	snapshotPath, err := resolveSnapshotPath(opts.snapshotPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	tree, err := parseForExport(snapshotPath, 0)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	lines, err := childLines(tree, opts.path, opts.long)
+	tree.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+// diffLines lists changes as "+ path" for added, "- path" for removed and
+// "~ path (what changed)" for modified nodes.
+func diffLines(changes []snapshot.NodeChange) []string {
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		switch c.Kind {
+		case snapshot.ChangeAdded:
+			lines[i] = "+ " + c.Path
+		case snapshot.ChangeRemoved:
+			lines[i] = "- " + c.Path
+		default:
+			var what []string
+			if c.DataChanged() {
+				what = append(what, fmt.Sprintf("data %d -> %d bytes", c.Old.DataLen(), c.New.DataLen()))
+			}
+			if c.VersionChanged() {
+				what = append(what, fmt.Sprintf("version %d -> %d", c.Old.Stat.Version, c.New.Stat.Version))
+			}
+			lines[i] = "~ " + c.Path + " (" + strings.Join(what, ", ") + ")"
+		}
+	}
+	return lines
+}
+
+// runDiff prints how the nodes of a newer snapshot differ from an older one
+// and exits.
+func runDiff(args []string) {
+	if len(args) != 2 {
+		fmt.Fprintf(os.Stderr, "expected an old and a new snapshot file\nusage: %s diff <old-snapshot> <new-snapshot>\n", os.Args[0])
+		os.Exit(2)
+	}
+	var trees [2]*snapshot.Tree
+	for i, arg := range args {
+		snapshotPath, err := resolveSnapshotPath(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		trees[i], err = parseForExport(snapshotPath, 0)
+		if err != nil {
+			trees[0].Close()
+			fmt.Fprintf(os.Stderr, "%s: %v\n", arg, err)
+			os.Exit(1)
+		}
+	}
+	for _, line := range diffLines(snapshot.Diff(trees[0], trees[1])) {
+		fmt.Println(line)
+	}
+	trees[0].Close()
+	trees[1].Close()
+}
+
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Fprintf(os.Stderr, "usage: %s <snapshot-file>\n", os.Args[0])
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "get":
+			runGet(os.Args[2:])
+			return
+		case "ls":
+			runLs(os.Args[2:])
+			return
+		case "diff":
+			runDiff(os.Args[2:])
+			return
+		}
+	}
+	opts, err := parseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\nusage: %s <snapshot-file> [-path <znode>] [-strict-acls] [-verify-checksum] [-policy <file.json>] [-baseline <file>] [-save-baseline <file>] [-json] [-dump] [-max-data-len <bytes>]\n", err, os.Args[0])
+		os.Exit(2)
+	}
+	if abs, err := filepath.Abs(opts.snapshotPath); err == nil {
+		opts.snapshotPath = abs
+	}
+	sourcePath := opts.snapshotPath
+	opts.snapshotPath, err = resolveSnapshotPath(opts.snapshotPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(2)
 	}
 
-	p := tea.NewProgram(newAppModel(os.Args[1]), tea.WithAltScreen())
+	if opts.exportJSON {
+		if err := writeJSON(os.Stdout, opts.snapshotPath, int32(opts.maxDataLen)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if opts.dump {
+		if err := writeDump(os.Stdout, opts.snapshotPath, int32(opts.maxDataLen)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if opts.saveBaseline != "" {
+		if err := writeBaseline(opts.snapshotPath, opts.saveBaseline, int32(opts.maxDataLen)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	app := newAppModel(opts.snapshotPath)
+	app.sourcePath = sourcePath
+	app.startPath = opts.startPath
+	app.strictACLs = opts.strictACLs
+	app.verifySum = opts.verifySum
+	app.maxDataLen = int32(opts.maxDataLen)
+	if opts.policyPath != "" {
+		app.policy, err = policy.Load(opts.policyPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if opts.baselinePath != "" {
+		app.baseline, err = snapshot.LoadBaseline(opts.baselinePath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	stateFile, stateErr := tui.DefaultStateFile()
+	if stateErr == nil {
+		// An unreadable state file only costs the restored view.
+		app.viewState, _ = tui.LoadViewState(stateFile, opts.snapshotPath)
+	}
+	restoreTitle := saveTerminalTitle(os.Stdout)
+	p := tea.NewProgram(app, tea.WithAltScreen())
 	finalModel, err := p.Run()
+	restoreTitle()
+	if app, ok := finalModel.(appModel); ok {
+		app.stopLoading()
+		app.tree.Close()
+		if stateErr == nil {
+			if err := app.saveViewState(stateFile); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to start tui: %v\n", err)
 		os.Exit(1)
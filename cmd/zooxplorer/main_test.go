@@ -0,0 +1,320 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+	"github.com/jowiho/zooxplorer/internal/tui"
+)
+
+func TestWindowTitleCmdNamesSnapshotFile(t *testing.T) {
+	cmd := windowTitleCmd("/var/lib/zookeeper/version-2/snapshot.1a2b")
+	want := tea.SetWindowTitle("zooxplorer — snapshot.1a2b")()
+	if got := cmd(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected title message: %#v", got)
+	}
+}
+
+func TestLoadDoneSetsWindowTitle(t *testing.T) {
+	m := newAppModel("snapshot.1")
+	root := &snapshot.Node{ID: "/", Path: ""}
+	_, cmd := m.Update(loadDoneMsg{tree: &snapshot.Tree{Root: root}})
+	if cmd == nil {
+		t.Fatal("expected a title command after load")
+	}
+	if got, want := cmd(), tea.SetWindowTitle("zooxplorer — snapshot.1")(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected title message: %#v", got)
+	}
+}
+
+func TestLoadDoneOpensTruncatedSnapshot(t *testing.T) {
+	m := newAppModel("snapshot.1")
+	root := &snapshot.Node{ID: "/", Path: ""}
+	err := fmt.Errorf("%w: node record at offset 99 is incomplete", snapshot.ErrTruncated)
+	updated, _ := m.Update(loadDoneMsg{tree: &snapshot.Tree{Root: root}, err: err})
+	app := updated.(appModel)
+	if app.loadErr != nil || app.ui == nil {
+		t.Fatalf("expected the partial tree to open, got error %v", app.loadErr)
+	}
+	updated, _ = app.Update(tea.WindowSizeMsg{Width: 200, Height: 20})
+	if view := updated.(appModel).View(); !strings.Contains(view, "Snapshot truncated") {
+		t.Fatalf("expected a truncation warning, got:\n%s", view)
+	}
+}
+
+func TestViewStateRestoredAndSaved(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	a := &snapshot.Node{ID: "a", Path: "/a", Parent: root}
+	a1 := &snapshot.Node{ID: "a1", Path: "/a/a1", Parent: a}
+	root.Children = []*snapshot.Node{a}
+	a.Children = []*snapshot.Node{a1}
+	tree := &snapshot.Tree{Root: root, NodesByPath: map[string]*snapshot.Node{"/a": a, "/a/a1": a1}}
+
+	file := filepath.Join(t.TempDir(), "state.json")
+	m := newAppModel("snapshot.1")
+	if err := m.saveViewState(file); err != nil {
+		t.Fatalf("expected nothing to save before the snapshot opened, got %v", err)
+	}
+	m.viewState = &tui.ViewState{Expanded: []string{"/a"}, Selected: "/a/a1"}
+	updated, _ := m.Update(loadDoneMsg{tree: tree})
+	if err := updated.(appModel).saveViewState(file); err != nil {
+		t.Fatal(err)
+	}
+	state, err := tui.LoadViewState(file, "snapshot.1")
+	if err != nil || state == nil || state.Selected != "/a/a1" {
+		t.Fatalf("expected the restored selection to be saved, got %+v, %v", state, err)
+	}
+}
+
+func TestReloadKeepsSelectionAndExpansion(t *testing.T) {
+	tree := func(extra ...string) *snapshot.Tree {
+		root := &snapshot.Node{ID: "/", Path: ""}
+		a := &snapshot.Node{ID: "a", Path: "/a", Parent: root}
+		a1 := &snapshot.Node{ID: "a1", Path: "/a/a1", Parent: a}
+		root.Children = []*snapshot.Node{a}
+		a.Children = []*snapshot.Node{a1}
+		nodes := map[string]*snapshot.Node{"": root, "/": root, "/a": a, "/a/a1": a1}
+		for _, name := range extra {
+			n := &snapshot.Node{ID: name, Path: "/" + name, Parent: root}
+			root.Children = append(root.Children, n)
+			nodes[n.Path] = n
+		}
+		return &snapshot.Tree{Root: root, NodesByPath: nodes}
+	}
+	m := newAppModel("snapshot.1")
+	m.viewState = &tui.ViewState{Expanded: []string{"/a"}, Selected: "/a/a1"}
+	updated, _ := m.Update(loadDoneMsg{tree: tree()})
+	updated, _ = updated.Update(tea.WindowSizeMsg{Width: 200, Height: 20})
+
+	updated, cmd := updated.Update(tui.ReloadMsg{})
+	if cmd == nil {
+		t.Fatal("expected a reload to start parsing")
+	}
+	updated, _ = updated.Update(loadDoneMsg{tree: tree("b")})
+	app := updated.(appModel)
+	state := app.ui.(tui.Model).ViewState()
+	if state.Selected != "/a/a1" || !reflect.DeepEqual(state.Expanded, []string{"/a"}) {
+		t.Fatalf("expected the view to survive the reload, got %+v", state)
+	}
+	if app.tree.NodesByPath["/b"] == nil {
+		t.Fatal("expected the reloaded tree")
+	}
+	if view := app.View(); !strings.Contains(view, "b") || strings.Count(view, "\n") != 19 {
+		t.Fatalf("expected the reloaded tree at the terminal size, got:\n%s", view)
+	}
+
+	updated, _ = app.Update(loadDoneMsg{err: errors.New("bad magic")})
+	app = updated.(appModel)
+	if app.loadErr != nil || app.tree.NodesByPath["/b"] == nil {
+		t.Fatalf("expected a failed reload to keep the tree, got %v", app.loadErr)
+	}
+	if view := app.View(); !strings.Contains(view, "reload failed: bad magic") {
+		t.Fatalf("expected the failure in the status bar, got:\n%s", view)
+	}
+}
+
+func TestQuitWhileLoadingCancelsParse(t *testing.T) {
+	m := newAppModel("snapshot.1")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if cmd == nil {
+		t.Fatal("expected ctrl+c to quit while loading")
+	}
+	if m.loadCtx.Err() == nil {
+		t.Fatal("expected quitting to cancel the load")
+	}
+}
+
+func TestStopLoadingWaitsForReloads(t *testing.T) {
+	m := newAppModel(filepath.Join(t.TempDir(), "missing"))
+	reload := startLoadCmd(m.loadCtx, m.parses, m.snapshotPath, m.loadOptions(), m.events)
+	reload()
+	m.stopLoading()
+	if m.loadCtx.Err() == nil || len(m.events) != 0 {
+		t.Fatalf("expected the reload cancelled and its result drained, %d events left", len(m.events))
+	}
+
+	reload()
+	if len(m.events) != 0 {
+		t.Fatal("expected no parse to start after stopping")
+	}
+}
+
+func TestParseArgsAcceptsPathAfterSnapshotFile(t *testing.T) {
+	for _, args := range [][]string{
+		{"snapshot.1", "-path", "/a/b"},
+		{"-path", "/a/b", "snapshot.1"},
+	} {
+		opts, err := parseArgs(args)
+		if err != nil {
+			t.Fatalf("parseArgs(%q) error = %v", args, err)
+		}
+		if opts.snapshotPath != "snapshot.1" || opts.startPath != "/a/b" {
+			t.Fatalf("parseArgs(%q) = %+v", args, opts)
+		}
+	}
+}
+
+func TestParseArgsRequiresOneSnapshotFile(t *testing.T) {
+	for _, args := range [][]string{nil, {"a", "b"}, {"-path", "/a"}} {
+		if _, err := parseArgs(args); err == nil {
+			t.Fatalf("expected error for %q", args)
+		}
+	}
+}
+
+func TestParseArgsAuditOptions(t *testing.T) {
+	opts, err := parseArgs([]string{"snapshot.1", "-strict-acls", "-verify-checksum", "-policy", "acl.json"})
+	if err != nil || !opts.strictACLs || !opts.verifySum || opts.policyPath != "acl.json" {
+		t.Fatalf("expected strict ACL mode, got %+v (%v)", opts, err)
+	}
+}
+
+func TestParseArgsMaxDataLen(t *testing.T) {
+	opts, err := parseArgs([]string{"snapshot.1", "-max-data-len", "536870912"})
+	if err != nil || opts.maxDataLen != 512*1024*1024 {
+		t.Fatalf("expected a 512MB data limit, got %+v (%v)", opts, err)
+	}
+	if _, err := parseArgs([]string{"snapshot.1", "-max-data-len", "4294967296"}); err == nil {
+		t.Fatal("expected a limit above 2GB to be rejected")
+	}
+}
+
+func TestParseArgsJSONExport(t *testing.T) {
+	opts, err := parseArgs([]string{"snapshot.1", "-json"})
+	if err != nil || !opts.exportJSON {
+		t.Fatalf("expected JSON export, got %+v (%v)", opts, err)
+	}
+}
+
+func TestParseArgsDump(t *testing.T) {
+	opts, err := parseArgs([]string{"--dump", "snapshot.1"})
+	if err != nil || !opts.dump || opts.snapshotPath != "snapshot.1" {
+		t.Fatalf("expected a dump of snapshot.1, got %+v (%v)", opts, err)
+	}
+}
+
+func TestParseGetArgs(t *testing.T) {
+	snapshotPath, path, err := parseGetArgs([]string{"snapshot.1", "/a/b"})
+	if err != nil || snapshotPath != "snapshot.1" || path != "/a/b" {
+		t.Fatalf("unexpected get arguments %q %q (%v)", snapshotPath, path, err)
+	}
+	for _, args := range [][]string{{"snapshot.1"}, {"snapshot.1", "a"}, {"snapshot.1", "/a", "/b"}} {
+		if _, _, err := parseGetArgs(args); err == nil {
+			t.Fatalf("expected %q to be rejected", args)
+		}
+	}
+}
+
+func TestNodeContentDecodesWithoutColors(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	a := &snapshot.Node{ID: "a", Path: "/a", Parent: root, Data: []byte(`{"k":1}`)}
+	b := &snapshot.Node{ID: "b", Path: "/b", Parent: root}
+	root.Children = []*snapshot.Node{a, b}
+	tree := &snapshot.Tree{Root: root, NodesByPath: map[string]*snapshot.Node{"": root, "/": root, "/a": a, "/b": b}}
+
+	if got, err := nodeContent(tree, "/a", false); err != nil || got != "{\n  \"k\": 1\n}\n" {
+		t.Fatalf("expected pretty-printed JSON, got %q (%v)", got, err)
+	}
+	if got, err := nodeContent(tree, "/a", true); err != nil || !strings.Contains(got, "\x1b[") {
+		t.Fatalf("expected highlighted JSON on a terminal, got %q (%v)", got, err)
+	}
+	if got, err := nodeContent(tree, "/b", false); err != nil || got != "" {
+		t.Fatalf("expected no content for an empty node, got %q (%v)", got, err)
+	}
+	if _, err := nodeContent(tree, "/missing", false); err == nil || err.Error() != "no node at /missing" {
+		t.Fatalf("expected a missing node to fail, got %v", err)
+	}
+}
+
+func TestParseLsArgs(t *testing.T) {
+	opts, err := parseLsArgs([]string{"snapshot.1", "/a", "-l"})
+	if err != nil || opts != (lsOptions{snapshotPath: "snapshot.1", path: "/a", long: true}) {
+		t.Fatalf("unexpected ls arguments %+v (%v)", opts, err)
+	}
+	if _, err := parseLsArgs([]string{"snapshot.1"}); err == nil {
+		t.Fatal("expected a missing node path to be rejected")
+	}
+}
+
+func TestChildLinesListSortedChildren(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	b := &snapshot.Node{ID: "b", Path: "/b", Parent: root}
+	a := &snapshot.Node{ID: "a", Path: "/a", Parent: root, Data: []byte("hello")}
+	a1 := &snapshot.Node{ID: "a1", Path: "/a/a1", Parent: a}
+	root.Children = []*snapshot.Node{b, a}
+	a.Children = []*snapshot.Node{a1}
+	tree := &snapshot.Tree{Root: root, NodesByPath: map[string]*snapshot.Node{"": root, "/": root, "/a": a, "/a/a1": a1, "/b": b}}
+
+	if lines, err := childLines(tree, "/", false); err != nil || !reflect.DeepEqual(lines, []string{"a", "b"}) {
+		t.Fatalf("expected the root's children by name, got %q (%v)", lines, err)
+	}
+	if lines, err := childLines(tree, "/", true); err != nil || !reflect.DeepEqual(lines, []string{"a\t5\t1", "b\t0\t0"}) {
+		t.Fatalf("expected sizes and child counts, got %q (%v)", lines, err)
+	}
+	if lines, err := childLines(tree, "/a/a1", false); err != nil || len(lines) != 0 {
+		t.Fatalf("expected a leaf to have no children, got %q (%v)", lines, err)
+	}
+	if _, err := childLines(tree, "/missing", false); err == nil || err.Error() != "no node at /missing" {
+		t.Fatalf("expected a missing node to fail, got %v", err)
+	}
+}
+
+func TestDiffLinesMarkChanges(t *testing.T) {
+	old := &snapshot.Node{Path: "/m", Data: []byte("one"), Stat: snapshot.StatPersisted{Version: 1}}
+	changed := &snapshot.Node{Path: "/m", Data: []byte("three"), Stat: snapshot.StatPersisted{Version: 2}}
+	bumped := &snapshot.Node{Path: "/v", Stat: snapshot.StatPersisted{Version: 4}}
+	changes := []snapshot.NodeChange{
+		{Path: "/a", Kind: snapshot.ChangeAdded, New: &snapshot.Node{Path: "/a"}},
+		{Path: "/m", Kind: snapshot.ChangeModified, Old: old, New: changed},
+		{Path: "/r", Kind: snapshot.ChangeRemoved, Old: &snapshot.Node{Path: "/r"}},
+		{Path: "/v", Kind: snapshot.ChangeModified, Old: &snapshot.Node{Path: "/v", Stat: snapshot.StatPersisted{Version: 3}}, New: bumped},
+	}
+	want := []string{
+		"+ /a",
+		"~ /m (data 3 -> 5 bytes, version 1 -> 2)",
+		"- /r",
+		"~ /v (version 3 -> 4)",
+	}
+	if got := diffLines(changes); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestResolveSnapshotPathPicksLatestInDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"snapshot.9", "snapshot.a"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if got, err := resolveSnapshotPath(dir); err != nil || got != filepath.Join(dir, "snapshot.a") {
+		t.Fatalf("expected snapshot.a, got %q (%v)", got, err)
+	}
+	file := filepath.Join(dir, "snapshot.9")
+	if got, err := resolveSnapshotPath(file); err != nil || got != file {
+		t.Fatalf("expected files to be used as-is, got %q (%v)", got, err)
+	}
+}
+
+func TestParseArgsBaselineOptions(t *testing.T) {
+	opts, err := parseArgs([]string{"-baseline", "old.json", "snapshot.1", "-save-baseline", "new.json"})
+	if err != nil || opts.baselinePath != "old.json" || opts.saveBaseline != "new.json" {
+		t.Fatalf("expected baseline files, got %+v (%v)", opts, err)
+	}
+}
+
+func TestLoadingViewWithUnknownTotal(t *testing.T) {
+	m := newAppModel("snapshot.1")
+	updated, _ := m.Update(loadProgressMsg{read: 2048, total: 0})
+	view := updated.(appModel).View()
+	if !strings.Contains(view, "Loading snapshot 2.0 KB") || !strings.Contains(view, "0%") {
+		t.Fatalf("expected bytes read without a total, got:\n%s", view)
+	}
+}
@@ -0,0 +1,124 @@
+package format
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// decompress decompresses data compressed with gzip, Snappy or zstd, which
+// are recognized by their magic bytes, and names the algorithm used.
+func decompress(data []byte) (out []byte, algo string, ok bool) {
+	if out, ok := tryGunzip(data); ok {
+		return out, "gzip", true
+	}
+	if out, ok := trySnappy(data); ok {
+		return out, "snappy", true
+	}
+	if out, ok := tryZstd(data); ok {
+		return out, "zstd", true
+	}
+	return nil, "", false
+}
+
+// Compression names the algorithm data is compressed with and the length of
+// the decompressed data; ok is false for data that is not compressed.
+func Compression(data []byte) (algo string, uncompressedLen int, ok bool) {
+	out, algo, ok := decompress(data)
+	if !ok {
+		return "", 0, false
+	}
+	return algo, len(out), true
+}
+
+// compressionEncodings maps the algorithms decompress names to the encoding
+// that renders their output.
+var compressionEncodings = map[string]Encoding{
+	"gzip":   EncodingGzip,
+	"snappy": EncodingSnappy,
+	"zstd":   EncodingZstd,
+}
+
+func tryGunzip(data []byte) ([]byte, bool) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return nil, false
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+	defer r.Close()
+
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+// zstdMagic starts every zstd frame.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// zstdDecoder is shared; DecodeAll is safe for concurrent use.
+var zstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+	d, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	return d
+})
+
+func tryZstd(data []byte) ([]byte, bool) {
+	if !bytes.HasPrefix(data, zstdMagic) {
+		return nil, false
+	}
+	decoded, err := zstdDecoder().DecodeAll(data, nil)
+	if err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+var (
+	// snappyStreamMagic starts the official Snappy framing format.
+	snappyStreamMagic = []byte("\xff\x06\x00\x00sNaPpY")
+	// xerialMagic starts the block framing used by Kafka's Java clients: the
+	// magic, two int32 versions, then blocks of an int32 length and a raw
+	// Snappy block.
+	xerialMagic = []byte("\x82SNAPPY\x00")
+)
+
+// trySnappy decompresses data written in either Snappy framing.
+func trySnappy(data []byte) ([]byte, bool) {
+	switch {
+	case bytes.HasPrefix(data, snappyStreamMagic):
+		decoded, err := io.ReadAll(snappy.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			return nil, false
+		}
+		return decoded, true
+	case bytes.HasPrefix(data, xerialMagic) && len(data) >= len(xerialMagic)+8:
+		var decoded []byte
+		rest := data[len(xerialMagic)+8:]
+		for len(rest) > 0 {
+			if len(rest) < 4 {
+				return nil, false
+			}
+			size := binary.BigEndian.Uint32(rest)
+			rest = rest[4:]
+			if uint64(size) > uint64(len(rest)) {
+				return nil, false
+			}
+			block, err := snappy.Decode(nil, rest[:size])
+			if err != nil {
+				return nil, false
+			}
+			decoded = append(decoded, block...)
+			rest = rest[size:]
+		}
+		return decoded, true
+	}
+	return nil, false
+}
@@ -0,0 +1,91 @@
+package format
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestZNodeContentSnappyStream(t *testing.T) {
+	var b bytes.Buffer
+	w := snappy.NewBufferedWriter(&b)
+	if _, err := w.Write([]byte(`{"a":1}`)); err != nil {
+		t.Fatalf("snappy write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("snappy close: %v", err)
+	}
+	if got := DetectEncoding(b.Bytes()); got != EncodingSnappy {
+		t.Fatalf("expected snappy, got %v", got)
+	}
+	if got := stripANSI(ZNodeContent(b.Bytes())); got != "{\n  \"a\": 1\n}" {
+		t.Fatalf("unexpected content:\n%s", got)
+	}
+}
+
+func TestZNodeContentXerialSnappy(t *testing.T) {
+	data := append([]byte{}, xerialMagic...)
+	data = binary.BigEndian.AppendUint32(data, 1)
+	data = binary.BigEndian.AppendUint32(data, 1)
+	for _, chunk := range []string{"hello ", "snappy"} {
+		block := snappy.Encode(nil, []byte(chunk))
+		data = binary.BigEndian.AppendUint32(data, uint32(len(block)))
+		data = append(data, block...)
+	}
+	if got := ZNodeContent(data); got != "hello snappy" {
+		t.Fatalf("unexpected content: %q", got)
+	}
+	want := "Size: " + strconv.Itoa(len(data)) + " bytes (compressed), 12 bytes (uncompressed)"
+	if got := DataSizeSummary(data); got != want {
+		t.Fatalf("unexpected size summary: %q", got)
+	}
+
+	if _, ok := trySnappy(data[:len(data)-1]); ok {
+		t.Fatal("expected a cut-off block to be rejected")
+	}
+}
+
+func TestZNodeContentZstdJSON(t *testing.T) {
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("zstd writer: %v", err)
+	}
+	data := enc.EncodeAll([]byte(`{"a":1}`), nil)
+	enc.Close()
+
+	if got := DetectEncoding(data); got != EncodingZstd {
+		t.Fatalf("expected zstd, got %v", got)
+	}
+	if got := stripANSI(ZNodeContent(data)); got != "{\n  \"a\": 1\n}" {
+		t.Fatalf("unexpected content:\n%s", got)
+	}
+	want := "Size: " + strconv.Itoa(len(data)) + " bytes (compressed), 7 bytes (uncompressed)"
+	if got := DataSizeSummary(data); got != want {
+		t.Fatalf("unexpected size summary: %q", got)
+	}
+	if got := RenderAs(data, EncodingGzip); got != hexDump(data) {
+		t.Fatalf("expected gzip rendering of zstd data to fall back to hex, got:\n%s", got)
+	}
+}
+
+func TestDecompressNamesAlgorithm(t *testing.T) {
+	if _, algo, ok := decompress(gzipBytes(t, []byte("x"))); !ok || algo != "gzip" {
+		t.Fatalf("expected gzip, got %q (%v)", algo, ok)
+	}
+	if _, _, ok := decompress(append([]byte{}, zstdMagic...)); ok {
+		t.Fatal("expected a bare zstd magic to be rejected")
+	}
+}
+
+func TestCompressionReportsUncompressedLength(t *testing.T) {
+	if algo, n, ok := Compression(gzipBytes(t, []byte("hello gzip"))); !ok || algo != "gzip" || n != 10 {
+		t.Fatalf("expected gzip of 10 bytes, got %q, %d (%v)", algo, n, ok)
+	}
+	if _, _, ok := Compression([]byte("plain")); ok {
+		t.Fatal("expected plain data not to be compressed")
+	}
+}
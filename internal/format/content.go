@@ -2,65 +2,308 @@ package format
 
 import (
 	"bytes"
-	"compress/gzip"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"strconv"
 	"strings"
 	"unicode/utf8"
 )
 
+// Encoding is a way of interpreting node data for display.
+type Encoding int
+
+const (
+	EncodingText Encoding = iota
+	EncodingHex
+	EncodingBase64
+	EncodingGzip
+	EncodingJSON
+	EncodingProtobuf
+	EncodingXML
+	EncodingSnappy
+	EncodingZstd
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingText:
+		return "Text"
+	case EncodingHex:
+		return "Hex"
+	case EncodingBase64:
+		return "Base64-decoded"
+	case EncodingGzip:
+		return "Gzip"
+	case EncodingJSON:
+		return "JSON"
+	case EncodingProtobuf:
+		return "Protobuf"
+	case EncodingXML:
+		return "XML"
+	case EncodingSnappy:
+		return "Snappy"
+	case EncodingZstd:
+		return "Zstd"
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
 func ZNodeContent(data []byte) string {
+	return ZNodeContentTheme(data, DefaultTheme)
+}
+
+// ZNodeContentTheme is ZNodeContent highlighting with theme.
+func ZNodeContentTheme(data []byte, theme Theme) string {
+	return RenderAsTheme(data, DetectEncoding(data), theme)
+}
+
+// Describe returns everything the content and metadata panes show about
+// data: the content as ZNodeContent renders it, the size summary of
+// DataSizeSummary and the type of ClassifyData. Compressed data is
+// decompressed only once for all three.
+func Describe(data []byte) (content, sizeSummary, dataType string) {
+	return DescribeTheme(data, DefaultTheme)
+}
+
+// DescribeTheme is Describe highlighting the content with theme.
+func DescribeTheme(data []byte, theme Theme) (content, sizeSummary, dataType string) {
+	if decoded, algo, ok := decompress(data); ok {
+		return renderDecoded(decoded, theme), formatSizeSummary(len(data), len(decoded), true), algo + "+" + classifyDecoded(decoded)
+	}
+	return RenderAsTheme(data, DetectEncoding(data), theme), formatSizeSummary(len(data), 0, false), ClassifyData(data)
+}
+
+// DetectEncoding returns the encoding ZNodeContent uses for data.
+func DetectEncoding(data []byte) Encoding {
+	if _, algo, ok := decompress(data); ok {
+		return compressionEncodings[algo]
+	}
+	if isJSON(data) {
+		return EncodingJSON
+	}
+	if isXML(data) {
+		return EncodingXML
+	}
+	if _, ok := detectBase64(data); ok {
+		return EncodingBase64
+	}
+	if utf8.Valid(data) {
+		return EncodingText
+	}
+	if _, ok := tryProtobuf(data); ok {
+		return EncodingProtobuf
+	}
+	return EncodingHex
+}
+
+// ClassifyData labels what data is, following the decoding ZNodeContent
+// renders it with through compression and base64, e.g. "gzip+JSON",
+// "UTF-8 text", "binary" or "empty". Layers the renderer does not decode,
+// such as base64 inside compressed data, are not named either.
+func ClassifyData(data []byte) string {
 	if len(data) == 0 {
-		return "<empty>"
+		return "empty"
+	}
+	switch DetectEncoding(data) {
+	case EncodingGzip, EncodingSnappy, EncodingZstd:
+		decoded, algo, _ := decompress(data)
+		return algo + "+" + classifyDecoded(decoded)
+	case EncodingJSON:
+		return "JSON"
+	case EncodingXML:
+		return "XML"
+	case EncodingBase64:
+		decoded, _ := tryBase64(data)
+		return "base64+" + ClassifyData(decoded)
+	case EncodingText:
+		return "UTF-8 text"
+	case EncodingProtobuf:
+		return "protobuf"
+	}
+	return "binary"
+}
+
+// classifyDecoded labels decompressed data the way renderDecoded renders it.
+func classifyDecoded(data []byte) string {
+	switch {
+	case len(data) == 0:
+		return "empty"
+	case isJSON(data):
+		return "JSON"
+	case isXML(data):
+		return "XML"
+	case utf8.Valid(data):
+		return "UTF-8 text"
+	}
+	if _, ok := tryProtobuf(data); ok {
+		return "protobuf"
+	}
+	return "binary"
+}
+
+// Interpretations lists every encoding that can sensibly render data, in a
+// stable order. Hex is always viable.
+func Interpretations(data []byte) []Encoding {
+	if len(data) == 0 {
+		return []Encoding{EncodingText}
+	}
+	var out []Encoding
+	if utf8.Valid(data) {
+		out = append(out, EncodingText)
+	}
+	out = append(out, EncodingHex)
+	if _, ok := tryBase64(data); ok {
+		out = append(out, EncodingBase64)
+	}
+	if _, algo, ok := decompress(data); ok {
+		out = append(out, compressionEncodings[algo])
+	}
+	if isJSON(data) {
+		out = append(out, EncodingJSON)
 	}
+	if isXML(data) {
+		out = append(out, EncodingXML)
+	}
+	if _, ok := tryProtobuf(data); ok {
+		out = append(out, EncodingProtobuf)
+	}
+	return out
+}
+
+// RenderAs renders data using the given encoding, falling back to a hex dump
+// when the data cannot be decoded that way.
+func RenderAs(data []byte, enc Encoding) string {
+	return RenderAsTheme(data, enc, DefaultTheme)
+}
 
-	if decoded, ok := tryGunzip(data); ok {
-		data = decoded
+// RenderAsTheme is RenderAs highlighting with theme.
+func RenderAsTheme(data []byte, enc Encoding, theme Theme) string {
+	if len(data) == 0 {
+		return "<empty>"
+	}
+	switch enc {
+	case EncodingText:
+		return strings.TrimRight(string(data), "\n")
+	case EncodingBase64:
+		if decoded, ok := tryBase64(data); ok {
+			return base64Note + "\n" + ZNodeContentTheme(decoded, theme)
+		}
+	case EncodingGzip, EncodingSnappy, EncodingZstd:
+		if decoded, algo, ok := decompress(data); ok && compressionEncodings[algo] == enc {
+			return renderDecoded(decoded, theme)
+		}
+	case EncodingJSON:
+		return renderDecoded(data, theme)
+	case EncodingProtobuf:
+		if decoded, ok := tryProtobuf(data); ok {
+			return decoded
+		}
+	case EncodingXML:
+		if pretty, ok := indentXML(data, theme); ok {
+			return pretty
+		}
 	}
+	return hexDump(data)
+}
 
+// renderDecoded renders already decompressed data as JSON, XML, text,
+// protobuf or hex.
+func renderDecoded(data []byte, theme Theme) string {
 	trimmed := bytes.TrimSpace(data)
 	if len(trimmed) > 0 && json.Valid(trimmed) {
 		var out bytes.Buffer
 		if err := json.Indent(&out, trimmed, "", "  "); err == nil {
-			return highlightJSON(out.String())
+			return highlightJSON(out.String(), theme)
 		}
 	}
+	if pretty, ok := indentXML(data, theme); ok {
+		return pretty
+	}
 
 	if utf8.Valid(data) {
 		return strings.TrimRight(string(data), "\n")
 	}
 
+	if decoded, ok := tryProtobuf(data); ok {
+		return decoded
+	}
+	return hexDump(data)
+}
+
+func hexDump(data []byte) string {
 	return strings.TrimRight(hex.Dump(data), "\n")
 }
 
-func DataSizeSummary(data []byte) string {
-	compressed := len(data)
-	if decoded, ok := tryGunzip(data); ok {
-		return fmt.Sprintf("Size: %d bytes (compressed), %d bytes (uncompressed)", compressed, len(decoded))
-	}
-	return fmt.Sprintf("Size: %d bytes", compressed)
+func isJSON(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && json.Valid(trimmed)
 }
 
-func tryGunzip(data []byte) ([]byte, bool) {
-	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+func tryBase64(data []byte) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) < 4 || len(trimmed)%4 != 0 {
 		return nil, false
 	}
-	r, err := gzip.NewReader(bytes.NewReader(data))
-	if err != nil {
+	decoded, err := base64.StdEncoding.DecodeString(string(trimmed))
+	if err != nil || len(decoded) == 0 {
 		return nil, false
 	}
-	defer r.Close()
+	return decoded, true
+}
+
+// base64Note heads content rendered from base64-decoded data.
+const base64Note = "(decoded from base64)"
 
-	decoded, err := io.ReadAll(r)
-	if err != nil {
+// minDetectedBase64Len is the shortest payload detected as base64; shorter
+// ones are too often plain words.
+const minDetectedBase64Len = 8
+
+// detectBase64 decodes data when it is base64 of text or of a known binary
+// format, to tell base64 payloads from text that merely looks like base64.
+func detectBase64(data []byte) ([]byte, bool) {
+	if len(bytes.TrimSpace(data)) < minDetectedBase64Len {
+		return nil, false
+	}
+	decoded, ok := tryBase64(data)
+	if !ok || !(isPrintableText(decoded) || hasBinarySignature(decoded)) {
 		return nil, false
 	}
 	return decoded, true
 }
 
+// binarySignatures are the leading bytes of binary formats worth decoding
+// base64 for: gzip, zip and PNG.
+var binarySignatures = [][]byte{
+	{0x1f, 0x8b},
+	[]byte("PK\x03\x04"),
+	[]byte("\x89PNG"),
+}
+
+func hasBinarySignature(data []byte) bool {
+	for _, sig := range binarySignatures {
+		if bytes.HasPrefix(data, sig) {
+			return true
+		}
+	}
+	return false
+}
+
+func DataSizeSummary(data []byte) string {
+	decoded, _, ok := decompress(data)
+	return formatSizeSummary(len(data), len(decoded), ok)
+}
+
+// formatSizeSummary describes data of size bytes, which decompress to
+// uncompressed bytes if compressed is set.
+func formatSizeSummary(size, uncompressed int, compressed bool) string {
+	if compressed {
+		return fmt.Sprintf("Size: %d bytes (compressed), %d bytes (uncompressed)", size, uncompressed)
+	}
+	return fmt.Sprintf("Size: %d bytes", size)
+}
+
 const (
 	ansiReset   = "\x1b[0m"
 	ansiBlue    = "\x1b[34m"
@@ -69,7 +312,7 @@ const (
 	ansiMagenta = "\x1b[35m"
 )
 
-func highlightJSON(pretty string) string {
+func highlightJSON(pretty string, theme Theme) string {
 	var b strings.Builder
 	for i := 0; i < len(pretty); {
 		ch := pretty[i]
@@ -89,34 +332,30 @@ func highlightJSON(pretty string) string {
 			}
 			token := pretty[start:i]
 			if isObjectKey(pretty, i) {
-				b.WriteString(ansiBlue)
-				b.WriteString(token)
-				b.WriteString(ansiReset)
+				b.WriteString(paint(theme.Key, token))
 			} else {
-				b.WriteString(ansiGreen)
-				b.WriteString(token)
-				b.WriteString(ansiReset)
+				b.WriteString(paint(theme.String, token))
 			}
 			continue
 		}
 
 		if lit, ok := readLiteral(pretty, i, "true"); ok {
-			b.WriteString(ansiMagenta + lit + ansiReset)
+			b.WriteString(paint(theme.Literal, lit))
 			i += len(lit)
 			continue
 		}
 		if lit, ok := readLiteral(pretty, i, "false"); ok {
-			b.WriteString(ansiMagenta + lit + ansiReset)
+			b.WriteString(paint(theme.Literal, lit))
 			i += len(lit)
 			continue
 		}
 		if lit, ok := readLiteral(pretty, i, "null"); ok {
-			b.WriteString(ansiMagenta + lit + ansiReset)
+			b.WriteString(paint(theme.Literal, lit))
 			i += len(lit)
 			continue
 		}
 		if num, ok := readNumber(pretty, i); ok {
-			b.WriteString(ansiCyan + num + ansiReset)
+			b.WriteString(paint(theme.Number, num))
 			i += len(num)
 			continue
 		}
@@ -3,8 +3,10 @@ package format
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/base64"
 	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -46,6 +48,45 @@ func TestZNodeContentGunzipJSONPrettyPrint(t *testing.T) {
 	}
 }
 
+func TestInterpretationsOfBase64JSON(t *testing.T) {
+	data := []byte("eyJhIjoxfQ==") // {"a":1}
+	got := Interpretations(data)
+	want := []Encoding{EncodingText, EncodingHex, EncodingBase64}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected interpretations: %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected interpretations: %v", got)
+		}
+	}
+	if DetectEncoding(data) != EncodingBase64 {
+		t.Fatalf("expected base64 to be detected, got %v", DetectEncoding(data))
+	}
+	if out := stripANSI(RenderAs(data, EncodingBase64)); out != "(decoded from base64)\n{\n  \"a\": 1\n}" {
+		t.Fatalf("unexpected base64 rendering:\n%s", out)
+	}
+}
+
+func TestDetectBase64GuardsAgainstFalsePositives(t *testing.T) {
+	for _, text := range []string{"abcdefgh", "host", "localhost:2181"} {
+		if got := DetectEncoding([]byte(text)); got != EncodingText {
+			t.Fatalf("expected %q detected as text, got %v", text, got)
+		}
+	}
+	gz := base64.StdEncoding.EncodeToString(gzipBytes(t, []byte("zipped")))
+	if got := ZNodeContent([]byte(gz)); got != "(decoded from base64)\nzipped" {
+		t.Fatalf("expected base64 gzip to be decoded, got %q", got)
+	}
+}
+
+func TestRenderAsHexFallsBackForUndecodableData(t *testing.T) {
+	got := RenderAs([]byte("hi"), EncodingGzip)
+	if !strings.HasPrefix(got, "00000000  68 69") {
+		t.Fatalf("expected hex dump fallback, got %q", got)
+	}
+}
+
 func TestDataSizeSummaryPlain(t *testing.T) {
 	got := DataSizeSummary([]byte("hello"))
 	if got != "Size: 5 bytes" {
@@ -62,6 +103,37 @@ func TestDataSizeSummaryCompressed(t *testing.T) {
 	}
 }
 
+func TestClassifyData(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{nil, "empty"},
+		{[]byte(`{"a":1}`), "JSON"},
+		{gzipBytes(t, []byte(`{"a":1}`)), "gzip+JSON"},
+		{gzipBytes(t, []byte("hello gzip")), "gzip+UTF-8 text"},
+		{[]byte("<a><b/></a>"), "XML"},
+		{[]byte("aGVsbG8gd29ybGQ="), "base64+UTF-8 text"},
+		{[]byte("plain text"), "UTF-8 text"},
+		{[]byte{0xff, 0xfe, 0x00, 0x01}, "binary"},
+	}
+	for _, tc := range tests {
+		if got := ClassifyData(tc.data); got != tc.want {
+			t.Fatalf("ClassifyData(%q) = %q, want %q", tc.data, got, tc.want)
+		}
+	}
+}
+
+func TestClassifyDataStopsWhereTheContentStopsDecoding(t *testing.T) {
+	data := gzipBytes(t, []byte("aGVsbG8gd29ybGQ="))
+	if got := ZNodeContent(data); got != "aGVsbG8gd29ybGQ=" {
+		t.Fatalf("expected the content pane to show the base64 text, got %q", got)
+	}
+	if got := ClassifyData(data); got != "gzip+UTF-8 text" {
+		t.Fatalf("expected the type of what is shown, got %q", got)
+	}
+}
+
 func gzipBytes(t *testing.T, data []byte) []byte {
 	t.Helper()
 	var b bytes.Buffer
@@ -79,3 +151,26 @@ func stripANSI(s string) string {
 	re := regexp.MustCompile(`\x1b\[[0-9;]*m`)
 	return re.ReplaceAllString(s, "")
 }
+
+func TestDescribeMatchesSeparateFunctions(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,
+		[]byte("plain text"),
+		[]byte(`{"a":1}`),
+		gzipBytes(t, []byte(`{"a":1}`)),
+		gzipBytes(t, []byte("hello gzip")),
+		gzipBytes(t, []byte("aGVsbG8gd29ybGQ=")),
+		{0xff, 0x00, 0x10},
+	} {
+		content, size, class := Describe(data)
+		if want := ZNodeContent(data); content != want {
+			t.Fatalf("content of %q: expected %q, got %q", data, want, content)
+		}
+		if want := DataSizeSummary(data); size != want {
+			t.Fatalf("size of %q: expected %q, got %q", data, want, size)
+		}
+		if want := ClassifyData(data); class != want {
+			t.Fatalf("type of %q: expected %q, got %q", data, want, class)
+		}
+	}
+}
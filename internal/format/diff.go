@@ -0,0 +1,141 @@
+package format
+
+import "strings"
+
+// maxDiffCells bounds the size of the line table used to diff two texts.
+// Larger inputs are shown as a full replacement instead.
+const maxDiffCells = 4_000_000
+
+const ansiRed = "\x1b[31m"
+
+// DiffOp says whether a diff line is shared, only in the old text or only in
+// the new text.
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffRemoved
+	DiffAdded
+)
+
+// DiffLine is one line of a line-based diff.
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+func (l DiffLine) String() string {
+	switch l.Op {
+	case DiffRemoved:
+		return "-" + l.Text
+	case DiffAdded:
+		return "+" + l.Text
+	}
+	return " " + l.Text
+}
+
+// DiffLines returns a line-based diff turning a into b, built from their
+// longest common subsequence of lines.
+func DiffLines(a, b string) []DiffLine {
+	from := splitDiffLines(a)
+	to := splitDiffLines(b)
+
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix &&
+		from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+
+	out := make([]DiffLine, 0, len(from)+len(to))
+	for _, line := range from[:prefix] {
+		out = append(out, DiffLine{Op: DiffEqual, Text: line})
+	}
+	out = append(out, diffMiddle(from[prefix:len(from)-suffix], to[prefix:len(to)-suffix])...)
+	for _, line := range from[len(from)-suffix:] {
+		out = append(out, DiffLine{Op: DiffEqual, Text: line})
+	}
+	return out
+}
+
+func diffMiddle(from, to []string) []DiffLine {
+	out := make([]DiffLine, 0, len(from)+len(to))
+	if len(from)*len(to) > maxDiffCells {
+		for _, line := range from {
+			out = append(out, DiffLine{Op: DiffRemoved, Text: line})
+		}
+		for _, line := range to {
+			out = append(out, DiffLine{Op: DiffAdded, Text: line})
+		}
+		return out
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of from[i:]
+	// and to[j:].
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			out = append(out, DiffLine{Op: DiffEqual, Text: from[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, DiffLine{Op: DiffRemoved, Text: from[i]})
+			i++
+		default:
+			out = append(out, DiffLine{Op: DiffAdded, Text: to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		out = append(out, DiffLine{Op: DiffRemoved, Text: from[i]})
+	}
+	for ; j < len(to); j++ {
+		out = append(out, DiffLine{Op: DiffAdded, Text: to[j]})
+	}
+	return out
+}
+
+func splitDiffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// UnifiedDiff renders the diff of a and b with "---"/"+++" headers naming
+// both sides, coloring removed lines red and added lines green.
+func UnifiedDiff(fromLabel, toLabel, a, b string) string {
+	var sb strings.Builder
+	sb.WriteString(ansiRed + "--- " + fromLabel + ansiReset + "\n")
+	sb.WriteString(ansiGreen + "+++ " + toLabel + ansiReset)
+	for _, line := range DiffLines(a, b) {
+		sb.WriteString("\n")
+		switch line.Op {
+		case DiffRemoved:
+			sb.WriteString(ansiRed + line.String() + ansiReset)
+		case DiffAdded:
+			sb.WriteString(ansiGreen + line.String() + ansiReset)
+		default:
+			sb.WriteString(line.String())
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,55 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffLinesJSONContents(t *testing.T) {
+	a := "{\n  \"host\": \"a\",\n  \"port\": 1,\n  \"tls\": false\n}"
+	b := "{\n  \"host\": \"a\",\n  \"port\": 2,\n  \"tls\": false,\n  \"debug\": true\n}"
+
+	var got []string
+	for _, line := range DiffLines(a, b) {
+		got = append(got, line.String())
+	}
+	want := []string{
+		" {",
+		"   \"host\": \"a\",",
+		"-  \"port\": 1,",
+		"-  \"tls\": false",
+		"+  \"port\": 2,",
+		"+  \"tls\": false,",
+		"+  \"debug\": true",
+		" }",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected diff:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestDiffLinesKeepsCommonLinesBetweenChanges(t *testing.T) {
+	got := DiffLines("a\nb\nc", "b\nc\nd")
+	want := []DiffLine{
+		{Op: DiffRemoved, Text: "a"},
+		{Op: DiffEqual, Text: "b"},
+		{Op: DiffEqual, Text: "c"},
+		{Op: DiffAdded, Text: "d"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d lines, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestUnifiedDiffHeaders(t *testing.T) {
+	got := stripANSI(UnifiedDiff("/a", "/b", "x", "y"))
+	want := "--- /a\n+++ /b\n-x\n+y"
+	if got != want {
+		t.Fatalf("unexpected unified diff:\n%s", got)
+	}
+}
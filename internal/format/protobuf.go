@@ -0,0 +1,131 @@
+package format
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxProtobufDepth bounds how deep nested messages are decoded.
+const maxProtobufDepth = 32
+
+// protoField is one decoded field of a message decoded without its schema.
+type protoField struct {
+	number   uint64
+	wireType uint64
+	value    uint64 // varint, fixed32 and fixed64 fields
+	bytes    []byte // length-delimited fields
+	message  []protoField
+}
+
+// tryProtobuf decodes data as a protobuf message without a schema and renders
+// its fields as an indented tree. Messages written with a varint length
+// prefix, as by writeDelimitedTo, are decoded too. It fails unless the
+// decode consumes all of data.
+func tryProtobuf(data []byte) (string, bool) {
+	fields, ok := decodeProtobuf(data, 0)
+	if !ok {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || size == 0 || size != uint64(len(data)-n) {
+			return "", false
+		}
+		if fields, ok = decodeProtobuf(data[n:], 0); !ok {
+			return "", false
+		}
+	}
+	var b strings.Builder
+	writeProtoFields(&b, fields, "")
+	return strings.TrimRight(b.String(), "\n"), true
+}
+
+func decodeProtobuf(data []byte, depth int) ([]protoField, bool) {
+	if len(data) == 0 || depth > maxProtobufDepth {
+		return nil, false
+	}
+	var fields []protoField
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, false
+		}
+		data = data[n:]
+		f := protoField{number: tag >> 3, wireType: tag & 7}
+		if f.number == 0 || f.number > 1<<29-1 {
+			return nil, false
+		}
+		switch f.wireType {
+		case 0:
+			f.value, n = binary.Uvarint(data)
+			if n <= 0 {
+				return nil, false
+			}
+			data = data[n:]
+		case 1:
+			if len(data) < 8 {
+				return nil, false
+			}
+			f.value = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case 2:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || size > uint64(len(data)-n) {
+				return nil, false
+			}
+			f.bytes = data[n : n+int(size)]
+			data = data[n+int(size):]
+			if !isPrintableText(f.bytes) {
+				f.message, _ = decodeProtobuf(f.bytes, depth+1)
+			}
+		case 5:
+			if len(data) < 4 {
+				return nil, false
+			}
+			f.value = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		default:
+			// Groups (3, 4) are deprecated; anything else is invalid.
+			return nil, false
+		}
+		fields = append(fields, f)
+	}
+	return fields, true
+}
+
+func writeProtoFields(b *strings.Builder, fields []protoField, indent string) {
+	for _, f := range fields {
+		switch {
+		case f.message != nil:
+			fmt.Fprintf(b, "%s%d {\n", indent, f.number)
+			writeProtoFields(b, f.message, indent+"  ")
+			fmt.Fprintf(b, "%s}\n", indent)
+		case f.wireType == 2 && isPrintableText(f.bytes):
+			fmt.Fprintf(b, "%s%d: %s\n", indent, f.number, strconv.Quote(string(f.bytes)))
+		case f.wireType == 2:
+			fmt.Fprintf(b, "%s%d: 0x%x (%d bytes)\n", indent, f.number, f.bytes, len(f.bytes))
+		case f.wireType == 1:
+			fmt.Fprintf(b, "%s%d: 0x%016x (fixed64)\n", indent, f.number, f.value)
+		case f.wireType == 5:
+			fmt.Fprintf(b, "%s%d: 0x%08x (fixed32)\n", indent, f.number, f.value)
+		default:
+			fmt.Fprintf(b, "%s%d: %d\n", indent, f.number, f.value)
+		}
+	}
+}
+
+// isPrintableText reports whether data is UTF-8 text without control
+// characters other than whitespace, which is shown as a string rather than
+// decoded as a nested message.
+func isPrintableText(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
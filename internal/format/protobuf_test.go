@@ -0,0 +1,53 @@
+package format
+
+import "testing"
+
+// sampleProtobuf encodes {1: 150, 2: "hi", 3: {1: 1}, 4: fixed32 42}.
+var sampleProtobuf = []byte{
+	0x08, 0x96, 0x01,
+	0x12, 0x02, 'h', 'i',
+	0x1a, 0x02, 0x08, 0x01,
+	0x25, 0x2a, 0x00, 0x00, 0x00,
+}
+
+const sampleProtobufTree = "1: 150\n2: \"hi\"\n3 {\n  1: 1\n}\n4: 0x0000002a (fixed32)"
+
+func TestTryProtobufRendersFieldTree(t *testing.T) {
+	got, ok := tryProtobuf(sampleProtobuf)
+	if !ok || got != sampleProtobufTree {
+		t.Fatalf("unexpected decode (ok=%v):\n%s", ok, got)
+	}
+}
+
+func TestTryProtobufAcceptsLengthPrefix(t *testing.T) {
+	data := append([]byte{byte(len(sampleProtobuf))}, sampleProtobuf...)
+	got, ok := tryProtobuf(data)
+	if !ok || got != sampleProtobufTree {
+		t.Fatalf("unexpected decode (ok=%v):\n%s", ok, got)
+	}
+}
+
+func TestTryProtobufRejectsPartialDecode(t *testing.T) {
+	for _, data := range [][]byte{
+		append(append([]byte{}, sampleProtobuf...), 0x08), // tag without value
+		{0x12, 0x05, 'h', 'i'},                            // length past the end
+		{0x00, 0x01},                                      // field number 0
+		{0x0b, 0x01},                                      // group wire type
+	} {
+		if got, ok := tryProtobuf(data); ok {
+			t.Fatalf("expected % x to be rejected, got:\n%s", data, got)
+		}
+	}
+}
+
+func TestZNodeContentDetectsProtobuf(t *testing.T) {
+	if got := DetectEncoding(sampleProtobuf); got != EncodingProtobuf {
+		t.Fatalf("expected protobuf, got %v", got)
+	}
+	if got := ZNodeContent(sampleProtobuf); got != sampleProtobufTree {
+		t.Fatalf("unexpected content:\n%s", got)
+	}
+	if got := ZNodeContent([]byte{0xff, 0xfe}); got != hexDump([]byte{0xff, 0xfe}) {
+		t.Fatalf("expected a hex dump for non-protobuf binary, got:\n%s", got)
+	}
+}
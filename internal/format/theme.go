@@ -0,0 +1,40 @@
+package format
+
+// Theme holds the ANSI escape codes used to highlight each class of token in
+// rendered content. A token is written as its code, the token and ansiReset;
+// an empty code leaves the token unstyled.
+type Theme struct {
+	// Key colors JSON object keys and XML tag names.
+	Key string
+	// String colors JSON strings and XML attribute values.
+	String string
+	// Number colors JSON numbers and XML attribute names.
+	Number string
+	// Literal colors true, false and null, and XML comments and declarations.
+	Literal string
+}
+
+// DefaultTheme uses the basic ANSI colors, which suit dark terminals.
+var DefaultTheme = Theme{
+	Key:     ansiBlue,
+	String:  ansiGreen,
+	Number:  ansiCyan,
+	Literal: ansiMagenta,
+}
+
+// LightTheme uses darker 256-color shades that stay readable on light
+// terminals.
+var LightTheme = Theme{
+	Key:     "\x1b[38;5;25m",
+	String:  "\x1b[38;5;28m",
+	Number:  "\x1b[38;5;30m",
+	Literal: "\x1b[38;5;90m",
+}
+
+// paint wraps token in code, leaving it unstyled when code is empty.
+func paint(code, token string) string {
+	if code == "" {
+		return token
+	}
+	return code + token + ansiReset
+}
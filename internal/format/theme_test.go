@@ -0,0 +1,36 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestZNodeContentThemeColorsTokens(t *testing.T) {
+	in := []byte(`{"a":"x","n":1,"b":true}`)
+	if ZNodeContentTheme(in, DefaultTheme) != ZNodeContent(in) {
+		t.Fatal("expected ZNodeContent to use the default theme")
+	}
+
+	got := ZNodeContentTheme(in, LightTheme)
+	for _, want := range []string{
+		LightTheme.Key + `"a"` + ansiReset,
+		LightTheme.String + `"x"` + ansiReset,
+		LightTheme.Number + "1" + ansiReset,
+		LightTheme.Literal + "true" + ansiReset,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %q", want, got)
+		}
+	}
+}
+
+func TestZNodeContentThemeWithoutColors(t *testing.T) {
+	in := []byte(`<a b="c"><!-- d --></a>`)
+	got := ZNodeContentTheme(in, Theme{})
+	if strings.Contains(got, "\x1b[") {
+		t.Fatalf("expected no escape codes, got %q", got)
+	}
+	if got != stripANSI(ZNodeContent(in)) {
+		t.Fatalf("expected the same text as the default theme, got %q", got)
+	}
+}
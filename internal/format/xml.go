@@ -0,0 +1,131 @@
+package format
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// isXML reports whether data is a well-formed XML document or fragment with
+// at least one element.
+func isXML(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '<' {
+		return false
+	}
+	d := xml.NewDecoder(bytes.NewReader(trimmed))
+	elements := 0
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return elements > 0
+		}
+		if err != nil {
+			return false
+		}
+		if _, ok := tok.(xml.StartElement); ok {
+			elements++
+		}
+	}
+}
+
+// indentXML reindents XML with two spaces per level and highlights tag names,
+// attributes and comments. Elements holding only text stay on one line.
+func indentXML(data []byte, theme Theme) (string, bool) {
+	if !isXML(data) {
+		return "", false
+	}
+	d := xml.NewDecoder(bytes.NewReader(bytes.TrimSpace(data)))
+	var tokens []xml.Token
+	for {
+		// RawToken keeps namespace prefixes as written.
+		tok, err := d.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", false
+		}
+		if text, ok := tok.(xml.CharData); ok && len(bytes.TrimSpace(text)) == 0 {
+			continue
+		}
+		tokens = append(tokens, xml.CopyToken(tok))
+	}
+
+	var b strings.Builder
+	depth := 0
+	for i := 0; i < len(tokens); i++ {
+		if _, ok := tokens[i].(xml.EndElement); ok {
+			depth--
+		}
+		b.WriteString(strings.Repeat("  ", depth))
+		switch tok := tokens[i].(type) {
+		case xml.StartElement:
+			writeXMLStart(&b, tok, theme)
+			if closed := xmlTokenAt(tokens, i+1); isXMLEnd(closed) {
+				b.WriteString("/>")
+				i++
+			} else if text, ok := closed.(xml.CharData); ok && isXMLEnd(xmlTokenAt(tokens, i+2)) {
+				b.WriteString(">")
+				writeXMLText(&b, text)
+				writeXMLEnd(&b, tokens[i+2].(xml.EndElement), theme)
+				i += 2
+			} else {
+				b.WriteString(">")
+				depth++
+			}
+		case xml.EndElement:
+			writeXMLEnd(&b, tok, theme)
+		case xml.CharData:
+			writeXMLText(&b, tok)
+		case xml.Comment:
+			b.WriteString(paint(theme.Literal, "<!--"+string(tok)+"-->"))
+		case xml.ProcInst:
+			b.WriteString(paint(theme.Literal, "<?"+tok.Target+" "+string(tok.Inst)+"?>"))
+		case xml.Directive:
+			b.WriteString(paint(theme.Literal, "<!"+string(tok)+">"))
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimRight(b.String(), "\n"), true
+}
+
+func xmlTokenAt(tokens []xml.Token, i int) xml.Token {
+	if i < len(tokens) {
+		return tokens[i]
+	}
+	return nil
+}
+
+func isXMLEnd(tok xml.Token) bool {
+	_, ok := tok.(xml.EndElement)
+	return ok
+}
+
+func xmlName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+func writeXMLStart(b *strings.Builder, tok xml.StartElement, theme Theme) {
+	b.WriteString("<" + paint(theme.Key, xmlName(tok.Name)))
+	for _, attr := range tok.Attr {
+		var value bytes.Buffer
+		xml.EscapeText(&value, []byte(attr.Value))
+		b.WriteString(" " + paint(theme.Number, xmlName(attr.Name)) + "=" + paint(theme.String, `"`+value.String()+`"`))
+	}
+}
+
+func writeXMLEnd(b *strings.Builder, tok xml.EndElement, theme Theme) {
+	b.WriteString("</" + paint(theme.Key, xmlName(tok.Name)) + ">")
+}
+
+func writeXMLText(b *strings.Builder, text xml.CharData) {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, bytes.TrimSpace(text))
+	b.WriteString(escaped.String())
+}
@@ -0,0 +1,32 @@
+package format
+
+import "testing"
+
+func TestZNodeContentPrettyXML(t *testing.T) {
+	in := []byte(`<?xml version="1.0"?><config env="prod"><!-- db --><db:pool xmlns:db="urn:db" size="5"><host>a &amp; b</host><empty/></db:pool></config>`)
+	if got := DetectEncoding(in); got != EncodingXML {
+		t.Fatalf("expected XML, got %v", got)
+	}
+	want := `<?xml version="1.0"?>
+<config env="prod">
+  <!-- db -->
+  <db:pool xmlns:db="urn:db" size="5">
+    <host>a &amp; b</host>
+    <empty/>
+  </db:pool>
+</config>`
+	if got := stripANSI(ZNodeContent(in)); got != want {
+		t.Fatalf("unexpected pretty XML:\n%s", got)
+	}
+}
+
+func TestMalformedXMLFallsBackToText(t *testing.T) {
+	for _, in := range []string{"<a><b></a>", "<not xml", "< 3 apples"} {
+		if got := DetectEncoding([]byte(in)); got != EncodingText {
+			t.Fatalf("expected %q detected as text, got %v", in, got)
+		}
+		if got := ZNodeContent([]byte(in)); got != in {
+			t.Fatalf("expected %q unchanged, got %q", in, got)
+		}
+	}
+}
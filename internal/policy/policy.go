@@ -0,0 +1,200 @@
+// Package policy checks node ACLs against a JSON policy of required and
+// forbidden ACL entries.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// Policy is a list of rules, each applying to the nodes matching its path.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule requires or forbids ACL entries on nodes matching Path. Path is a
+// path.Match pattern; a trailing "/**" matches the prefix and everything
+// below it.
+type Rule struct {
+	Name    string       `json:"name"`
+	Path    string       `json:"path"`
+	Require []ACLPattern `json:"require"`
+	Forbid  []ACLPattern `json:"forbid"`
+}
+
+// ACLPattern matches ACL entries. Empty fields match anything; Perms matches
+// entries granting any of the listed permissions.
+type ACLPattern struct {
+	Scheme string   `json:"scheme"`
+	ID     string   `json:"id"`
+	Perms  []string `json:"perms"`
+}
+
+// Violation is a node breaking a rule.
+type Violation struct {
+	Node   *snapshot.Node
+	Rule   string
+	Reason string
+}
+
+var permBits = map[string]int32{
+	"read":   1,
+	"write":  2,
+	"create": 4,
+	"delete": 8,
+	"admin":  16,
+}
+
+// openACLUnsafe is what the ACL reference -1 stands for.
+var openACLUnsafe = []snapshot.ACL{{Perms: 31, Scheme: "world", ID: "anyone"}}
+
+// Load reads a policy from a JSON file.
+func Load(file string) (*Policy, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	return Parse(b)
+}
+
+// Parse decodes and validates a JSON policy.
+func Parse(b []byte) (*Policy, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var p Policy
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("parse policy: %w", err)
+	}
+	for i, rule := range p.Rules {
+		if rule.Path == "" {
+			return nil, fmt.Errorf("parse policy: rule %d has no path", i+1)
+		}
+		if _, err := path.Match(strings.TrimSuffix(rule.Path, "/**"), "/"); err != nil {
+			return nil, fmt.Errorf("parse policy: rule %d: bad path pattern %q", i+1, rule.Path)
+		}
+		for _, pattern := range append(append([]ACLPattern{}, rule.Require...), rule.Forbid...) {
+			for _, perm := range pattern.Perms {
+				if _, ok := permBits[perm]; !ok {
+					return nil, fmt.Errorf("parse policy: rule %d: unknown permission %q", i+1, perm)
+				}
+			}
+		}
+	}
+	return &p, nil
+}
+
+// Evaluate checks every node of the tree, root excluded, and returns the
+// violations in path order.
+func (p *Policy) Evaluate(tree *snapshot.Tree) []Violation {
+	if p == nil || tree == nil || tree.Root == nil {
+		return nil
+	}
+	var out []Violation
+	var walk func(n *snapshot.Node)
+	walk = func(n *snapshot.Node) {
+		for _, rule := range p.Rules {
+			if rule.matchesPath(n.Path) {
+				out = append(out, rule.check(n, nodeACLs(tree, n))...)
+			}
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	for _, child := range tree.Root.Children {
+		walk(child)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Node.Path < out[j].Node.Path })
+	return out
+}
+
+func nodeACLs(tree *snapshot.Tree, n *snapshot.Node) []snapshot.ACL {
+	if n.ACLRef == -1 {
+		return openACLUnsafe
+	}
+	return tree.ACLs[n.ACLRef]
+}
+
+func (r Rule) matchesPath(p string) bool {
+	if prefix, ok := strings.CutSuffix(r.Path, "/**"); ok {
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+		matched, _ := path.Match(prefix, p)
+		return matched
+	}
+	matched, _ := path.Match(r.Path, p)
+	return matched
+}
+
+func (r Rule) check(n *snapshot.Node, acls []snapshot.ACL) []Violation {
+	name := r.Name
+	if name == "" {
+		name = r.Path
+	}
+	var out []Violation
+	for _, pattern := range r.Forbid {
+		for _, acl := range acls {
+			if pattern.matches(acl) {
+				out = append(out, Violation{Node: n, Rule: name, Reason: "forbidden " + describeACL(acl)})
+			}
+		}
+	}
+	for _, pattern := range r.Require {
+		found := false
+		for _, acl := range acls {
+			if pattern.matches(acl) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, Violation{Node: n, Rule: name, Reason: "missing " + pattern.String()})
+		}
+	}
+	return out
+}
+
+func (p ACLPattern) matches(acl snapshot.ACL) bool {
+	if p.Scheme != "" && p.Scheme != acl.Scheme {
+		return false
+	}
+	if p.ID != "" && p.ID != acl.ID {
+		return false
+	}
+	if len(p.Perms) == 0 {
+		return true
+	}
+	for _, perm := range p.Perms {
+		if acl.Perms&permBits[perm] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (p ACLPattern) String() string {
+	scheme, id := p.Scheme, p.ID
+	if scheme == "" {
+		scheme = "*"
+	}
+	if id == "" {
+		id = "*"
+	}
+	s := scheme + ":" + id
+	if len(p.Perms) > 0 {
+		s += " (" + strings.Join(p.Perms, "|") + ")"
+	}
+	return s
+}
+
+func describeACL(acl snapshot.ACL) string {
+	return fmt.Sprintf("%s:%s", acl.Scheme, acl.ID)
+}
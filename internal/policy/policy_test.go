@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+const testPolicy = `{
+  "rules": [
+    {"name": "no world write under /secure", "path": "/secure/**", "forbid": [{"scheme": "world", "perms": ["write"]}]},
+    {"path": "/app/*", "require": [{"scheme": "digest"}]}
+  ]
+}`
+
+func testTree() *snapshot.Tree {
+	root := &snapshot.Node{ID: "/", Path: "", ACLRef: -1}
+	secure := &snapshot.Node{ID: "secure", Path: "/secure", Parent: root, ACLRef: 1}
+	leak := &snapshot.Node{ID: "leak", Path: "/secure/leak", Parent: secure, ACLRef: -1}
+	app := &snapshot.Node{ID: "app", Path: "/app", Parent: root, ACLRef: -1}
+	cfg := &snapshot.Node{ID: "cfg", Path: "/app/cfg", Parent: app, ACLRef: 1}
+	open := &snapshot.Node{ID: "open", Path: "/app/open", Parent: app, ACLRef: 2}
+	root.Children = []*snapshot.Node{secure, app}
+	secure.Children = []*snapshot.Node{leak}
+	app.Children = []*snapshot.Node{cfg, open}
+	return &snapshot.Tree{
+		Root: root,
+		ACLs: map[int64][]snapshot.ACL{
+			1: {{Perms: 31, Scheme: "digest", ID: "admin:hash"}},
+			2: {{Perms: 1, Scheme: "world", ID: "anyone"}},
+		},
+	}
+}
+
+func TestEvaluateFlagsViolatingNodes(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	violations := p.Evaluate(testTree())
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.Node.Path+": "+v.Rule+": "+v.Reason)
+	}
+	want := []string{
+		"/app/open: /app/*: missing digest:*",
+		"/secure/leak: no world write under /secure: forbidden world:anyone",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected violations:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestLoadRejectsInvalidPolicies(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"unknown-field.json": `{"rules": [{"path": "/a", "deny": []}]}`,
+		"no-path.json":       `{"rules": [{"forbid": [{"scheme": "world"}]}]}`,
+		"bad-perm.json":      `{"rules": [{"path": "/a", "forbid": [{"perms": ["fly"]}]}]}`,
+	} {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(body), 0o644); err != nil {
+			t.Fatalf("write policy: %v", err)
+		}
+		if _, err := Load(file); err == nil {
+			t.Fatalf("expected %s to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+)
+
+// knownACLSchemes are the authentication schemes that ship with ZooKeeper.
+var knownACLSchemes = map[string]bool{
+	"world":  true,
+	"auth":   true,
+	"digest": true,
+	"ip":     true,
+	"sasl":   true,
+	"x509":   true,
+}
+
+// AuditACLSchemes returns a warning for each ACL with a scheme outside the
+// known ones, naming how many nodes use it and the first of them.
+func (t *Tree) AuditACLSchemes() []string {
+	if t == nil || t.Root == nil {
+		return nil
+	}
+	type usage struct {
+		count int
+		first string
+	}
+	used := make(map[int64]*usage)
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if u, ok := used[n.ACLRef]; ok {
+			u.count++
+		} else {
+			used[n.ACLRef] = &usage{count: 1, first: n.Path}
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(t.Root)
+
+	refs := make([]int64, 0, len(t.ACLs))
+	for ref := range t.ACLs {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i] < refs[j] })
+
+	var warnings []string
+	for _, ref := range refs {
+		u, ok := used[ref]
+		if !ok {
+			continue
+		}
+		for _, entry := range t.ACLs[ref] {
+			if knownACLSchemes[entry.Scheme] {
+				continue
+			}
+			first := u.first
+			if first == "" {
+				first = "/"
+			}
+			warnings = append(warnings, fmt.Sprintf("ACL %d uses unknown scheme %q (%d nodes, e.g. %s)", ref, entry.Scheme, u.count, first))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,47 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditACLSchemesFlagsUnknownSchemes(t *testing.T) {
+	root := &Node{ID: "/", Path: "", ACLRef: 1}
+	a := &Node{ID: "a", Path: "/a", Parent: root, ACLRef: 2}
+	b := &Node{ID: "b", Path: "/b", Parent: root, ACLRef: 2}
+	root.Children = []*Node{a, b}
+	tree := &Tree{
+		Root: root,
+		ACLs: map[int64][]ACL{
+			1: {{Perms: 31, Scheme: "world", ID: "anyone"}, {Perms: 1, Scheme: "sasl", ID: "zk"}},
+			2: {{Perms: 31, Scheme: "digest", ID: "u:h"}, {Perms: 1, Scheme: "bogus", ID: "x"}},
+			3: {{Perms: 31, Scheme: "unused", ID: "x"}},
+		},
+	}
+
+	warnings := tree.AuditACLSchemes()
+	if len(warnings) != 1 {
+		t.Fatalf("expected one warning, got %q", warnings)
+	}
+	if want := `ACL 2 uses unknown scheme "bogus" (2 nodes, e.g. /a)`; warnings[0] != want {
+		t.Fatalf("expected %q, got %q", want, warnings[0])
+	}
+}
+
+func TestParseStrictACLSchemesAcceptsKnownSchemes(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "snapshot.test")
+	if err := os.WriteFile(tmp, buildTestSnapshot(), 0o644); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+	tree, err := ParseFileWithOptions(tmp, ParseOptions{StrictACLSchemes: true})
+	if err != nil {
+		t.Fatalf("ParseFileWithOptions() error = %v", err)
+	}
+	for _, w := range tree.Warnings {
+		if strings.Contains(w, "unknown scheme") {
+			t.Fatalf("expected no scheme warnings, got %q", w)
+		}
+	}
+}
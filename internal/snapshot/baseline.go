@@ -0,0 +1,108 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Baseline records the structure of a tree: the content hash and ACL
+// reference of every node by path. It is small enough to keep around and
+// compare later snapshots against without the tree it was taken from.
+type Baseline struct {
+	Nodes map[string]BaselineEntry `json:"nodes"`
+}
+
+// BaselineEntry is what a baseline remembers about one node.
+type BaselineEntry struct {
+	Hash   string `json:"hash"`
+	ACLRef int64  `json:"acl"`
+}
+
+// BaselineDiff lists how a tree differs from a baseline. Removed holds the
+// paths of baseline nodes missing from the tree.
+type BaselineDiff struct {
+	Added   []*Node
+	Changed []*Node
+	Removed []string
+}
+
+// Baseline records the tree's structure. The root is left out.
+func (t *Tree) Baseline() *Baseline {
+	b := &Baseline{Nodes: make(map[string]BaselineEntry)}
+	t.walkNodes(func(n *Node) {
+		b.Nodes[n.Path] = BaselineEntry{Hash: n.ContentHash(), ACLRef: n.ACLRef}
+	})
+	return b
+}
+
+// SaveBaseline writes b to file as JSON.
+func SaveBaseline(file string, b *Baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode baseline: %w", err)
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write baseline: %w", err)
+	}
+	return nil
+}
+
+// LoadBaseline reads a baseline written by SaveBaseline.
+func LoadBaseline(file string) (*Baseline, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse baseline: %w", err)
+	}
+	if b.Nodes == nil {
+		return nil, fmt.Errorf("parse baseline: no nodes")
+	}
+	return &b, nil
+}
+
+// Compare reports the nodes of t that are new or changed relative to the
+// baseline, in tree order, and the sorted baseline paths t no longer has.
+// Changed nodes have different data or a different ACL reference.
+func (b *Baseline) Compare(t *Tree) BaselineDiff {
+	var diff BaselineDiff
+	seen := make(map[string]bool, len(b.Nodes))
+	t.walkNodes(func(n *Node) {
+		seen[n.Path] = true
+		old, ok := b.Nodes[n.Path]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, n)
+		case old != (BaselineEntry{Hash: n.ContentHash(), ACLRef: n.ACLRef}):
+			diff.Changed = append(diff.Changed, n)
+		}
+	})
+	for path := range b.Nodes {
+		if !seen[path] {
+			diff.Removed = append(diff.Removed, path)
+		}
+	}
+	sort.Strings(diff.Removed)
+	return diff
+}
+
+// walkNodes calls fn for every node below the root, depth first.
+func (t *Tree) walkNodes(fn func(n *Node)) {
+	if t == nil || t.Root == nil {
+		return
+	}
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		fn(n)
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	for _, child := range t.Root.Children {
+		walk(child)
+	}
+}
@@ -0,0 +1,48 @@
+package snapshot
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBaselineFlagsAddedChangedAndRemovedNodes(t *testing.T) {
+	tree, err := ParseBytes(buildTestSnapshot())
+	if err != nil {
+		t.Fatalf("parse snapshot: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "baseline.json")
+	saved := tree.Baseline()
+	saved.Nodes["/gone"] = BaselineEntry{Hash: "00", ACLRef: 1}
+	if err := SaveBaseline(file, saved); err != nil {
+		t.Fatalf("save baseline: %v", err)
+	}
+
+	later, err := ParseBytes(buildTestSnapshot())
+	if err != nil {
+		t.Fatalf("parse snapshot: %v", err)
+	}
+	a := later.NodesByPath["/a"]
+	changed := later.NodesByPath["/a/b"]
+	changed.Data = []byte("changed")
+	added := &Node{ID: "c", Path: "/a/c", Parent: a}
+	a.Children = append(a.Children, added)
+
+	baseline, err := LoadBaseline(file)
+	if err != nil {
+		t.Fatalf("load baseline: %v", err)
+	}
+	diff := baseline.Compare(later)
+	if len(diff.Added) != 1 || diff.Added[0] != added {
+		t.Fatalf("expected /a/c added, got %v", diff.Added)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0] != changed {
+		t.Fatalf("expected /a/b changed, got %v", diff.Changed)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0] != "/gone" {
+		t.Fatalf("expected /gone removed, got %v", diff.Removed)
+	}
+
+	if unchanged := tree.Baseline().Compare(tree); len(unchanged.Added)+len(unchanged.Changed)+len(unchanged.Removed) != 0 {
+		t.Fatalf("expected no differences against the tree's own baseline, got %+v", unchanged)
+	}
+}
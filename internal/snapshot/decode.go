@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"encoding/binary"
 	"fmt"
+	"hash"
 	"io"
 )
 
@@ -11,6 +12,8 @@ type decoder struct {
 	r          *bufio.Reader
 	off        int64
 	onProgress func(offset int64)
+	// checksum, if set, is fed every byte decoded.
+	checksum hash.Hash32
 }
 
 func newDecoder(r io.Reader, onProgress func(offset int64)) *decoder {
@@ -30,6 +33,9 @@ func (d *decoder) readN(n int) ([]byte, error) {
 		return nil, d.wrapErr(err)
 	}
 	d.off += int64(n)
+	if d.checksum != nil {
+		d.checksum.Write(buf)
+	}
 	if d.onProgress != nil {
 		d.onProgress(d.off)
 	}
@@ -87,6 +93,65 @@ func (d *decoder) ReadBuffer(maxLen int32) ([]byte, error) {
 	return d.readN(int(l))
 }
 
+// sealLen is the size of a seal: an 8-byte checksum and the string "/".
+const sealLen = 8 + 4 + 1
+
+// SkipSealed skips a section of n bytes followed by a seal, as ZooKeeper
+// 3.6+ appends after the first seal. It consumes nothing and reports false
+// when the next bytes do not have that shape.
+func (d *decoder) SkipSealed(n int) (bool, error) {
+	b, err := d.r.Peek(n + sealLen)
+	if err != nil {
+		// Too few bytes left for the section.
+		return false, nil
+	}
+	path := b[n+8:]
+	if binary.BigEndian.Uint32(path) != 1 || path[4] != '/' {
+		return false, nil
+	}
+	if _, err := d.readN(n + sealLen); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Drain reads until EOF and reports how many bytes were consumed and how many
+// of them were non-zero.
+func (d *decoder) Drain() (total, nonZero int64, err error) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := d.r.Read(buf)
+		for _, b := range buf[:n] {
+			if b != 0 {
+				nonZero++
+			}
+		}
+		total += int64(n)
+		d.off += int64(n)
+		if n > 0 && d.onProgress != nil {
+			d.onProgress(d.off)
+		}
+		if readErr == io.EOF {
+			return total, nonZero, nil
+		}
+		if readErr != nil {
+			return total, nonZero, d.wrapErr(readErr)
+		}
+	}
+}
+
 func (d *decoder) wrapErr(err error) error {
 	return fmt.Errorf("decode failed at offset %d: %w", d.off, err)
 }
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
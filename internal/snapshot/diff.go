@@ -0,0 +1,61 @@
+package snapshot
+
+import "sort"
+
+// ChangeKind tells how a node differs between two snapshots.
+type ChangeKind int
+
+const (
+	ChangeAdded ChangeKind = iota
+	ChangeRemoved
+	ChangeModified
+)
+
+// NodeChange is a node that differs between two snapshots. Old is nil for
+// added nodes and New is nil for removed ones.
+type NodeChange struct {
+	Path string
+	Kind ChangeKind
+	Old  *Node
+	New  *Node
+}
+
+// DataChanged reports whether a modified node's data differs.
+func (c NodeChange) DataChanged() bool {
+	return c.Old != nil && c.New != nil && c.Old.ContentHash() != c.New.ContentHash()
+}
+
+// VersionChanged reports whether a modified node's data version differs.
+func (c NodeChange) VersionChanged() bool {
+	return c.Old != nil && c.New != nil && c.Old.Stat.Version != c.New.Stat.Version
+}
+
+// Diff reports the nodes below the root that b added, removed or modified
+// relative to a, sorted by path. Modified nodes have different data or a
+// different data version.
+func Diff(a, b *Tree) []NodeChange {
+	old := make(map[string]*Node)
+	a.walkNodes(func(n *Node) {
+		old[n.Path] = n
+	})
+	var changes []NodeChange
+	b.walkNodes(func(n *Node) {
+		prev, ok := old[n.Path]
+		delete(old, n.Path)
+		change := NodeChange{Path: n.Path, Kind: ChangeModified, Old: prev, New: n}
+		switch {
+		case !ok:
+			change.Kind = ChangeAdded
+			changes = append(changes, change)
+		case change.DataChanged() || change.VersionChanged():
+			changes = append(changes, change)
+		}
+	})
+	for path, n := range old {
+		changes = append(changes, NodeChange{Path: path, Kind: ChangeRemoved, Old: n})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
@@ -0,0 +1,53 @@
+package snapshot
+
+import "testing"
+
+func TestDiffReportsAddedRemovedAndModifiedNodes(t *testing.T) {
+	a, err := ParseBytes(buildTestSnapshot())
+	if err != nil {
+		t.Fatalf("ParseBytes() error = %v", err)
+	}
+	b, err := ParseBytes(buildTestSnapshot())
+	if err != nil {
+		t.Fatalf("ParseBytes() error = %v", err)
+	}
+	if changes := Diff(a, b); len(changes) != 0 {
+		t.Fatalf("expected identical snapshots not to differ, got %+v", changes)
+	}
+
+	// Replace /a, dropping its child, rather than editing its data, whose
+	// hash the parser already took.
+	for i, child := range b.Root.Children {
+		if child.Path == "/a" {
+			b.Root.Children[i] = &Node{ID: "a", Path: "/a", Parent: b.Root, Data: []byte(`{"k":2}`), Stat: child.Stat}
+		}
+	}
+	b.NodesByPath["/c"].Stat.Version++
+	added := &Node{ID: "d", Path: "/d", Parent: b.Root}
+	b.Root.Children = append(b.Root.Children, added)
+
+	changes := Diff(a, b)
+	want := []struct {
+		path    string
+		kind    ChangeKind
+		data    bool
+		version bool
+	}{
+		{"/a", ChangeModified, true, false},
+		{"/a/b", ChangeRemoved, false, false},
+		{"/c", ChangeModified, false, true},
+		{"/d", ChangeAdded, false, false},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Path != w.path || c.Kind != w.kind || c.DataChanged() != w.data || c.VersionChanged() != w.version {
+			t.Fatalf("change %d: expected %+v, got %s kind %d data %v version %v", i, w, c.Path, c.Kind, c.DataChanged(), c.VersionChanged())
+		}
+	}
+	if changes[1].Old == nil || changes[1].New != nil || changes[3].Old != nil || changes[3].New != added {
+		t.Fatalf("expected removed and added nodes on their side only, got %+v", changes)
+	}
+}
@@ -0,0 +1,52 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LatestInDir returns the path of the snapshot with the highest zxid in dir,
+// such as ZooKeeper's version-2 data directory. Snapshots are named
+// snapshot.<zxid in hex>, optionally followed by a compression suffix like
+// .gz; other files are ignored.
+func LatestInDir(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("read snapshot directory: %w", err)
+	}
+	var latest string
+	var latestZxid uint64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		zxid, ok := snapshotZxid(entry.Name())
+		if !ok {
+			continue
+		}
+		if latest == "" || zxid > latestZxid {
+			latest, latestZxid = entry.Name(), zxid
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no snapshot.<zxid> files in %s", dir)
+	}
+	return filepath.Join(dir, latest), nil
+}
+
+// snapshotZxid parses the zxid from a snapshot file name.
+func snapshotZxid(name string) (uint64, bool) {
+	rest, ok := strings.CutPrefix(name, "snapshot.")
+	if !ok {
+		return 0, false
+	}
+	hex, _, _ := strings.Cut(rest, ".")
+	zxid, err := strconv.ParseUint(hex, 16, 64)
+	if err != nil {
+		return 0, false
+	}
+	return zxid, true
+}
@@ -0,0 +1,33 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLatestInDirPicksHighestZxid(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"snapshot.ff", "snapshot.1a0", "snapshot.1b.gz", "snapshot.zz", "log.200", "acceptedEpoch"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "snapshot.fff"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	got, err := LatestInDir(dir)
+	if err != nil {
+		t.Fatalf("LatestInDir() error = %v", err)
+	}
+	if want := filepath.Join(dir, "snapshot.1a0"); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestLatestInDirWithoutSnapshots(t *testing.T) {
+	if _, err := LatestInDir(t.TempDir()); err == nil {
+		t.Fatal("expected an error for a directory without snapshots")
+	}
+}
@@ -0,0 +1,42 @@
+package snapshot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// dumpPreviewLen is how many bytes of a node's data Dump previews.
+const dumpPreviewLen = 40
+
+// Dump writes one tab-separated line per node of tree, the root included as
+// "/", to w: the path, the data length in bytes and a quoted preview of the
+// start of the data. Nodes are sorted by path so that dumps of the same
+// snapshot are byte-identical.
+func Dump(w io.Writer, tree *Tree) error {
+	nodes := sortedNodes(tree)
+
+	bw := bufio.NewWriter(w)
+	for _, n := range nodes {
+		path := n.Path
+		if path == "" {
+			path = "/"
+		}
+		fmt.Fprintf(bw, "%s\t%d\t%s\n", path, n.DataLen(), dumpPreview(n))
+	}
+	return bw.Flush()
+}
+
+// dumpPreview quotes the start of the node's data, marking data cut short
+// with "...", or is empty for nodes without data.
+func dumpPreview(n *Node) string {
+	if n.DataLen() == 0 {
+		return ""
+	}
+	data := n.Bytes()
+	if len(data) <= dumpPreviewLen {
+		return strconv.Quote(string(data))
+	}
+	return strconv.Quote(string(data[:dumpPreviewLen])) + "..."
+}
@@ -0,0 +1,35 @@
+package snapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDumpWritesSortedNodesWithPreview(t *testing.T) {
+	tree, err := ParseBytes(buildTestSnapshot())
+	if err != nil {
+		t.Fatalf("ParseBytes() error = %v", err)
+	}
+	var out bytes.Buffer
+	if err := Dump(&out, tree); err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	want := "/\t0\t\n" +
+		"/a\t7\t\"{\\\"k\\\":1}\"\n" +
+		"/a/b\t5\t\"child\"\n" +
+		"/c\t5\t\"plain\"\n"
+	if out.String() != want {
+		t.Fatalf("unexpected dump:\n%s", out.String())
+	}
+}
+
+func TestDumpPreviewIsCutShort(t *testing.T) {
+	n := &Node{Data: []byte(strings.Repeat("x", dumpPreviewLen) + "\x00tail")}
+	if got, want := dumpPreview(n), `"`+strings.Repeat("x", dumpPreviewLen)+`"...`; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if got := dumpPreview(&Node{Data: []byte{0xff, '\n'}}); got != `"\xff\n"` {
+		t.Fatalf("expected escaped binary data, got %q", got)
+	}
+}
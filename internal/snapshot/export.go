@@ -0,0 +1,87 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// exportNode is the JSON form of a node written by ExportJSON. Data is
+// base64 encoded and null for nodes without data.
+type exportNode struct {
+	Path   string     `json:"path"`
+	Data   []byte     `json:"data"`
+	ACLRef int64      `json:"acl"`
+	Stat   exportStat `json:"stat"`
+}
+
+type exportStat struct {
+	Czxid          int64 `json:"czxid"`
+	Mzxid          int64 `json:"mzxid"`
+	Ctime          int64 `json:"ctime"`
+	Mtime          int64 `json:"mtime"`
+	Version        int32 `json:"version"`
+	Cversion       int32 `json:"cversion"`
+	Aversion       int32 `json:"aversion"`
+	EphemeralOwner int64 `json:"ephemeralOwner"`
+	Pzxid          int64 `json:"pzxid"`
+}
+
+// sortedNodes returns every node of tree, the root first, sorted by path.
+func sortedNodes(tree *Tree) []*Node {
+	var nodes []*Node
+	if tree != nil && tree.Root != nil {
+		nodes = append(nodes, tree.Root)
+	}
+	tree.walkNodes(func(n *Node) {
+		nodes = append(nodes, n)
+	})
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Path < nodes[j].Path
+	})
+	return nodes
+}
+
+// ExportJSON writes every node of tree, the root included as "/", to w as
+// {"nodes": [...]} with one node per line. Nodes are sorted by path so that
+// exports of the same snapshot are byte-identical.
+func ExportJSON(w io.Writer, tree *Tree) error {
+	nodes := sortedNodes(tree)
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`{"nodes": [`)
+	for i, n := range nodes {
+		path := n.Path
+		if path == "" {
+			path = "/"
+		}
+		s := n.Stat
+		line, err := json.Marshal(exportNode{
+			Path:   path,
+			Data:   n.Bytes(),
+			ACLRef: n.ACLRef,
+			Stat: exportStat{
+				Czxid:          s.Czxid,
+				Mzxid:          s.Mzxid,
+				Ctime:          s.Ctime,
+				Mtime:          s.Mtime,
+				Version:        s.Version,
+				Cversion:       s.Cversion,
+				Aversion:       s.Aversion,
+				EphemeralOwner: s.EphemeralOwner,
+				Pzxid:          s.Pzxid,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString("\n  ")
+		bw.Write(line)
+	}
+	bw.WriteString("\n]}\n")
+	return bw.Flush()
+}
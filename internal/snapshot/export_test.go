@@ -0,0 +1,48 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestExportJSONWritesSortedNodes(t *testing.T) {
+	tree, err := ParseBytes(buildTestSnapshot())
+	if err != nil {
+		t.Fatalf("ParseBytes() error = %v", err)
+	}
+	var out bytes.Buffer
+	if err := ExportJSON(&out, tree); err != nil {
+		t.Fatalf("ExportJSON() error = %v", err)
+	}
+
+	var doc struct {
+		Nodes []struct {
+			Path string `json:"path"`
+			Data []byte `json:"data"`
+			ACL  int64  `json:"acl"`
+			Stat struct {
+				Mzxid int64 `json:"mzxid"`
+			} `json:"stat"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("export is not valid JSON: %v\n%s", err, out.String())
+	}
+	var paths []string
+	for _, n := range doc.Nodes {
+		paths = append(paths, n.Path)
+	}
+	if want := []string{"/", "/a", "/a/b", "/c"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("expected paths %v, got %v", want, paths)
+	}
+	if a := doc.Nodes[1]; string(a.Data) != `{"k":1}` || a.ACL != 1 || a.Stat.Mzxid != tree.NodesByPath["/a"].Stat.Mzxid {
+		t.Fatalf("unexpected /a export: %+v", a)
+	}
+
+	var again bytes.Buffer
+	if err := ExportJSON(&again, tree); err != nil || !bytes.Equal(out.Bytes(), again.Bytes()) {
+		t.Fatal("expected identical exports of the same tree")
+	}
+}
@@ -0,0 +1,43 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// contentHashLen is the number of SHA-256 bytes kept in a content hash; enough
+// to tell blobs apart in practice while staying readable.
+const contentHashLen = 8
+
+// ContentHash returns a short hex SHA-256 digest of the node's data. It is
+// computed on first use and cached on the node.
+func (n *Node) ContentHash() string {
+	if n.contentHash == "" {
+		n.contentHash = hashData(n.Bytes())
+	}
+	return n.contentHash
+}
+
+func hashData(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:contentHashLen])
+}
+
+// NodesByContentHash groups every node of the tree, root included, by the hash
+// of its data.
+func (t *Tree) NodesByContentHash() map[string][]*Node {
+	groups := make(map[string][]*Node)
+	if t == nil || t.Root == nil {
+		return groups
+	}
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		hash := n.ContentHash()
+		groups[hash] = append(groups[hash], n)
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(t.Root)
+	return groups
+}
@@ -0,0 +1,36 @@
+package snapshot
+
+import "testing"
+
+func TestContentHashMatchesForIdenticalData(t *testing.T) {
+	a := &Node{Path: "/a", Data: []byte("same")}
+	b := &Node{Path: "/b", Data: []byte("same")}
+	c := &Node{Path: "/c", Data: []byte("other")}
+
+	if a.ContentHash() != b.ContentHash() {
+		t.Fatalf("expected identical data to share a hash, got %s and %s", a.ContentHash(), b.ContentHash())
+	}
+	if a.ContentHash() == c.ContentHash() {
+		t.Fatal("expected different data to hash differently")
+	}
+	if len(a.ContentHash()) != 2*contentHashLen {
+		t.Fatalf("unexpected hash length: %q", a.ContentHash())
+	}
+}
+
+func TestNodesByContentHashCountsDistinctBlobs(t *testing.T) {
+	root := &Node{ID: "/", Path: ""}
+	a := &Node{ID: "a", Path: "/a", Parent: root, Data: []byte("x")}
+	b := &Node{ID: "b", Path: "/b", Parent: root, Data: []byte("x")}
+	c := &Node{ID: "c", Path: "/c", Parent: root, Data: []byte("y")}
+	root.Children = []*Node{a, b, c}
+
+	groups := (&Tree{Root: root}).NodesByContentHash()
+	// root (empty), "x" (a, b) and "y" (c).
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 distinct blobs, got %d", len(groups))
+	}
+	if len(groups[a.ContentHash()]) != 2 {
+		t.Fatalf("expected a and b grouped, got %d nodes", len(groups[a.ContentHash()]))
+	}
+}
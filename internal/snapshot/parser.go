@@ -1,7 +1,14 @@
 package snapshot
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
+	"hash/adler32"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,10 +16,28 @@ import (
 
 const (
 	snapshotMagic = 0x5A4B534E // "ZKSN"
-	maxStringLen  = int32(16 * 1024 * 1024)
-	maxBufferLen  = int32(256 * 1024 * 1024)
+	// swappedMagic is snapshotMagic read from a little-endian file.
+	swappedMagic = 0x4E534B5A
+	// The default limits on the length of a single string (paths, ACL
+	// schemes and IDs) and of a single node's data.
+	defaultMaxStringLen = int32(16 * 1024 * 1024)
+	defaultMaxBufferLen = int32(256 * 1024 * 1024)
+	// zxidDigestLen is the zxid, digest version and digest ZooKeeper 3.6+
+	// writes after the first seal; lastProcessedZxidLen is the zxid 3.7+
+	// writes after that.
+	zxidDigestLen        = 8 + 4 + 8
+	lastProcessedZxidLen = 8
 )
 
+// limits bounds the lengths the parser accepts, guarding against corrupt
+// length prefixes allocating huge buffers.
+type limits struct {
+	maxStringLen int32
+	maxBufferLen int32
+}
+
+var defaultLimits = limits{maxStringLen: defaultMaxStringLen, maxBufferLen: defaultMaxBufferLen}
+
 type Header struct {
 	Magic   int32
 	Version int32
@@ -32,13 +57,24 @@ type StatPersisted struct {
 }
 
 type Node struct {
-	ID       string
-	Path     string
-	Data     []byte
-	ACLRef   int64
-	Stat     StatPersisted
+	ID   string
+	Path string
+	// Data holds the node's data unless it was spilled to disk while
+	// parsing; Bytes returns it either way.
+	Data   []byte
+	ACLRef int64
+	Stat   StatPersisted
+	// DiskSize is the number of bytes the node's record occupies in the
+	// snapshot: path, data buffer, ACL reference and stat.
+	DiskSize int64
 	Parent   *Node
 	Children []*Node
+
+	contentHash  string
+	spilled      *spilledData
+	subtreeSize  int
+	descendants  int
+	subtreeSized bool
 }
 
 type ACL struct {
@@ -52,13 +88,85 @@ type Tree struct {
 	Root        *Node
 	NodesByPath map[string]*Node
 	ACLs        map[int64][]ACL
+	// Sessions maps the ID of every session open at snapshot time to its
+	// timeout in milliseconds.
+	Sessions map[int64]int32
+	// Warnings lists non-fatal oddities found while parsing.
+	Warnings []string
+
+	spill *spiller
+}
+
+// ErrTruncated is returned when the snapshot ends in the middle of the node
+// list. The tree of the nodes read up to that point is returned along with
+// it, and must be closed like any other.
+var ErrTruncated = errors.New("snapshot truncated")
+
+// ErrChecksumMismatch is returned when ParseOptions.VerifyChecksum is set
+// and the checksum in the seal does not match the snapshot's data.
+var ErrChecksumMismatch = errors.New("snapshot checksum mismatch")
+
+// ParseOptions controls optional parser behavior.
+type ParseOptions struct {
+	// Progress is called periodically with the number of bytes read so far.
+	// totalBytes is 0 when the size of the input is unknown.
+	Progress func(readBytes, totalBytes int64)
+	// VerifyTrailer adds a warning when non-zero bytes follow the seal and
+	// the zxid digest sections of ZooKeeper 3.6+.
+	VerifyTrailer bool
+	// VerifyChecksum compares the Adler32 checksum in the seal with the one
+	// of the decoded bytes and fails with ErrChecksumMismatch when they
+	// differ. Snapshots without a seal fail too.
+	VerifyChecksum bool
+	// StrictACLSchemes adds a warning for ACLs using schemes ZooKeeper does
+	// not ship with; see Tree.AuditACLSchemes.
+	StrictACLSchemes bool
+	// SpillThreshold moves the data of nodes larger than this many bytes to
+	// a temp file, read back on access. Zero keeps all data in memory. Close
+	// the tree to remove the temp file.
+	SpillThreshold int
+	// SpillDir is where the temp file is created; empty means os.TempDir.
+	SpillDir string
+	// Context, if set, aborts parsing with its error once it is done.
+	Context context.Context
+	// MaxBufferLen is the largest node data accepted, in bytes; zero means
+	// 256MB. Raise it for snapshots holding larger blobs.
+	MaxBufferLen int32
+	// MaxStringLen is the longest path, ACL scheme or ACL ID accepted, in
+	// bytes; zero means 16MB.
+	MaxStringLen int32
 }
 
+func (o ParseOptions) limits() limits {
+	l := defaultLimits
+	if o.MaxStringLen > 0 {
+		l.maxStringLen = o.MaxStringLen
+	}
+	if o.MaxBufferLen > 0 {
+		l.maxBufferLen = o.MaxBufferLen
+	}
+	return l
+}
+
+// cancelCheckInterval is how many nodes are parsed between checks of
+// ParseOptions.Context.
+const cancelCheckInterval = 1024
+
 func ParseFile(path string) (*Tree, error) {
 	return ParseFileWithProgress(path, nil)
 }
 
 func ParseFileWithProgress(path string, progress func(readBytes, totalBytes int64)) (*Tree, error) {
+	return ParseFileWithOptions(path, ParseOptions{Progress: progress})
+}
+
+// ParseFileContext is ParseFileWithProgress, returning ctx.Err() soon after
+// ctx is done.
+func ParseFileContext(ctx context.Context, path string, progress func(readBytes, totalBytes int64)) (*Tree, error) {
+	return ParseFileWithOptions(path, ParseOptions{Progress: progress, Context: ctx})
+}
+
+func ParseFileWithOptions(path string, opts ParseOptions) (*Tree, error) {
 	f, err := os.Open(filepath.Clean(path))
 	if err != nil {
 		return nil, fmt.Errorf("open snapshot file: %w", err)
@@ -67,50 +175,169 @@ func ParseFileWithProgress(path string, progress func(readBytes, totalBytes int6
 
 	var total int64
 	if info, statErr := f.Stat(); statErr == nil {
+		if info.IsDir() {
+			return nil, fmt.Errorf("open snapshot file: %s is a directory, expected a snapshot file", path)
+		}
 		total = info.Size()
 	}
 
+	// Archived snapshots are often gzipped. Their progress is reported in
+	// compressed bytes so that it still adds up to the file size.
+	counted := &countingReader{r: f}
+	br := bufio.NewReader(counted)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("open gzipped snapshot file: %w", err)
+		}
+		defer gz.Close()
+		if progress := opts.Progress; progress != nil {
+			opts.Progress = func(_, _ int64) {
+				progress(min(counted.n, total), total)
+			}
+		}
+		return ParseReaderWithOptions(gz, 0, opts)
+	}
+	return ParseReaderWithOptions(br, total, opts)
+}
+
+// Parse reads a snapshot from r; it is short for ParseReader.
+func Parse(r io.Reader) (*Tree, error) {
+	return ParseReader(r)
+}
+
+// ParseReader reads a snapshot from r, e.g. one already in memory or
+// streamed from another process.
+func ParseReader(r io.Reader) (*Tree, error) {
+	return ParseReaderWithProgress(r, 0, nil)
+}
+
+// ParseReaderWithProgress reads a snapshot of total bytes from r, reporting
+// the bytes read to progress. Pass 0 when the total is unknown.
+func ParseReaderWithProgress(r io.Reader, total int64, progress func(readBytes, totalBytes int64)) (*Tree, error) {
+	return ParseReaderWithOptions(r, total, ParseOptions{Progress: progress})
+}
+
+// ParseBytes parses a snapshot held in memory.
+func ParseBytes(b []byte) (*Tree, error) {
+	return Parse(bytes.NewReader(b))
+}
+
+// InspectHeader reads only the snapshot header from r, leaving the rest of
+// the snapshot unread, to triage files without parsing their trees.
+func InspectHeader(r io.Reader) (Header, error) {
+	return parseHeader(newDecoder(r, nil))
+}
+
+// ParseReaderWithOptions reads a snapshot of total bytes from r; total is 0
+// when unknown.
+func ParseReaderWithOptions(r io.Reader, total int64, opts ParseOptions) (*Tree, error) {
+	if total < 0 {
+		total = 0
+	}
+	progress := opts.Progress
 	const reportStep int64 = 512 * 1024
 	lastReported := int64(-reportStep)
 	reportProgress := func(read int64) {
-		if progress == nil || total <= 0 {
+		if progress == nil {
 			return
 		}
-		if read-lastReported < reportStep && read < total {
+		if read-lastReported < reportStep && (total == 0 || read < total) {
 			return
 		}
-		if read > total {
+		if total > 0 && read > total {
 			read = total
 		}
 		lastReported = read
 		progress(read, total)
 	}
 	reportProgress(0)
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	d := newDecoder(f, reportProgress)
+	d := newDecoder(r, reportProgress)
+	if opts.VerifyChecksum {
+		d.checksum = adler32.New()
+	}
 	header, err := parseHeader(d)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := parseSessions(d); err != nil {
+	sessions, err := parseSessions(d)
+	if err != nil {
 		return nil, err
 	}
-	acls, err := parseACLCache(d)
+	lim := opts.limits()
+	acls, err := parseACLCache(d, lim)
 	if err != nil {
 		return nil, err
 	}
 
-	tree, err := parseNodes(d, header, acls)
+	spill := &spiller{dir: opts.SpillDir, threshold: opts.SpillThreshold}
+	tree, err := parseNodes(ctx, d, header, acls, spill, lim)
+	if errors.Is(err, ErrTruncated) {
+		tree.Sessions = sessions
+		tree.Warnings = append(tree.Warnings, err.Error())
+		return tree, err
+	}
 	if err != nil {
+		spill.remove()
 		return nil, err
 	}
+	tree.Sessions = sessions
 
-	// Read and ignore the first seal (checksum + "/"), if present.
-	if _, err := d.ReadInt64(); err == nil {
-		if _, err := d.ReadString(maxStringLen); err != nil {
+	// Read the first seal (checksum + "/"), if present. Anything after it is
+	// drained so padding appended by other tools never fails the parse.
+	var computed uint32
+	if d.checksum != nil {
+		computed = d.checksum.Sum32()
+		d.checksum = nil
+	}
+	seal, err := d.ReadInt64()
+	if err != nil && opts.VerifyChecksum {
+		tree.Close()
+		return nil, fmt.Errorf("verify checksum: snapshot has no seal: %w", err)
+	}
+	if err == nil {
+		if opts.VerifyChecksum && uint64(seal) != uint64(computed) {
+			tree.Close()
+			return nil, fmt.Errorf("%w: seal has %#x, data has %#x", ErrChecksumMismatch, uint64(seal), computed)
+		}
+		if _, err := d.ReadString(lim.maxStringLen); err != nil {
+			tree.Close()
+			return nil, err
+		}
+		// ZooKeeper 3.6+ follows the seal with a sealed zxid digest, and 3.7+
+		// with a sealed last processed zxid; neither counts as trailing.
+		for _, n := range []int{zxidDigestLen, lastProcessedZxidLen} {
+			ok, err := d.SkipSealed(n)
+			if err != nil {
+				tree.Close()
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+		}
+		sealEnd := d.Offset()
+		trailing, nonZero, err := d.Drain()
+		if err != nil {
+			tree.Close()
 			return nil, err
 		}
+		if opts.VerifyTrailer && nonZero > 0 {
+			tree.Warnings = append(tree.Warnings, fmt.Sprintf("%d trailing bytes after seal at offset %d (%d non-zero)", trailing, sealEnd, nonZero))
+		}
+	}
+
+	if opts.StrictACLSchemes {
+		tree.Warnings = append(tree.Warnings, tree.AuditACLSchemes()...)
 	}
 
 	if progress != nil && total > 0 {
@@ -133,32 +360,39 @@ func parseHeader(d *decoder) (Header, error) {
 	if err != nil {
 		return Header{}, err
 	}
+	if magic == swappedMagic {
+		return Header{}, fmt.Errorf("snapshot appears byte-swapped (wrong endianness)")
+	}
 	if magic != snapshotMagic {
 		return Header{}, fmt.Errorf("invalid snapshot magic %x", magic)
 	}
 	return Header{Magic: magic, Version: version, DBID: dbid}, nil
 }
 
-func parseSessions(d *decoder) error {
+func parseSessions(d *decoder) (map[int64]int32, error) {
 	count, err := d.ReadInt32()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if count < 0 {
-		return fmt.Errorf("invalid session count %d", count)
+		return nil, fmt.Errorf("invalid session count %d", count)
 	}
+	sessions := make(map[int64]int32)
 	for i := int32(0); i < count; i++ {
-		if _, err := d.ReadInt64(); err != nil {
-			return err
+		id, err := d.ReadInt64()
+		if err != nil {
+			return nil, err
 		}
-		if _, err := d.ReadInt32(); err != nil {
-			return err
+		timeout, err := d.ReadInt32()
+		if err != nil {
+			return nil, err
 		}
+		sessions[id] = timeout
 	}
-	return nil
+	return sessions, nil
 }
 
-func parseACLCache(d *decoder) (map[int64][]ACL, error) {
+func parseACLCache(d *decoder, lim limits) (map[int64][]ACL, error) {
 	count, err := d.ReadInt32()
 	if err != nil {
 		return nil, err
@@ -185,11 +419,11 @@ func parseACLCache(d *decoder) (map[int64][]ACL, error) {
 			if err != nil {
 				return nil, err
 			}
-			scheme, err := d.ReadString(maxStringLen)
+			scheme, err := d.ReadString(lim.maxStringLen)
 			if err != nil {
 				return nil, err
 			}
-			id, err := d.ReadString(maxStringLen)
+			id, err := d.ReadString(lim.maxStringLen)
 			if err != nil {
 				return nil, err
 			}
@@ -200,38 +434,53 @@ func parseACLCache(d *decoder) (map[int64][]ACL, error) {
 	return acls, nil
 }
 
-func parseNodes(d *decoder, header Header, acls map[int64][]ACL) (*Tree, error) {
+func parseNodes(ctx context.Context, d *decoder, header Header, acls map[int64][]ACL, spill *spiller, lim limits) (*Tree, error) {
 	nodes := make(map[string]*Node)
-
-	for {
-		path, err := d.ReadString(maxStringLen)
-		if err != nil {
-			return nil, err
-		}
-		if path == "/" {
-			break
+	newTree := func() *Tree {
+		root := nodes[""]
+		// Mirror ZooKeeper behavior where "/" also points to root.
+		nodes["/"] = root
+		root.SubtreeSize()
+		return &Tree{
+			Header:      header,
+			Root:        root,
+			NodesByPath: nodes,
+			ACLs:        acls,
+			spill:       spill,
 		}
+	}
 
-		data, err := d.ReadBuffer(maxBufferLen)
-		if err != nil {
-			return nil, err
+	for count := 1; ; count++ {
+		if count%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 		}
-		aclRef, err := d.ReadInt64()
+		start := d.Offset()
+		rec, done, err := readNodeRecord(d, lim)
 		if err != nil {
+			if isTruncation(err) && nodes[""] != nil {
+				return newTree(), fmt.Errorf("%w: node record at offset %d is incomplete: %v", ErrTruncated, start, err)
+			}
 			return nil, err
 		}
-
-		stat, err := parseStatPersisted(d)
-		if err != nil {
-			return nil, err
+		if done {
+			break
+		}
+		path := rec.path
+		if _, dup := nodes[path]; dup {
+			return nil, fmt.Errorf("invalid tree: duplicate path %q at offset %d", path, start)
 		}
 
 		node := &Node{
-			ID:     nodeID(path),
-			Path:   path,
-			Data:   data,
-			ACLRef: aclRef,
-			Stat:   stat,
+			ID:       nodeID(path),
+			Path:     path,
+			ACLRef:   rec.aclRef,
+			Stat:     rec.stat,
+			DiskSize: d.Offset() - start,
+		}
+		if err := spill.maybeSpill(node, rec.data); err != nil {
+			return nil, err
 		}
 		nodes[path] = node
 
@@ -239,29 +488,60 @@ func parseNodes(d *decoder, header Header, acls map[int64][]ACL) (*Tree, error)
 			continue
 		}
 
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid tree: path %q does not start with \"/\"%s", path, separatorHint(path))
+		}
 		parentPath := parentOf(path)
 		parent, ok := nodes[parentPath]
 		if !ok {
-			return nil, fmt.Errorf("invalid tree: parent %q for path %q not found", parentPath, path)
+			return nil, fmt.Errorf("invalid tree: parent %q for path %q at offset %d not found%s", parentPath, path, start, separatorHint(path))
 		}
 		node.Parent = parent
 		parent.Children = append(parent.Children, node)
 	}
 
-	root, ok := nodes[""]
-	if !ok {
+	if nodes[""] == nil {
 		return nil, fmt.Errorf("invalid snapshot: missing root node")
 	}
+	return newTree(), nil
+}
 
-	// Mirror ZooKeeper behavior where "/" also points to root.
-	nodes["/"] = root
+// nodeRecord is one serialized node.
+type nodeRecord struct {
+	path   string
+	data   []byte
+	aclRef int64
+	stat   StatPersisted
+}
 
-	return &Tree{
-		Header:      header,
-		Root:        root,
-		NodesByPath: nodes,
-		ACLs:        acls,
-	}, nil
+// readNodeRecord reads the next node record, reporting done instead at the
+// "/" path that ends the node list.
+func readNodeRecord(d *decoder, lim limits) (rec nodeRecord, done bool, err error) {
+	rec.path, err = d.ReadString(lim.maxStringLen)
+	if err != nil {
+		return rec, false, err
+	}
+	if rec.path == "/" {
+		return rec, true, nil
+	}
+	rec.data, err = d.ReadBuffer(lim.maxBufferLen)
+	if err != nil {
+		return rec, false, err
+	}
+	rec.aclRef, err = d.ReadInt64()
+	if err != nil {
+		return rec, false, err
+	}
+	rec.stat, err = parseStatPersisted(d)
+	if err != nil {
+		return rec, false, err
+	}
+	return rec, false, nil
+}
+
+// isTruncation reports whether err means the input ended early.
+func isTruncation(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
 }
 
 func parseStatPersisted(d *decoder) (StatPersisted, error) {
@@ -314,6 +594,15 @@ func parseStatPersisted(d *decoder) (StatPersisted, error) {
 	}, nil
 }
 
+// separatorHint explains a broken path that looks like it was written with
+// backslash separators instead of "/".
+func separatorHint(path string) string {
+	if !strings.Contains(path, `\`) {
+		return ""
+	}
+	return `; paths must use "/" separators, not "\"`
+}
+
 func parentOf(path string) string {
 	idx := strings.LastIndex(path, "/")
 	if idx <= 0 {
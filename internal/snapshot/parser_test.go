@@ -2,9 +2,16 @@ package snapshot
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/adler32"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -44,6 +51,9 @@ func TestParseFileBuildsTree(t *testing.T) {
 	if b.Parent != a {
 		t.Fatal("expected /a/b parent /a")
 	}
+	if len(tree.Sessions) != 1 || tree.Sessions[42] != 30000 {
+		t.Fatalf("expected session 42 with a 30s timeout, got %v", tree.Sessions)
+	}
 	if len(tree.ACLs[1]) != 1 {
 		t.Fatalf("expected ACL ref 1 with one entry, got %d", len(tree.ACLs[1]))
 	}
@@ -52,6 +62,118 @@ func TestParseFileBuildsTree(t *testing.T) {
 	}
 }
 
+func TestParseBytesMatchesParseFile(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "snapshot.test")
+	if err := os.WriteFile(tmp, buildTestSnapshot(), 0o644); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+	fromFile, err := ParseFile(tmp)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	fromBytes, err := ParseBytes(buildTestSnapshot())
+	if err != nil {
+		t.Fatalf("ParseBytes() error = %v", err)
+	}
+	if fromBytes.Header != fromFile.Header {
+		t.Fatalf("header mismatch: %+v vs %+v", fromBytes.Header, fromFile.Header)
+	}
+	if len(fromBytes.NodesByPath) != len(fromFile.NodesByPath) {
+		t.Fatalf("expected %d nodes, got %d", len(fromFile.NodesByPath), len(fromBytes.NodesByPath))
+	}
+	for path, want := range fromFile.NodesByPath {
+		got, ok := fromBytes.NodesByPath[path]
+		if !ok {
+			t.Fatalf("missing node %q", path)
+		}
+		if !bytes.Equal(got.Data, want.Data) || got.Stat != want.Stat || got.ACLRef != want.ACLRef || got.DiskSize != want.DiskSize {
+			t.Fatalf("node %q differs: %+v vs %+v", path, got, want)
+		}
+	}
+	if len(fromBytes.ACLs) != len(fromFile.ACLs) {
+		t.Fatalf("expected %d ACL entries, got %d", len(fromFile.ACLs), len(fromBytes.ACLs))
+	}
+}
+
+func TestParseReaderWithUnknownTotal(t *testing.T) {
+	var reports [][2]int64
+	tree, err := ParseReaderWithProgress(bytes.NewReader(buildTestSnapshot()), 0, func(read, total int64) {
+		reports = append(reports, [2]int64{read, total})
+	})
+	if err != nil {
+		t.Fatalf("ParseReaderWithProgress() error = %v", err)
+	}
+	if tree.NodesByPath["/a/b"] == nil {
+		t.Fatal("expected /a/b parsed from the reader")
+	}
+	if len(reports) == 0 || reports[0] != [2]int64{0, 0} {
+		t.Fatalf("expected progress reported with an unknown total, got %v", reports)
+	}
+}
+
+func TestParseFileReadsGzippedSnapshot(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(buildTestSnapshot()); err != nil {
+		t.Fatalf("gzip snapshot: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip snapshot: %v", err)
+	}
+	tmp := filepath.Join(t.TempDir(), "snapshot.test.gz")
+	if err := os.WriteFile(tmp, compressed.Bytes(), 0o644); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+
+	var lastRead, lastTotal int64
+	tree, err := ParseFileWithProgress(tmp, func(read, total int64) {
+		lastRead, lastTotal = read, total
+	})
+	if err != nil {
+		t.Fatalf("ParseFileWithProgress() error = %v", err)
+	}
+	if tree.NodesByPath["/a/b"] == nil || string(tree.NodesByPath["/c"].Data) != "plain" {
+		t.Fatal("expected the gzipped snapshot's nodes")
+	}
+	if lastTotal != int64(compressed.Len()) || lastRead > lastTotal {
+		t.Fatalf("expected progress against the compressed size %d, got %d/%d", compressed.Len(), lastRead, lastTotal)
+	}
+}
+
+func TestParseFileRecordsDiskSize(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "snapshot.test")
+	if err := os.WriteFile(tmp, buildTestSnapshot(), 0o644); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+
+	tree, err := ParseFile(tmp)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	// Each record is a length-prefixed path, a length-prefixed buffer, an
+	// int64 ACL reference and a 60-byte stat.
+	const statSize = 60
+	tests := map[string]int64{
+		"":     4 + 0 + 4 + 8 + statSize,
+		"/a":   4 + 2 + 4 + 7 + 8 + statSize,
+		"/a/b": 4 + 4 + 4 + 5 + 8 + statSize,
+		"/c":   4 + 2 + 4 + 5 + 8 + statSize,
+	}
+	for path, want := range tests {
+		if got := tree.NodesByPath[path].DiskSize; got != want {
+			t.Fatalf("DiskSize(%q) = %d, want %d", path, got, want)
+		}
+	}
+
+	var records bytes.Buffer
+	writeNode(&records, "/a", []byte(`{"k":1}`), 1)
+	if got := tree.NodesByPath["/a"].DiskSize; got != int64(records.Len()) {
+		t.Fatalf("expected DiskSize to match serialized record length %d, got %d", records.Len(), got)
+	}
+}
+
 func TestParseFileRejectsBadMagic(t *testing.T) {
 	tmp := filepath.Join(t.TempDir(), "snapshot.bad")
 	b := buildTestSnapshot()
@@ -65,6 +187,251 @@ func TestParseFileRejectsBadMagic(t *testing.T) {
 	}
 }
 
+func TestParseReportsByteSwappedMagic(t *testing.T) {
+	b := buildTestSnapshot()
+	binary.LittleEndian.PutUint32(b[:4], snapshotMagic)
+
+	_, err := ParseBytes(b)
+	if err == nil || !strings.Contains(err.Error(), "byte-swapped (wrong endianness)") {
+		t.Fatalf("expected a byte-swap error, got %v", err)
+	}
+}
+
+func TestParseRejectsBackslashSeparatedPaths(t *testing.T) {
+	b := bytes.Replace(buildTestSnapshot(), []byte("/a/b"), []byte(`\a\b`), 1)
+
+	_, err := ParseBytes(b)
+	if err == nil || !strings.Contains(err.Error(), `paths must use "/" separators`) {
+		t.Fatalf("expected a separator error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `\\a\\b`) {
+		t.Fatalf("expected the offending path in the error, got %v", err)
+	}
+}
+
+func TestParseVerifiesChecksum(t *testing.T) {
+	b := buildTestSnapshot()
+	// The seal is the checksum followed by the string "/".
+	sealAt := len(b) - 8 - 5
+	binary.BigEndian.PutUint64(b[sealAt:], uint64(adler32.Checksum(b[:sealAt])))
+
+	opts := ParseOptions{VerifyChecksum: true}
+	if _, err := ParseReaderWithOptions(bytes.NewReader(b), 0, opts); err != nil {
+		t.Fatalf("expected a matching checksum to parse, got %v", err)
+	}
+
+	b[sealAt+7] ^= 0xff
+	if _, err := ParseReaderWithOptions(bytes.NewReader(b), 0, opts); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+	if _, err := ParseBytes(b); err != nil {
+		t.Fatalf("expected the checksum to be ignored by default, got %v", err)
+	}
+	if _, err := ParseReaderWithOptions(bytes.NewReader(b[:sealAt]), 0, opts); err == nil || !strings.Contains(err.Error(), "no seal") {
+		t.Fatalf("expected a missing seal to fail verification, got %v", err)
+	}
+}
+
+func TestParseReturnsPartialTreeWhenTruncated(t *testing.T) {
+	full := buildTestSnapshot()
+	// Cut the snapshot in the middle of the /a/b record.
+	cut := bytes.Index(full, []byte("/a/b")) + 10
+
+	tree, err := ParseBytes(full[:cut])
+	if !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected ErrTruncated, got %v", err)
+	}
+	if tree == nil || tree.NodesByPath["/a"] == nil {
+		t.Fatal("expected the nodes before the cut")
+	}
+	if tree.NodesByPath["/a/b"] != nil || tree.NodesByPath["/c"] != nil {
+		t.Fatal("expected no nodes from the cut record on")
+	}
+	if tree.Sessions[42] != 30000 || len(tree.Warnings) != 1 {
+		t.Fatalf("expected sessions and a truncation warning, got %v %v", tree.Sessions, tree.Warnings)
+	}
+
+	if tree, err := ParseBytes(full[:20]); tree != nil || err == nil {
+		t.Fatal("expected no tree when the snapshot ends before the nodes")
+	}
+}
+
+func TestParseFileContextStopsWhenCancelled(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "snapshot.test")
+	if err := os.WriteFile(tmp, buildTestSnapshot(), 0o644); err != nil {
+		t.Fatalf("write test snapshot: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if tree, err := ParseFileContext(ctx, tmp, nil); tree != nil || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestParseStopsMidNodesWhenCancelled(t *testing.T) {
+	var b bytes.Buffer
+	writeI32(&b, snapshotMagic)
+	writeI32(&b, 2)
+	writeI64(&b, -1)
+	writeI32(&b, 0) // sessions
+	writeI32(&b, 0) // ACLs
+	writeNode(&b, "", nil, -1)
+	for i := range 3 * cancelCheckInterval {
+		writeNode(&b, fmt.Sprintf("/n%d", i), nil, -1)
+	}
+	writeString(&b, "/")
+
+	// Cancel once parsing has started.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := &cancelingReader{r: &b, cancel: cancel}
+	_, err := ParseReaderWithOptions(r, 0, ParseOptions{Context: ctx})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+// cancelingReader calls cancel on its first read.
+type cancelingReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+}
+
+func (c *cancelingReader) Read(p []byte) (int, error) {
+	c.cancel()
+	return c.r.Read(p)
+}
+
+func TestParseHonorsLengthLimits(t *testing.T) {
+	// buildTestSnapshot's largest node data is 7 bytes and its longest
+	// string 6.
+	if _, err := ParseReaderWithOptions(bytes.NewReader(buildTestSnapshot()), 0, ParseOptions{MaxBufferLen: 7, MaxStringLen: 6}); err != nil {
+		t.Fatalf("expected limits at the largest lengths to pass, got %v", err)
+	}
+	_, err := ParseReaderWithOptions(bytes.NewReader(buildTestSnapshot()), 0, ParseOptions{MaxBufferLen: 6})
+	if err == nil || !strings.Contains(err.Error(), "buffer length 7 exceeds limit 6") {
+		t.Fatalf("expected buffer limit error, got %v", err)
+	}
+	_, err = ParseReaderWithOptions(bytes.NewReader(buildTestSnapshot()), 0, ParseOptions{MaxStringLen: 5})
+	if err == nil || !strings.Contains(err.Error(), "string length 6 exceeds limit 5") {
+		t.Fatalf("expected string limit error, got %v", err)
+	}
+}
+
+func TestParseRejectsDuplicatePaths(t *testing.T) {
+	var b bytes.Buffer
+	writeI32(&b, snapshotMagic)
+	writeI32(&b, 2)
+	writeI64(&b, -1)
+	writeI32(&b, 0) // sessions
+	writeI32(&b, 0) // ACLs
+	writeNode(&b, "", nil, -1)
+	writeNode(&b, "/a", nil, -1)
+	writeNode(&b, "/a/b", nil, -1)
+	offset := b.Len()
+	writeNode(&b, "/a", nil, -1)
+	writeString(&b, "/")
+
+	_, err := ParseBytes(b.Bytes())
+	want := fmt.Sprintf("duplicate path \"/a\" at offset %d", offset)
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected %q, got %v", want, err)
+	}
+}
+
+func TestParseFileRejectsDirectory(t *testing.T) {
+	_, err := ParseFile(t.TempDir())
+	if err == nil {
+		t.Fatal("expected parse error for a directory")
+	}
+	if !strings.Contains(err.Error(), "is a directory, expected a snapshot file") {
+		t.Fatalf("expected directory error, got: %v", err)
+	}
+}
+
+func TestParseFileToleratesTrailingBytesAfterSeal(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "snapshot.padded")
+	b := append(buildTestSnapshot(), 0, 0, 0, 0, 'x', 'y')
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+
+	tree, err := ParseFile(tmp)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if tree.NodesByPath["/c"] == nil {
+		t.Fatal("expected /c node")
+	}
+	if len(tree.Warnings) != 0 {
+		t.Fatalf("expected no warnings without verification, got %v", tree.Warnings)
+	}
+
+	tree, err = ParseFileWithOptions(tmp, ParseOptions{VerifyTrailer: true})
+	if err != nil {
+		t.Fatalf("ParseFileWithOptions() error = %v", err)
+	}
+	if len(tree.Warnings) != 1 || !strings.Contains(tree.Warnings[0], "6 trailing bytes") || !strings.Contains(tree.Warnings[0], "2 non-zero") {
+		t.Fatalf("expected trailing-bytes warning, got %v", tree.Warnings)
+	}
+}
+
+func TestParseFileIgnoresZeroPaddingAfterSeal(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "snapshot.zeros")
+	b := append(buildTestSnapshot(), make([]byte, 4096)...)
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+
+	tree, err := ParseFileWithOptions(tmp, ParseOptions{VerifyTrailer: true})
+	if err != nil {
+		t.Fatalf("ParseFileWithOptions() error = %v", err)
+	}
+	if len(tree.Warnings) != 0 {
+		t.Fatalf("expected zero padding to be accepted silently, got %v", tree.Warnings)
+	}
+}
+
+func TestParseFileSkipsZxidDigestAfterSeal(t *testing.T) {
+	var b bytes.Buffer
+	b.Write(buildTestSnapshot())
+	// ZooKeeper 3.6+: zxid, digest version and digest, then a seal.
+	writeI64(&b, 0x100000005)
+	writeI32(&b, 2)
+	writeI64(&b, 0x1234abcd)
+	writeI64(&b, 0x5678)
+	writeString(&b, "/")
+	// ZooKeeper 3.7+: the last processed zxid, then a seal.
+	writeI64(&b, 0x100000005)
+	writeI64(&b, 0x9abc)
+	writeString(&b, "/")
+
+	tmp := filepath.Join(t.TempDir(), "snapshot.36")
+	if err := os.WriteFile(tmp, b.Bytes(), 0o644); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+	tree, err := ParseFileWithOptions(tmp, ParseOptions{VerifyTrailer: true})
+	if err != nil {
+		t.Fatalf("ParseFileWithOptions() error = %v", err)
+	}
+	if tree.NodesByPath["/c"] == nil || len(tree.Warnings) != 0 {
+		t.Fatalf("expected the digest trailer to be accepted silently, got %v", tree.Warnings)
+	}
+
+	b.WriteString("xy")
+	if err := os.WriteFile(tmp, b.Bytes(), 0o644); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+	tree, err = ParseFileWithOptions(tmp, ParseOptions{VerifyTrailer: true})
+	if err != nil {
+		t.Fatalf("ParseFileWithOptions() error = %v", err)
+	}
+	want := fmt.Sprintf("2 trailing bytes after seal at offset %d (2 non-zero)", b.Len()-2)
+	if len(tree.Warnings) != 1 || tree.Warnings[0] != want {
+		t.Fatalf("expected %q, got %v", want, tree.Warnings)
+	}
+}
+
 func buildTestSnapshot() []byte {
 	var b bytes.Buffer
 
@@ -139,3 +506,24 @@ func writeI32(b *bytes.Buffer, v int32) {
 func writeI64(b *bytes.Buffer, v int64) {
 	_ = binary.Write(b, binary.BigEndian, v)
 }
+
+func TestInspectHeader(t *testing.T) {
+	b := buildTestSnapshot()
+	// Only the header is present; the rest of the snapshot is not needed.
+	header, err := InspectHeader(bytes.NewReader(b[:16]))
+	if err != nil {
+		t.Fatalf("inspect header: %v", err)
+	}
+	tree, err := ParseBytes(b)
+	if err != nil {
+		t.Fatalf("parse snapshot: %v", err)
+	}
+	if header != tree.Header {
+		t.Fatalf("expected header %+v, got %+v", tree.Header, header)
+	}
+
+	b[0] = 0x00
+	if _, err := InspectHeader(bytes.NewReader(b)); err == nil {
+		t.Fatal("expected error for bad magic")
+	}
+}
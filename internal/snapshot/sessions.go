@@ -0,0 +1,11 @@
+package snapshot
+
+// SessionTimeout returns the timeout in milliseconds of the session with the
+// given ID, and whether the session was open at snapshot time.
+func (t *Tree) SessionTimeout(owner int64) (int32, bool) {
+	if t == nil {
+		return 0, false
+	}
+	timeout, ok := t.Sessions[owner]
+	return timeout, ok
+}
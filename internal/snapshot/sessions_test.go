@@ -0,0 +1,20 @@
+package snapshot
+
+import "testing"
+
+func TestSessionTimeout(t *testing.T) {
+	tree, err := ParseBytes(buildTestSnapshot())
+	if err != nil {
+		t.Fatalf("parse snapshot: %v", err)
+	}
+	if timeout, ok := tree.SessionTimeout(42); !ok || timeout != 30000 {
+		t.Fatalf("expected session 42 with 30000ms, got %d %v", timeout, ok)
+	}
+	if _, ok := tree.SessionTimeout(7); ok {
+		t.Fatal("expected unknown session 7")
+	}
+	var none *Tree
+	if _, ok := none.SessionTimeout(42); ok {
+		t.Fatal("expected no sessions on a nil tree")
+	}
+}
@@ -0,0 +1,113 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+)
+
+// spillFile is a temp file holding node data that was moved out of memory
+// while parsing.
+type spillFile struct {
+	f    *os.File
+	size int64
+}
+
+// spiller moves the data of large nodes to a spill file, creating the file
+// on first use.
+type spiller struct {
+	dir       string
+	threshold int
+	file      *spillFile
+}
+
+// spilledData locates a node's data in a spill file.
+type spilledData struct {
+	file   *spillFile
+	offset int64
+	length int
+}
+
+// maybeSpill stores data in node, or in the spill file when it is larger than
+// the threshold.
+func (s *spiller) maybeSpill(node *Node, data []byte) error {
+	if s == nil || s.threshold <= 0 || len(data) <= s.threshold {
+		node.Data = data
+		return nil
+	}
+	if s.file == nil {
+		f, err := os.CreateTemp(s.dir, "zooxplorer-spill-*")
+		if err != nil {
+			return fmt.Errorf("create spill file: %w", err)
+		}
+		s.file = &spillFile{f: f}
+	}
+	n, err := s.file.f.Write(data)
+	if err != nil {
+		return fmt.Errorf("write spill file: %w", err)
+	}
+	node.spilled = &spilledData{file: s.file, offset: s.file.size, length: n}
+	node.contentHash = hashData(data)
+	s.file.size += int64(n)
+	return nil
+}
+
+func (s *spiller) remove() error {
+	if s == nil || s.file == nil {
+		return nil
+	}
+	err := s.file.remove()
+	s.file = nil
+	return err
+}
+
+func (f *spillFile) remove() error {
+	closeErr := f.f.Close()
+	if err := os.Remove(f.f.Name()); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func (d *spilledData) load() ([]byte, error) {
+	buf := make([]byte, d.length)
+	if _, err := d.file.f.ReadAt(buf, d.offset); err != nil {
+		return nil, fmt.Errorf("read spill file: %w", err)
+	}
+	return buf, nil
+}
+
+// Bytes returns the node's data, reading it back from the spill file when it
+// was moved out of memory while parsing. Spilled data that can no longer be
+// read, e.g. after the tree was closed, is returned as nil.
+func (n *Node) Bytes() []byte {
+	if n.spilled == nil {
+		return n.Data
+	}
+	data, err := n.spilled.load()
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// DataLen returns the length of the node's data without loading spilled data.
+func (n *Node) DataLen() int {
+	if n.spilled != nil {
+		return n.spilled.length
+	}
+	return len(n.Data)
+}
+
+// Spilled reports whether the node's data was moved to the spill file.
+func (n *Node) Spilled() bool {
+	return n.spilled != nil
+}
+
+// Close removes the tree's spill file, if any. Spilled node data cannot be
+// read afterwards.
+func (t *Tree) Close() error {
+	if t == nil {
+		return nil
+	}
+	return t.spill.remove()
+}
@@ -0,0 +1,71 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFileSpillsLargeNodeData(t *testing.T) {
+	dir := t.TempDir()
+	tmp := filepath.Join(dir, "snapshot.test")
+	if err := os.WriteFile(tmp, buildTestSnapshot(), 0o644); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+	spillDir := t.TempDir()
+
+	tree, err := ParseFileWithOptions(tmp, ParseOptions{SpillThreshold: 6, SpillDir: spillDir})
+	if err != nil {
+		t.Fatalf("ParseFileWithOptions() error = %v", err)
+	}
+
+	a := tree.NodesByPath["/a"]
+	if !a.Spilled() || a.Data != nil {
+		t.Fatal("expected /a data to be spilled out of memory")
+	}
+	if a.DataLen() != 7 {
+		t.Fatalf("expected spilled length 7, got %d", a.DataLen())
+	}
+	if got := string(a.Bytes()); got != `{"k":1}` {
+		t.Fatalf("expected spilled data read back, got %q", got)
+	}
+	if a.ContentHash() != hashData([]byte(`{"k":1}`)) {
+		t.Fatal("expected content hash of the spilled data")
+	}
+	c := tree.NodesByPath["/c"]
+	if c.Spilled() || string(c.Bytes()) != "plain" {
+		t.Fatal("expected small node data to stay in memory")
+	}
+
+	files, err := os.ReadDir(spillDir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one spill file, got %d (%v)", len(files), err)
+	}
+	if err := tree.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if files, _ := os.ReadDir(spillDir); len(files) != 0 {
+		t.Fatal("expected Close to remove the spill file")
+	}
+	if a.Bytes() != nil {
+		t.Fatal("expected no data after the tree was closed")
+	}
+}
+
+func TestParseFileWithoutThresholdKeepsDataInMemory(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "snapshot.test")
+	if err := os.WriteFile(tmp, buildTestSnapshot(), 0o644); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+
+	tree, err := ParseFile(tmp)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	defer tree.Close()
+	for path, node := range tree.NodesByPath {
+		if node.Spilled() {
+			t.Fatalf("expected %q to stay in memory", path)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package snapshot
+
+// SubtreeSize returns the number of data bytes held by the node and all of
+// its descendants. It is computed on first use and cached on every node of
+// the subtree; parsing computes it for the whole tree.
+func (n *Node) SubtreeSize() int {
+	n.sizeSubtree()
+	return n.subtreeSize
+}
+
+// DescendantCount returns the number of nodes below the node. It is cached
+// along with SubtreeSize.
+func (n *Node) DescendantCount() int {
+	n.sizeSubtree()
+	return n.descendants
+}
+
+func (n *Node) sizeSubtree() {
+	if n.subtreeSized {
+		return
+	}
+	n.subtreeSize = n.DataLen()
+	n.descendants = len(n.Children)
+	for _, child := range n.Children {
+		child.sizeSubtree()
+		n.subtreeSize += child.subtreeSize
+		n.descendants += child.descendants
+	}
+	n.subtreeSized = true
+}
@@ -0,0 +1,29 @@
+package snapshot
+
+import "testing"
+
+func TestSubtreeSizeSumsDescendantData(t *testing.T) {
+	tree, err := ParseBytes(buildTestSnapshot())
+	if err != nil {
+		t.Fatalf("ParseBytes() error = %v", err)
+	}
+	for path, want := range map[string]int{"": 17, "/a": 12, "/a/b": 5, "/c": 5} {
+		if got := tree.NodesByPath[path].SubtreeSize(); got != want {
+			t.Fatalf("expected %d bytes below %q, got %d", want, path, got)
+		}
+	}
+	for path, want := range map[string]int{"": 3, "/a": 1, "/a/b": 0, "/c": 0} {
+		if got := tree.NodesByPath[path].DescendantCount(); got != want {
+			t.Fatalf("expected %d nodes below %q, got %d", want, path, got)
+		}
+	}
+	if !tree.NodesByPath["/a/b"].subtreeSized {
+		t.Fatal("expected parsing to compute subtree sizes")
+	}
+
+	leaf := &Node{Data: []byte("abc")}
+	parent := &Node{Children: []*Node{leaf, {}}}
+	if got := parent.SubtreeSize(); got != 3 || !leaf.subtreeSized {
+		t.Fatalf("expected a lazily computed 3 bytes, got %d", got)
+	}
+}
@@ -0,0 +1,76 @@
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Validate checks the tree's links for consistency: every node's Parent
+// matches its path, Children lists no node twice and points back at its
+// parent, and NodesByPath holds exactly the nodes reachable from the root.
+// Parsed trees pass; an error points at a parser bug or a tree modified
+// after parsing.
+func (t *Tree) Validate() []error {
+	if t == nil || t.Root == nil {
+		return []error{fmt.Errorf("tree has no root")}
+	}
+	var errs []error
+	if t.Root.Parent != nil {
+		errs = append(errs, fmt.Errorf("/: root has parent %s", displayPath(t.Root.Parent.Path)))
+	}
+
+	reachable := make(map[*Node]bool)
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		reachable[n] = true
+		seen := make(map[string]bool, len(n.Children))
+		for _, child := range n.Children {
+			path := displayPath(child.Path)
+			if seen[child.Path] {
+				errs = append(errs, fmt.Errorf("%s: listed twice among the children of %s", path, displayPath(n.Path)))
+				continue
+			}
+			seen[child.Path] = true
+			if child.Parent != n {
+				errs = append(errs, fmt.Errorf("%s: child of %s but its parent link points elsewhere", path, displayPath(n.Path)))
+			}
+			if parentOf(child.Path) != n.Path {
+				errs = append(errs, fmt.Errorf("%s: path is not below its parent %s", path, displayPath(n.Path)))
+			}
+			if reachable[child] {
+				errs = append(errs, fmt.Errorf("%s: reachable through more than one parent", path))
+				continue
+			}
+			walk(child)
+		}
+	}
+	walk(t.Root)
+
+	for n := range reachable {
+		if t.NodesByPath[n.Path] != n {
+			errs = append(errs, fmt.Errorf("%s: missing from NodesByPath", displayPath(n.Path)))
+		}
+	}
+	for path, n := range t.NodesByPath {
+		if path == "/" && n == t.Root {
+			continue
+		}
+		if !reachable[n] {
+			errs = append(errs, fmt.Errorf("%s: in NodesByPath but not reachable from the root", displayPath(path)))
+		} else if n.Path != path {
+			errs = append(errs, fmt.Errorf("%s: NodesByPath maps it to %s", displayPath(path), displayPath(n.Path)))
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+	return errs
+}
+
+// displayPath shows the root's empty path as "/".
+func displayPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
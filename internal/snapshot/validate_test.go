@@ -0,0 +1,43 @@
+package snapshot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateAcceptsParsedTree(t *testing.T) {
+	tree, err := ParseBytes(buildTestSnapshot())
+	if err != nil {
+		t.Fatalf("ParseBytes() error = %v", err)
+	}
+	if errs := tree.Validate(); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidateReportsBrokenLinks(t *testing.T) {
+	tree, err := ParseBytes(buildTestSnapshot())
+	if err != nil {
+		t.Fatalf("ParseBytes() error = %v", err)
+	}
+	a := tree.NodesByPath["/a"]
+	b := tree.NodesByPath["/a/b"]
+	c := tree.NodesByPath["/c"]
+	a.Children = append(a.Children, b)
+	c.Parent = a
+	orphan := &Node{ID: "x", Path: "/x"}
+	tree.NodesByPath["/x"] = orphan
+
+	var got []string
+	for _, err := range tree.Validate() {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		"/a/b: listed twice among the children of /a",
+		"/c: child of / but its parent link points elsewhere",
+		"/x: in NodesByPath but not reachable from the root",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,32 @@
+package snapshot
+
+import "io"
+
+// Walk decodes the snapshot in r and calls fn for every node in the order
+// they are stored, which is pre-order, without building a Tree. The root
+// node has the empty path. Walk stops at the first error fn returns and
+// returns it.
+func Walk(r io.Reader, fn func(path string, data []byte, acl int64, stat StatPersisted) error) error {
+	d := newDecoder(r, nil)
+	if _, err := parseHeader(d); err != nil {
+		return err
+	}
+	if _, err := parseSessions(d); err != nil {
+		return err
+	}
+	if _, err := parseACLCache(d, defaultLimits); err != nil {
+		return err
+	}
+	for {
+		rec, done, err := readNodeRecord(d, defaultLimits)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if err := fn(rec.path, rec.data, rec.aclRef, rec.stat); err != nil {
+			return err
+		}
+	}
+}
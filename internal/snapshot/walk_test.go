@@ -0,0 +1,44 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWalkVisitsNodesInPreOrder(t *testing.T) {
+	var got []string
+	err := Walk(bytes.NewReader(buildTestSnapshot()), func(path string, data []byte, acl int64, stat StatPersisted) error {
+		got = append(got, path+"="+string(data))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	if want := `=|/a={"k":1}|/a/b=child|/c=plain`; strings.Join(got, "|") != want {
+		t.Fatalf("expected %q, got %q", want, strings.Join(got, "|"))
+	}
+}
+
+func TestWalkStopsAtCallbackError(t *testing.T) {
+	stop := errors.New("stop")
+	var visited int
+	err := Walk(bytes.NewReader(buildTestSnapshot()), func(path string, data []byte, acl int64, stat StatPersisted) error {
+		visited++
+		if path == "/a" {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || visited != 2 {
+		t.Fatalf("expected to stop after /a, got %v after %d nodes", err, visited)
+	}
+}
+
+func TestWalkRejectsBadHeader(t *testing.T) {
+	err := Walk(bytes.NewReader([]byte{0, 0, 0, 0}), func(string, []byte, int64, StatPersisted) error { return nil })
+	if err == nil {
+		t.Fatal("expected an error for a bad header")
+	}
+}
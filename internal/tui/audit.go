@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// maxAuditLines bounds the findings listed in the audit dialog.
+const maxAuditLines = 20
+
+func (m Model) auditText() string {
+	lines := []string{"Audit Report", ""}
+	var findings []string
+	if m.tree != nil {
+		findings = m.tree.Warnings
+	}
+	if len(findings) == 0 && len(m.violations) == 0 && m.baselineDiff == nil {
+		lines = append(lines, "No findings.")
+	}
+	for i, finding := range findings {
+		if i == maxAuditLines {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(findings)-maxAuditLines))
+			break
+		}
+		lines = append(lines, "- "+finding)
+	}
+	if len(m.violations) > 0 {
+		lines = append(lines, "", fmt.Sprintf("Policy violations: %d", len(m.violations)))
+		for i, v := range m.violations {
+			if i == maxAuditLines {
+				lines = append(lines, fmt.Sprintf("... and %d more", len(m.violations)-maxAuditLines))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("- %s: %s (%s)", printablePath(v.Node.Path), v.Reason, v.Rule))
+		}
+	}
+	if d := m.baselineDiff; d != nil {
+		lines = append(lines, "", fmt.Sprintf("Baseline: %d added, %d changed, %d removed", len(d.Added), len(d.Changed), len(d.Removed)))
+		for i, path := range d.Removed {
+			if i == maxAuditLines {
+				lines = append(lines, fmt.Sprintf("... and %d more", len(d.Removed)-maxAuditLines))
+				break
+			}
+			lines = append(lines, "- removed "+printablePath(path))
+		}
+	}
+	lines = append(lines, "", "Press any key to close.")
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) renderAuditDialog() string {
+	lines := strings.Split(m.auditText(), "\n")
+	for i := range lines {
+		lines[i] = truncate(lines[i], m.screenWidth()-8)
+	}
+	lines[0] = statsLabelStyle.Render(lines[0])
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("214")).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+}
+
+// setBaseline marks the nodes that differ from the baseline in the tree.
+func (m *Model) setBaseline(diff snapshot.BaselineDiff) {
+	m.baselineDiff = &diff
+	m.baselineMarks = make(map[*snapshot.Node]string, len(diff.Added)+len(diff.Changed))
+	for _, node := range diff.Added {
+		m.baselineMarks[node] = markerAdded
+	}
+	for _, node := range diff.Changed {
+		m.baselineMarks[node] = markerChanged
+	}
+	m.rowsGeneration++
+}
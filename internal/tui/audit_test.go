@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/policy"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+func TestAuditDialogListsTreeWarnings(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.Warnings = []string{`ACL 2 uses unknown scheme "bogus" (1 nodes, e.g. /a)`}
+	var model tea.Model = NewModel(tree)
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")})
+	typed := model.(Model)
+	if !typed.auditOpen {
+		t.Fatal("expected audit dialog open")
+	}
+	if !strings.Contains(typed.auditText(), `- ACL 2 uses unknown scheme "bogus"`) {
+		t.Fatalf("expected warning in audit report, got: %q", typed.auditText())
+	}
+
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyDown})
+	typed = model.(Model)
+	if typed.auditOpen || typed.selected.Path != "/a" {
+		t.Fatal("expected any key to close the dialog without navigating")
+	}
+}
+
+func TestAuditDialogUsesTheScreenWidth(t *testing.T) {
+	tree := sampleSnapshotTree()
+	warning := `ACL 2 uses unknown scheme "bogus" (1 nodes, e.g. /` + strings.Repeat("deeply/nested/", 6) + `node)`
+	tree.Warnings = []string{warning}
+	var model tea.Model = NewModel(tree)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")})
+	if view := model.(Model).renderAuditDialog(); !strings.Contains(view, warning) {
+		t.Fatalf("expected the full %d-character warning in the dialog, got:\n%s", len(warning), view)
+	}
+}
+
+func TestAuditDialogWithoutFindings(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	if !strings.Contains(m.auditText(), "No findings.") {
+		t.Fatalf("expected empty report, got: %q", m.auditText())
+	}
+}
+
+func TestPolicyViolationsMarkedAndListed(t *testing.T) {
+	p, err := policy.Parse([]byte(`{"rules": [{"path": "/b", "require": [{"scheme": "digest"}]}]}`))
+	if err != nil {
+		t.Fatalf("policy.Parse() error = %v", err)
+	}
+	m := NewModelWithOptions(sampleSnapshotTree(), Options{Policy: p})
+
+	lines := renderTreeWindow(m.rows, m.selected, 120, m.expanded, m.sortOrder, false,
+		treeDisplay{times: m.times, violating: m.violating}, nil, "", 0, len(m.rows)+1)
+	if got := stripANSI(lines[2]); !strings.HasPrefix(got, " "+markerViolation) || !strings.Contains(got, "b") {
+		t.Fatalf("expected /b marked as violating, got %q", got)
+	}
+	if got := stripANSI(lines[1]); strings.Contains(got[:2], markerViolation) {
+		t.Fatalf("expected /a unmarked, got %q", got)
+	}
+	if !strings.Contains(m.auditText(), "Policy violations: 1\n- /b: missing digest:* (/b)") {
+		t.Fatalf("expected violation in audit report, got: %q", m.auditText())
+	}
+}
+
+func TestBaselineDifferencesMarkedAndListed(t *testing.T) {
+	baseline := sampleSnapshotTree().Baseline()
+	delete(baseline.Nodes, "/b")
+	baseline.Nodes["/gone"] = snapshot.BaselineEntry{}
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a"].Data = []byte("changed")
+	m := NewModelWithOptions(tree, Options{Baseline: baseline})
+
+	lines := renderTreeWindow(m.rows, nil, 120, m.expanded, m.sortOrder, false, m.treeDisplay(), nil, "", 0, len(m.rows)+1)
+	var marks []string
+	for _, line := range lines[1:] {
+		marks = append(marks, stripANSI(line)[:2])
+	}
+	if strings.Join(marks, "|") != " "+markerChanged+"| "+markerAdded {
+		t.Fatalf("expected /a changed and /b added, got %q", marks)
+	}
+	if !strings.Contains(m.auditText(), "Baseline: 1 added, 1 changed, 1 removed\n- removed /gone") {
+		t.Fatalf("expected baseline summary in audit report, got: %q", m.auditText())
+	}
+}
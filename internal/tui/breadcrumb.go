@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// breadcrumbSeparator separates the segments of the breadcrumb.
+const breadcrumbSeparator = " › "
+
+var breadcrumbStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+// renderBreadcrumb shows the ancestry of the selected node, e.g.
+// "/ › services › foo › leader". When that is wider than width, the middle
+// segments give way to "…" so that the root and the nearest ancestors stay.
+func (m Model) renderBreadcrumb(width int) string {
+	if m.selected == nil || width < 1 {
+		return ""
+	}
+	var segments []string
+	for node := m.selected; node != nil; node = node.Parent {
+		if node.Parent == nil {
+			segments = append(segments, "/")
+		} else {
+			segments = append(segments, node.ID)
+		}
+	}
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+	return breadcrumbStyle.Render(fitBreadcrumb(segments, width))
+}
+
+// fitBreadcrumb joins segments, leaving out as few middle segments as needed
+// to fit width. The last segment alone is shortened from the left.
+func fitBreadcrumb(segments []string, width int) string {
+	line := strings.Join(segments, breadcrumbSeparator)
+	if lipgloss.Width(line) <= width || len(segments) < 2 {
+		return truncateLeft(line, width)
+	}
+	last := len(segments) - 1
+	for keep := last - 1; keep >= 1; keep-- {
+		parts := append([]string{segments[0], "…"}, segments[len(segments)-keep:]...)
+		if line := strings.Join(parts, breadcrumbSeparator); lipgloss.Width(line) <= width {
+			return line
+		}
+	}
+	return truncateLeft(segments[last], width)
+}
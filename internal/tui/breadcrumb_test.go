@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+func TestBreadcrumbShowsAncestry(t *testing.T) {
+	tree := sampleSnapshotTree()
+	m := NewModel(tree)
+	m.selectNode(tree.NodesByPath["/a/a1"])
+	if got := stripANSI(m.renderBreadcrumb(80)); got != "/ › a › a1" {
+		t.Fatalf("unexpected breadcrumb %q", got)
+	}
+
+	m.selected = tree.Root
+	if got := stripANSI(m.renderBreadcrumb(80)); got != "/" {
+		t.Fatalf("expected the root alone, got %q", got)
+	}
+}
+
+func TestBreadcrumbEllipsizesMiddleSegments(t *testing.T) {
+	segments := []string{"/", "services", "payments", "foo", "leader"}
+	tests := map[int]string{
+		40: "/ › services › payments › foo › leader",
+		31: "/ › … › payments › foo › leader",
+		20: "/ › … › foo › leader",
+		14: "/ › … › leader",
+		8:  "leader",
+		4:  "…der",
+	}
+	for width, want := range tests {
+		got := fitBreadcrumb(segments, width)
+		if got != want {
+			t.Fatalf("fitBreadcrumb(%d) = %q, want %q", width, got, want)
+		}
+		if lipgloss.Width(got) > width {
+			t.Fatalf("fitBreadcrumb(%d) = %q is too wide", width, got)
+		}
+	}
+}
+
+func TestViewShowsBreadcrumbAboveTree(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	a := &snapshot.Node{ID: "a", Path: "/a", Parent: root}
+	root.Children = []*snapshot.Node{a}
+	var model tea.Model = NewModel(&snapshot.Tree{Root: root, NodesByPath: map[string]*snapshot.Node{"/a": a}})
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	lines := strings.Split(stripANSI(model.View()), "\n")
+	if !strings.Contains(lines[1], "/ › a") || !strings.Contains(lines[2], "Node name") {
+		t.Fatalf("expected the breadcrumb above the table header, got %q and %q", lines[1], lines[2])
+	}
+}
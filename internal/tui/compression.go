@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jowiho/zooxplorer/internal/format"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// compressionInfo is what decompressing a node's data found out.
+type compressionInfo struct {
+	algo         string
+	uncompressed int
+	ok           bool
+}
+
+// ratio is how many times larger the data is uncompressed.
+func (c compressionInfo) ratio(compressed int) float64 {
+	if compressed == 0 {
+		return 0
+	}
+	return float64(c.uncompressed) / float64(compressed)
+}
+
+// compressionCache remembers which nodes hold compressed data, so that
+// drawing the tree decompresses every node at most once. It is shared by
+// all copies of the model.
+type compressionCache struct {
+	mu      sync.Mutex
+	entries map[*snapshot.Node]compressionInfo
+}
+
+func newCompressionCache() *compressionCache {
+	return &compressionCache{entries: make(map[*snapshot.Node]compressionInfo)}
+}
+
+// info detects the compression of node's data, caching the result. A nil
+// cache detects it every time.
+func (c *compressionCache) info(node *snapshot.Node) compressionInfo {
+	if c != nil {
+		c.mu.Lock()
+		info, ok := c.entries[node]
+		c.mu.Unlock()
+		if ok {
+			return info
+		}
+	}
+	var info compressionInfo
+	if node.DataLen() > 0 {
+		info.algo, info.uncompressed, info.ok = format.Compression(node.Bytes())
+	}
+	if c != nil {
+		c.mu.Lock()
+		c.entries[node] = info
+		c.mu.Unlock()
+	}
+	return info
+}
+
+// sizeSummary is format.DataSizeSummary for node's data, from the cached
+// compression info rather than decompressing the data again.
+func (c *compressionCache) sizeSummary(node *snapshot.Node) string {
+	info := c.info(node)
+	if !info.ok {
+		return fmt.Sprintf("Size: %d bytes", node.DataLen())
+	}
+	return fmt.Sprintf("Size: %d bytes (compressed), %d bytes (uncompressed)", node.DataLen(), info.uncompressed)
+}
+
+// compressionSummary describes the compression of node's data for the
+// metadata pane, e.g. "gzip 4.2x"; empty for uncompressed data.
+func (c *compressionCache) compressionSummary(node *snapshot.Node) string {
+	info := c.info(node)
+	if !info.ok {
+		return ""
+	}
+	return fmt.Sprintf("%s %.1fx", info.algo, info.ratio(node.DataLen()))
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jowiho/zooxplorer/internal/format"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+func TestCompressedNodesAreMarked(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: "/"}
+	packed := &snapshot.Node{ID: "packed", Path: "/packed", Parent: root, Data: gzipped(t, strings.Repeat("abc", 100))}
+	plain := &snapshot.Node{ID: "plain", Path: "/plain", Parent: root, Data: []byte("abc")}
+	root.Children = []*snapshot.Node{packed, plain}
+	rows := flatten(root, map[string]bool{}, sortByNodeName, false)
+
+	display := defaultTreeDisplay
+	display.compression = newCompressionCache()
+	lines := renderTreeWindow(rows, nil, 120, map[string]bool{}, sortByNodeName, false, display, nil, "", 0, 3)
+	if !strings.Contains(stripANSI(lines[1]), markerCompressed+" ") {
+		t.Fatalf("expected the compressed marker, got %q", stripANSI(lines[1]))
+	}
+	if strings.Contains(stripANSI(lines[2]), markerCompressed+" ") {
+		t.Fatalf("expected no marker on plain data, got %q", stripANSI(lines[2]))
+	}
+	if _, ok := display.compression.entries[packed]; !ok {
+		t.Fatal("expected the detection to be cached")
+	}
+}
+
+func TestCompressedSizeLabelFitsTheColumn(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: "/"}
+	packed := &snapshot.Node{ID: "packed", Path: "/packed", Parent: root, Data: gzipped(t, strings.Repeat("abc", 100))}
+	root.Children = []*snapshot.Node{packed}
+	rows := flatten(root, map[string]bool{}, sortByNodeName, false)
+
+	display := defaultTreeDisplay
+	display.compression = newCompressionCache()
+	display.sizeWarning = 1
+	display.sizeUnit = unitKB
+	lines := renderTreeWindow(rows, nil, 80, map[string]bool{}, sortByNodeName, false, display, nil, "", 0, 2)
+	header, line := stripANSI(lines[0]), stripANSI(lines[1])
+	if !strings.Contains(line, markerOversized+" "+markerCompressed+" ") || len([]rune(line)) != 80 {
+		t.Fatalf("expected an 80-cell row with both markers, got %q", line)
+	}
+	nameW, nodeW, _, _, _, _ := tableColumnWidths(80)
+	headerCells, rowCells := []rune(header), []rune(line)
+	end := nameW + 1 + nodeW
+	if string(headerCells[end-len("Node size"):end]) != "Node size" || rowCells[end-1] == ' ' || rowCells[end] != ' ' {
+		t.Fatalf("expected the size cell to end under its header:\n%s\n%s", header, line)
+	}
+
+	_, nodeW, _, _, _, _ = tableColumnWidths(120)
+	for _, unit := range []sizeUnit{unitBytes, unitKB, unitMB} {
+		if got := sizeLabel([]string{markerOversized, markerCompressed}, 2<<30, unit, nodeW); len(got) > nodeW {
+			t.Fatalf("expected at most %d cells in %v, got %q", nodeW, unit, got)
+		}
+	}
+}
+
+func TestCompressionCacheReusesDetection(t *testing.T) {
+	node := &snapshot.Node{Data: gzipped(t, "hello")}
+	c := newCompressionCache()
+	first := c.info(node)
+	node.Data = []byte("replaced")
+	if got := c.info(node); got != first || !got.ok {
+		t.Fatalf("expected the cached detection, got %+v", got)
+	}
+}
+
+func TestMetadataShowsCompressionRatio(t *testing.T) {
+	tree := sampleSnapshotTree()
+	b := tree.NodesByPath["/b"]
+	b.Data = gzipped(t, strings.Repeat("x", 1000))
+	m := NewModel(tree)
+	m.selectNode(b)
+	want := fmt.Sprintf("bytes (uncompressed), gzip %.1fx", 1000/float64(len(b.Data)))
+	if !strings.Contains(m.renderMetadata(), want) {
+		t.Fatalf("expected the ratio in the metadata, got %q", m.renderMetadata())
+	}
+}
+
+func TestSizeSummaryMatchesFormatFromCache(t *testing.T) {
+	c := newCompressionCache()
+	for _, data := range [][]byte{gzipped(t, strings.Repeat("abc", 100)), []byte("plain"), nil} {
+		node := &snapshot.Node{Data: data}
+		if got, want := c.sizeSummary(node), format.DataSizeSummary(data); got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+		if _, ok := c.entries[node]; !ok {
+			t.Fatal("expected the size summary to use the cache")
+		}
+	}
+}
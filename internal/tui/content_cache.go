@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/format"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+const (
+	contentCacheCapacity = 128
+	// prefetchByteBudget bounds how much raw node data one prefetch round may
+	// format, so heavy neighbors never keep a background goroutine busy.
+	prefetchByteBudget = 1024 * 1024
+)
+
+// nodeDescription is what the content and metadata panes show about a
+// node's data, as format.Describe returns it.
+type nodeDescription struct {
+	content string
+	size    string
+	class   string
+}
+
+func describeNode(node *snapshot.Node) nodeDescription {
+	var d nodeDescription
+	d.content, d.size, d.class = format.Describe(node.Bytes())
+	return d
+}
+
+// contentCache keeps the described content of recently visited and
+// prefetched nodes. It is shared between the model and prefetch goroutines.
+type contentCache struct {
+	mu         sync.Mutex
+	entries    map[*snapshot.Node]nodeDescription
+	order      []*snapshot.Node
+	capacity   int
+	generation uint64
+}
+
+func newContentCache(capacity int) *contentCache {
+	return &contentCache{
+		entries:  make(map[*snapshot.Node]nodeDescription, capacity),
+		capacity: capacity,
+	}
+}
+
+func (c *contentCache) get(node *snapshot.Node) (nodeDescription, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d, ok := c.entries[node]
+	return d, ok
+}
+
+func (c *contentCache) put(node *snapshot.Node, d nodeDescription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[node]; ok {
+		c.entries[node] = d
+		return
+	}
+	if c.capacity > 0 && len(c.order) >= c.capacity {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		delete(c.entries, oldest)
+	}
+	c.entries[node] = d
+	c.order = append(c.order, node)
+}
+
+// describe returns the description of node, describing and caching it on a
+// miss. A nil cache describes it every time.
+func (c *contentCache) describe(node *snapshot.Node) nodeDescription {
+	if c == nil {
+		return describeNode(node)
+	}
+	if d, ok := c.get(node); ok {
+		return d
+	}
+	d := describeNode(node)
+	c.put(node, d)
+	return d
+}
+
+// startPrefetch cancels any running prefetch and returns the generation the
+// new one should run under.
+func (c *contentCache) startPrefetch() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	return c.generation
+}
+
+func (c *contentCache) cancelled(generation uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation != generation
+}
+
+// prefetchDoneMsg reports that the prefetch of a generation ended, whether
+// it formatted every node, was superseded or ran out of budget.
+type prefetchDoneMsg struct {
+	generation uint64
+}
+
+// prefetchCmd prefetches nodes into cache under generation.
+func prefetchCmd(cache *contentCache, generation uint64, nodes []*snapshot.Node) tea.Cmd {
+	return func() tea.Msg {
+		cache.prefetch(generation, nodes, prefetchByteBudget)
+		return prefetchDoneMsg{generation: generation}
+	}
+}
+
+// prefetch formats nodes that are not cached yet. It stops as soon as a newer
+// prefetch starts and skips nodes that would exceed the byte budget.
+func (c *contentCache) prefetch(generation uint64, nodes []*snapshot.Node, budget int) {
+	for _, node := range nodes {
+		if node == nil || c.cancelled(generation) {
+			return
+		}
+		if _, ok := c.get(node); ok {
+			continue
+		}
+		if node.DataLen() > budget {
+			continue
+		}
+		budget -= node.DataLen()
+		c.put(node, describeNode(node))
+	}
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+func TestSelectionPrefetchesNeighborContent(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	cache := m.content
+	var model tea.Model = m
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRight})
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	typed := model.(Model)
+	if typed.selected.Path != "/a/a1" {
+		t.Fatalf("expected /a/a1 selected, got %q", typed.selected.Path)
+	}
+	if _, ok := cache.get(typed.tree.NodesByPath["/b"]); ok {
+		t.Fatal("expected the prefetch to wait for its command to run")
+	}
+	if cmd == nil {
+		t.Fatal("expected a prefetch command")
+	}
+	if _, ok := cmd().(prefetchDoneMsg); !ok {
+		t.Fatal("expected the prefetch to report that it is done")
+	}
+	for _, path := range []string{"/a", "/b"} {
+		if _, ok := cache.get(typed.tree.NodesByPath[path]); !ok {
+			t.Fatalf("expected neighbor %s to be prefetched", path)
+		}
+	}
+}
+
+func TestContentCachePrefetchStopsWhenSuperseded(t *testing.T) {
+	cache := newContentCache(contentCacheCapacity)
+	node := &snapshot.Node{ID: "a", Path: "/a", Data: []byte("a")}
+
+	stale := cache.startPrefetch()
+	cache.startPrefetch()
+	cache.prefetch(stale, []*snapshot.Node{node}, prefetchByteBudget)
+	if _, ok := cache.get(node); ok {
+		t.Fatal("expected superseded prefetch to do nothing")
+	}
+}
+
+func TestContentCachePrefetchRespectsByteBudget(t *testing.T) {
+	cache := newContentCache(contentCacheCapacity)
+	small := &snapshot.Node{ID: "s", Path: "/s", Data: []byte("abc")}
+	large := &snapshot.Node{ID: "l", Path: "/l", Data: make([]byte, 16)}
+
+	cache.prefetch(cache.startPrefetch(), []*snapshot.Node{large, small}, 8)
+	if _, ok := cache.get(large); ok {
+		t.Fatal("expected node over budget to be skipped")
+	}
+	if _, ok := cache.get(small); !ok {
+		t.Fatal("expected small node to be prefetched")
+	}
+}
+
+func TestContentCacheEvictsOldestEntry(t *testing.T) {
+	cache := newContentCache(2)
+	a := &snapshot.Node{Path: "/a"}
+	b := &snapshot.Node{Path: "/b"}
+	c := &snapshot.Node{Path: "/c"}
+	cache.put(a, nodeDescription{content: "a"})
+	cache.put(b, nodeDescription{content: "b"})
+	cache.put(c, nodeDescription{content: "c"})
+	if _, ok := cache.get(a); ok {
+		t.Fatal("expected oldest entry to be evicted")
+	}
+	if d, ok := cache.get(c); !ok || d.content != "c" {
+		t.Fatalf("expected newest entry cached, got %q", d.content)
+	}
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/format"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// plainContent is the node's content as the content pane shows it, decoded
+// and decompressed with the node's encoding, but without colors.
+func (m Model) plainContent(node *snapshot.Node) string {
+	if node.DataLen() == 0 {
+		return ""
+	}
+	return format.RenderAsTheme(node.Bytes(), m.nodeEncoding(node), format.Theme{})
+}
+
+// copySelectedContent copies the selected node's decoded content.
+func (m *Model) copySelectedContent() tea.Cmd {
+	text := m.plainContent(m.selected)
+	return m.copyWithHint(text, fmt.Sprintf("%d bytes of content", len(text)))
+}
+
+// saveSelectedContent writes the selected node's decoded content to a new
+// file in the working directory named after the node.
+func (m *Model) saveSelectedContent() tea.Cmd {
+	text := m.plainContent(m.selected)
+	if text != "" {
+		text += "\n"
+	}
+	name, err := writeNewFile(contentFileName(m.selected), []byte(text))
+	if err != nil {
+		return m.flashHint("save failed: " + err.Error())
+	}
+	return m.flashHint(fmt.Sprintf("wrote %d bytes to %s", len(text), name))
+}
+
+// statsCloseHint ends the stats dialog, below the report that s saves.
+// statsScrollHint replaces it when the report does not fit the screen.
+const (
+	statsCloseHint  = "Press s to save, any other key to close."
+	statsScrollHint = "Up/Down scrolls, s saves, other keys close."
+)
+
+// saveStats writes the stats report as shown to a new, timestamped file in
+// the working directory.
+func (m *Model) saveStats() tea.Cmd {
+	text := m.statsText + "\n"
+	name, err := writeNewFile(statsFileName(time.Now()), []byte(text))
+	if err != nil {
+		return m.flashHint("save failed: " + err.Error())
+	}
+	return m.flashHint("wrote stats to " + name)
+}
+
+// statsFileName names the file the stats report is saved to at t.
+func statsFileName(t time.Time) string {
+	return "zooxplorer-stats-" + t.Format("20060102-150405") + ".txt"
+}
+
+// contentFileName names the file a node's content is saved to.
+func contentFileName(node *snapshot.Node) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, node.ID)
+	if name == "" || name == "/" || name == "." || name == ".." {
+		return "root"
+	}
+	return name
+}
+
+// writeNewFile writes data to name, or to name.1, name.2 and so on when
+// name exists, and returns the name used.
+func writeNewFile(name string, data []byte) (string, error) {
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s.%d", name, i)
+		}
+		f, err := os.OpenFile(candidate, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return "", err
+		}
+		return candidate, f.Close()
+	}
+}
@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatalf("gzip write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	return b.Bytes()
+}
+
+func TestCopyContentCopiesDecodedPlainText(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a"].Data = gzipped(t, `{"k":1}`)
+	m := NewModel(tree)
+	var copied string
+	m.copyContent = func(s string) error {
+		copied = s
+		return nil
+	}
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if want := "{\n  \"k\": 1\n}"; copied != want {
+		t.Fatalf("expected %q copied, got %q", want, copied)
+	}
+	if got := model.(Model).keyHint; got != "copied 12 bytes of content" {
+		t.Fatalf("unexpected hint %q", got)
+	}
+}
+
+func TestSaveContentWritesNewFileNamedAfterNode(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a"].Data = gzipped(t, "hello")
+	var model tea.Model = NewModel(tree)
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if got := model.(Model).keyHint; got != "wrote 6 bytes to a" {
+		t.Fatalf("unexpected hint %q", got)
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if got := model.(Model).keyHint; got != "wrote 6 bytes to a.1" {
+		t.Fatalf("expected an existing file to be kept, got %q", got)
+	}
+	for _, name := range []string{"a", "a.1"} {
+		if data, err := os.ReadFile(name); err != nil || string(data) != "hello\n" {
+			t.Fatalf("expected decompressed content in %s, got %q (%v)", name, data, err)
+		}
+	}
+}
+
+func TestStatsDialogSavesReportToFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	typed := model.(Model)
+	if !typed.statsOpen {
+		t.Fatal("expected the stats dialog to stay open after saving")
+	}
+	if _, err := os.Stat("a"); err == nil {
+		t.Fatal("expected s to save the report, not the selected node's content")
+	}
+	name := strings.TrimPrefix(typed.keyHint, "wrote stats to ")
+	if !strings.HasPrefix(name, "zooxplorer-stats-") || !strings.HasSuffix(name, ".txt") {
+		t.Fatalf("unexpected hint %q", typed.keyHint)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("read saved stats: %v", err)
+	}
+	if string(data) != typed.statsText+"\n" {
+		t.Fatalf("expected the report as shown, got %q", data)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if model.(Model).statsOpen {
+		t.Fatal("expected any other key to close the dialog")
+	}
+}
+
+func TestStatsFileNameHasTimestamp(t *testing.T) {
+	at := time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC)
+	if got := statsFileName(at); got != "zooxplorer-stats-20240305-140709.txt" {
+		t.Fatalf("unexpected name %q", got)
+	}
+}
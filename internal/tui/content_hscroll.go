@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// contentHScrollStep is how many columns left and right scroll long content
+// lines by.
+const contentHScrollStep = 8
+
+// scrollContentH scrolls unwrapped content delta columns sideways, no further
+// than it takes to show the end of the longest line.
+func (m *Model) scrollContentH(delta int) {
+	if m.wrapContent {
+		m.contentHScroll = 0
+		return
+	}
+	widest := 0
+	for _, line := range m.displayLines {
+		widest = max(widest, lipgloss.Width(line))
+	}
+	_, rightOuter, _ := m.layout()
+	textWidth := contentTextWidth(rightOuter-2, m.contentInnerHeight(), len(m.displayLines))
+	maxScroll := max(widest-textWidth, 0)
+	m.contentHScroll = min(max(m.contentHScroll+delta, 0), maxScroll)
+}
+
+// contentHScrollFooter tells which column the content pane starts at and
+// whether the lines shown in it continue further right, e.g. "← col 9 →";
+// empty while everything fits.
+func (m Model) contentHScrollFooter(width, height int) string {
+	if m.wrapContent {
+		return ""
+	}
+	textWidth := contentTextWidth(width, height, len(m.displayLines))
+	more := false
+	end := min(m.contentOffset+height, len(m.displayLines))
+	for _, line := range m.displayLines[min(m.contentOffset, end):end] {
+		if lipgloss.Width(line) > m.contentHScroll+textWidth {
+			more = true
+			break
+		}
+	}
+	if m.contentHScroll == 0 && !more {
+		return ""
+	}
+	footer := "col " + formatCount(m.contentHScroll+1)
+	if m.contentHScroll > 0 {
+		footer = "← " + footer
+	}
+	if more {
+		footer += " →"
+	}
+	return footer
+}
+
+// contentTextWidth is the width of content text in a pane of width columns
+// and height rows, less the scrollbar that lines beyond the height need.
+func contentTextWidth(width, height, lines int) int {
+	if lines > height && width > 1 {
+		return width - 1
+	}
+	return width
+}
+
+// skipColumnsANSI drops the first cols columns of s, keeping its escape
+// sequences so that the colors of the rest stay intact.
+func skipColumnsANSI(s string, cols int) string {
+	if cols <= 0 {
+		return s
+	}
+	var b strings.Builder
+	skipped := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '[' {
+			j := i + 2
+			for j < len(s) {
+				c := s[j]
+				j++
+				if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') {
+					break
+				}
+			}
+			b.WriteString(s[i:j])
+			i = j
+			continue
+		}
+		if skipped >= cols {
+			b.WriteString(s[i:])
+			break
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		skipped += lipgloss.Width(string(r))
+		i += size
+	}
+	return b.String()
+}
+
+// withBorderFooter puts footer into the bottom border of box, like
+// withBorderTitle does with the top one.
+func withBorderFooter(box, footer string, borderStyle lipgloss.Style) string {
+	lines := strings.Split(box, "\n")
+	last := len(lines) - 1
+	width := lipgloss.Width(lines[last])
+	border := lipgloss.NormalBorder()
+	room := width - 6
+	if footer == "" || room < 1 {
+		return box
+	}
+	footer = truncate(footer, room)
+	fill := width - 5 - lipgloss.Width(footer)
+	lines[last] = borderStyle.Render(border.BottomLeft+border.Bottom) +
+		" " + footer + " " +
+		borderStyle.Render(strings.Repeat(border.Bottom, fill)+border.BottomRight)
+	return strings.Join(lines, "\n")
+}
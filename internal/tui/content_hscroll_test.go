@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestContentScrollsSideways(t *testing.T) {
+	tree := sampleSnapshotTree()
+	long := ""
+	for i := 0; i < 30; i++ {
+		long += string(rune('a'+i%26)) + strings.Repeat(".", 9)
+	}
+	tree.NodesByPath["/a"].Data = []byte(long)
+	var model tea.Model = NewModel(tree)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 100, Height: 21})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if footer := model.(Model).contentHScrollFooter(40, 4); footer != "col 1 →" {
+		t.Fatalf("expected a hint at more to the right, got %q", footer)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRight})
+	typed := model.(Model)
+	if typed.contentHScroll != contentHScrollStep || typed.selected.Path != "/a" {
+		t.Fatalf("expected the content to scroll, got column %d", typed.contentHScroll)
+	}
+	if view := stripANSI(typed.View()); !strings.Contains(view, "..b.........c") || !strings.Contains(view, "← col 9 →") {
+		t.Fatalf("expected the content from column 9 and a footer, got:\n%s", view)
+	}
+
+	for i := 0; i < 100; i++ {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRight})
+	}
+	typed = model.(Model)
+	// The single line needs no scrollbar, so it gets the wrap width's column.
+	if want := len(long) - typed.contentWrapWidth() - 1; typed.contentHScroll != want {
+		t.Fatalf("expected scrolling to stop at column %d, got %d", want, typed.contentHScroll)
+	}
+	if view := stripANSI(typed.View()); !strings.Contains(view, ".d.........│") || strings.Contains(view, " →") {
+		t.Fatalf("expected the end of the line without a hint at more, got:\n%s", view)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if got := model.(Model).contentHScroll; got != typed.contentHScroll-contentHScrollStep {
+		t.Fatalf("expected left to scroll back, got column %d", got)
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if typed = model.(Model); typed.contentHScroll != 0 {
+		t.Fatalf("expected another node to start at column 1, got %d", typed.contentHScroll)
+	}
+}
+
+func TestSkipColumnsANSIKeepsColors(t *testing.T) {
+	if got := skipColumnsANSI("\x1b[31mhello\x1b[0m world", 3); got != "\x1b[31mlo\x1b[0m world" {
+		t.Fatalf("unexpected result %q", got)
+	}
+	if got := skipColumnsANSI("short", 10); got != "" {
+		t.Fatalf("expected nothing left, got %q", got)
+	}
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jowiho/zooxplorer/internal/format"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// contentTypeLabel names what an encoding says about the data itself: data
+// only viewable as hex is binary.
+func contentTypeLabel(enc format.Encoding) string {
+	switch enc {
+	case format.EncodingHex:
+		return "Binary"
+	case format.EncodingBase64:
+		return "Base64"
+	}
+	return enc.String()
+}
+
+// contentTypeTitle describes how the content pane interprets node's data: the
+// detected type, the encoding forced with "e" if any, and the other
+// encodings "e" offers.
+func (m Model) contentTypeTitle(node *snapshot.Node, data []byte) string {
+	if m.diffPinned && m.pinned != nil && m.pinned != node {
+		return "Diff"
+	}
+	if len(data) == 0 {
+		return "Empty"
+	}
+	detected := format.DetectEncoding(data)
+	shown := m.nodeEncoding(node)
+	title := contentTypeLabel(detected)
+	if shown != detected {
+		title += " shown as " + shown.String()
+	}
+	var others []string
+	for _, enc := range format.Interpretations(data) {
+		if enc != shown {
+			others = append(others, enc.String())
+		}
+	}
+	if len(others) > 0 {
+		title += " (e: " + strings.Join(others, ", ") + ")"
+	}
+	return title
+}
+
+// withBorderTitle puts title into the top border of a box rendered with a
+// normal border, keeping the box width.
+func withBorderTitle(box, title string, borderStyle lipgloss.Style) string {
+	lines := strings.SplitN(box, "\n", 2)
+	width := lipgloss.Width(lines[0])
+	border := lipgloss.NormalBorder()
+	room := width - 6 // corners, a line on either side and padding spaces
+	if title == "" || room < 1 {
+		return box
+	}
+	title = truncate(title, room)
+	fill := width - 5 - lipgloss.Width(title)
+	lines[0] = borderStyle.Render(border.TopLeft+border.Top) +
+		" " + title + " " +
+		borderStyle.Render(strings.Repeat(border.Top, fill)+border.TopRight)
+	return strings.Join(lines, "\n")
+}
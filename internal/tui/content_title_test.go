@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jowiho/zooxplorer/internal/format"
+)
+
+func TestContentBoxTitleShowsDetectedType(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a"].Data = []byte(`{"k": 1}`)
+	var model tea.Model = NewModel(tree)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+
+	var titleLine string
+	for _, line := range strings.Split(stripANSI(model.View()), "\n") {
+		if strings.Contains(line, "┌─ JSON") {
+			titleLine = line
+		}
+	}
+	if titleLine == "" {
+		t.Fatalf("expected a content box titled JSON, got:\n%s", stripANSI(model.View()))
+	}
+	if !strings.Contains(titleLine, "JSON (e: Text, Hex) ") || !strings.HasSuffix(titleLine, "┐") {
+		t.Fatalf("expected the title to list the other encodings, got %q", titleLine)
+	}
+
+	typed := model.(Model)
+	typed.encodings[typed.selected] = format.EncodingHex
+	typed.reloadContent()
+	if got := typed.contentTitle; got != "JSON shown as Hex (e: Text, JSON)" {
+		t.Fatalf("expected the forced encoding in the title, got %q", got)
+	}
+}
+
+func TestWithBorderTitleKeepsBoxWidth(t *testing.T) {
+	box := lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Width(20).Render("x")
+	titled := withBorderTitle(box, "a rather long title for this box", lipgloss.NewStyle())
+	lines := strings.Split(titled, "\n")
+	if lipgloss.Width(lines[0]) != lipgloss.Width(strings.Split(box, "\n")[0]) {
+		t.Fatalf("expected the titled border to keep the width, got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[0], "┌─ a rather") {
+		t.Fatalf("expected the truncated title in the border, got %q", lines[0])
+	}
+}
@@ -0,0 +1,255 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/format"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+const (
+	// searchTextBudget bounds the decoded text kept for searches, so that
+	// scanning a huge tree cannot hold all of its content in memory twice.
+	searchTextBudget = 64 * 1024 * 1024
+	// dataSearchProgressEvery is how many nodes a data search scans between
+	// progress reports.
+	dataSearchProgressEvery = 256
+)
+
+// searchTextCache keeps the decoded, uncolored content of nodes for searches,
+// which would otherwise decode every node again for every query. It is shared
+// between the model and search goroutines.
+type searchTextCache struct {
+	mu     sync.Mutex
+	texts  map[*snapshot.Node]string
+	budget int
+}
+
+func newSearchTextCache(budget int) *searchTextCache {
+	return &searchTextCache{texts: make(map[*snapshot.Node]string), budget: budget}
+}
+
+// text returns the decoded content of node, caching it while the budget
+// lasts. A nil cache decodes every time.
+func (c *searchTextCache) text(node *snapshot.Node) string {
+	if c == nil {
+		return plainNodeContent(node)
+	}
+	c.mu.Lock()
+	text, ok := c.texts[node]
+	c.mu.Unlock()
+	if ok {
+		return text
+	}
+	text = plainNodeContent(node)
+	c.mu.Lock()
+	if len(text) <= c.budget {
+		c.texts[node] = text
+		c.budget -= len(text)
+	}
+	c.mu.Unlock()
+	return text
+}
+
+// contentTextAs returns node's uncolored content as the content pane shows
+// it: rendered as enc when override is set, i.e. the user chose enc with e,
+// and decoded as detected otherwise.
+func contentTextAs(node *snapshot.Node, enc format.Encoding, override bool, texts *searchTextCache) string {
+	if override {
+		return stripContentANSI(format.RenderAs(node.Bytes(), enc))
+	}
+	return texts.text(node)
+}
+
+// shownText is contentTextAs with node's encoding override, the text that
+// search match offsets index into.
+func (m Model) shownText(node *snapshot.Node) string {
+	enc, override := m.encodings[node]
+	return contentTextAs(node, enc, override, m.searchTexts)
+}
+
+// dataSearch is a running scan of all node data for a query.
+type dataSearch struct {
+	id      int
+	query   string
+	scanned int
+	total   int
+	ctx     context.Context
+	cancel  context.CancelFunc
+	events  chan tea.Msg
+}
+
+type dataSearchProgressMsg struct {
+	id      int
+	scanned int
+}
+
+type dataSearchDoneMsg struct {
+	id    int
+	nodes []*snapshot.Node
+}
+
+func (m *Model) openDataSearchPrompt() {
+	m.prompt = &prompt{
+		title: "Find all nodes whose data contains",
+		label: "Text: ",
+		submit: func(m *Model, input string) error {
+			if input == "" {
+				return errors.New("enter the text to find")
+			}
+			return nil
+		},
+		start: func(m *Model, input string) tea.Cmd {
+			return m.startDataSearch(input)
+		},
+	}
+}
+
+// startDataSearch scans the data of every node for query in the background,
+// in tree order, replacing any previous search.
+func (m *Model) startDataSearch(query string) tea.Cmd {
+	m.cancelDataSearch()
+	m.clearDataMatches()
+	if m.tree == nil {
+		return nil
+	}
+	nodes := depthFirstNodesByName(m.tree)
+	ctx, cancel := context.WithCancel(context.Background())
+	m.dataSearchSeq++
+	s := &dataSearch{
+		id:     m.dataSearchSeq,
+		query:  query,
+		total:  len(nodes),
+		ctx:    ctx,
+		cancel: cancel,
+		events: make(chan tea.Msg, 1),
+	}
+	m.dataSearch = s
+	go scanNodeData(ctx, s.id, nodes, query, m.searchTexts, s.events)
+	return waitDataSearchCmd(ctx, s.events)
+}
+
+// scanNodeData reports the nodes whose decoded content or raw data contains
+// query.
+func scanNodeData(ctx context.Context, id int, nodes []*snapshot.Node, query string, texts *searchTextCache, events chan<- tea.Msg) {
+	raw := []byte(query)
+	var found []*snapshot.Node
+	for i, node := range nodes {
+		if ctx.Err() != nil {
+			return
+		}
+		if i > 0 && i%dataSearchProgressEvery == 0 {
+			select {
+			case events <- dataSearchProgressMsg{id: id, scanned: i}:
+			default:
+			}
+		}
+		if strings.Contains(texts.text(node), query) || bytes.Contains(node.Bytes(), raw) {
+			found = append(found, node)
+		}
+	}
+	select {
+	case events <- dataSearchDoneMsg{id: id, nodes: found}:
+	case <-ctx.Done():
+	}
+}
+
+func waitDataSearchCmd(ctx context.Context, events <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg := <-events:
+			return msg
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (m *Model) cancelDataSearch() {
+	if m.dataSearch != nil {
+		m.dataSearch.cancel()
+		m.dataSearch = nil
+	}
+}
+
+func (m *Model) clearDataMatches() {
+	m.dataMatches = nil
+	m.dataMatchQuery = ""
+	m.dataMatchIndex = 0
+}
+
+// updateDataSearch handles the progress and results of the running search;
+// messages of replaced searches are dropped.
+func (m *Model) updateDataSearch(msg tea.Msg) tea.Cmd {
+	s := m.dataSearch
+	switch msg := msg.(type) {
+	case dataSearchProgressMsg:
+		if s == nil || msg.id != s.id {
+			return nil
+		}
+		next := *s
+		next.scanned = msg.scanned
+		m.dataSearch = &next
+		return waitDataSearchCmd(s.ctx, s.events)
+	case dataSearchDoneMsg:
+		if s == nil || msg.id != s.id {
+			return nil
+		}
+		s.cancel()
+		m.dataSearch = nil
+		if len(msg.nodes) == 0 {
+			return m.flashHint(fmt.Sprintf("No node data contains %q", s.query))
+		}
+		m.dataMatches = msg.nodes
+		m.dataMatchQuery = s.query
+		m.showDataMatch(0)
+	}
+	return nil
+}
+
+// stepDataMatch selects the next (delta 1) or previous (delta -1) match,
+// wrapping around.
+func (m *Model) stepDataMatch(delta int) {
+	if len(m.dataMatches) == 0 {
+		return
+	}
+	n := len(m.dataMatches)
+	m.showDataMatch(((m.dataMatchIndex+delta)%n + n) % n)
+}
+
+// showDataMatch selects match i and highlights the query in its content.
+func (m *Model) showDataMatch(i int) {
+	m.dataMatchIndex = i
+	node := m.dataMatches[i]
+	if m.filter != nil && !m.filter.keep(node) {
+		m.clearFilter()
+	}
+	m.jumpTo(node)
+	m.centerSelectedRowInTree()
+	if at := strings.Index(m.shownText(node), m.dataMatchQuery); at >= 0 {
+		m.matchQuery = m.dataMatchQuery
+		m.matchIndex = at
+		m.matchNode = node
+		m.scrollMatchIntoView()
+	} else {
+		m.clearContentMatch()
+	}
+}
+
+// dataSearchStatus describes the running search or the current match for the
+// status bar.
+func (m Model) dataSearchStatus() string {
+	if s := m.dataSearch; s != nil {
+		return fmt.Sprintf("Scanning data for %q: %s of %s nodes", s.query, formatCount(s.scanned), formatCount(s.total))
+	}
+	if len(m.dataMatches) > 0 {
+		return fmt.Sprintf("%s Match %d of %d for %q", statusKeyStyle.Render("n/N"), m.dataMatchIndex+1, len(m.dataMatches), m.dataMatchQuery)
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/format"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// runDataSearch types query into the data search prompt and feeds the
+// search's messages back until it is done.
+func runDataSearch(t *testing.T, model tea.Model, query string) Model {
+	t.Helper()
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(query)})
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for cmd != nil {
+		msg := cmd()
+		model, cmd = model.Update(msg)
+		if _, done := msg.(dataSearchDoneMsg); done {
+			break
+		}
+	}
+	return model.(Model)
+}
+
+func TestDataSearchFindsDecodedAndRawContent(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a/a1"].Data = gzipped(t, "the needle is compressed")
+	tree.NodesByPath["/b"].Data = []byte("plain needle")
+	m := runDataSearch(t, NewModel(tree), "needle")
+
+	if len(m.dataMatches) != 2 || m.dataMatches[0].Path != "/a/a1" || m.dataMatches[1].Path != "/b" {
+		t.Fatalf("expected /a/a1 and /b in tree order, got %v", m.dataMatches)
+	}
+	if m.selected.Path != "/a/a1" || m.matchNode != m.selected || m.matchQuery != "needle" {
+		t.Fatalf("expected the first match selected and highlighted, got %q", m.selected.Path)
+	}
+	if status := stripANSI(m.renderStatusBar(300)); !strings.Contains(status, `Match 1 of 2 for "needle"`) {
+		t.Fatalf("expected the match position in the status bar, got %q", status)
+	}
+
+	var model tea.Model = m
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if got := model.(Model).selected.Path; got != "/b" {
+		t.Fatalf("expected n to select the next match, got %q", got)
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if got := model.(Model).selected.Path; got != "/a/a1" {
+		t.Fatalf("expected n to wrap around, got %q", got)
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	if got := model.(Model).selected.Path; got != "/b" {
+		t.Fatalf("expected N to select the previous match, got %q", got)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if typed := model.(Model); len(typed.dataMatches) != 0 || strings.Contains(typed.renderStatusBar(300), "Match") {
+		t.Fatal("expected Esc to clear the matches")
+	}
+}
+
+func TestDataSearchHighlightsTheContentAsShown(t *testing.T) {
+	tree := sampleSnapshotTree()
+	b := tree.NodesByPath["/b"]
+	b.Data = []byte(`{"k":"needle"}`)
+	m := NewModel(tree)
+	m.encodings[b] = format.EncodingText
+	m = runDataSearch(t, m, "needle")
+
+	shown := m.selectedContentText()
+	if m.selected != b || shown != `{"k":"needle"}` {
+		t.Fatalf("expected /b shown as raw text, got %q", shown)
+	}
+	if m.matchIndex != strings.Index(shown, "needle") {
+		t.Fatalf("expected the match at %d of the shown text, got %d", strings.Index(shown, "needle"), m.matchIndex)
+	}
+}
+
+func TestDataSearchWithoutMatches(t *testing.T) {
+	m := runDataSearch(t, NewModel(sampleSnapshotTree()), "absent")
+	if len(m.dataMatches) != 0 || m.keyHint != `No node data contains "absent"` {
+		t.Fatalf("expected a no-results hint, got %q", m.keyHint)
+	}
+}
+
+func TestDataSearchReportsProgressAndDropsStaleMessages(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	m.startDataSearch("line")
+	first := m.dataSearch
+	m.startDataSearch("line3")
+	if first.ctx.Err() == nil {
+		t.Fatal("expected a new search to cancel the previous one")
+	}
+
+	var model tea.Model = m
+	model, cmd := model.Update(dataSearchProgressMsg{id: m.dataSearch.id, scanned: 2})
+	typed := model.(Model)
+	if status := typed.renderStatusBar(300); !strings.Contains(status, `Scanning data for "line3": 2 of 3 nodes`) || cmd == nil {
+		t.Fatalf("expected scan progress in the status bar, got %q", status)
+	}
+	model, _ = model.Update(dataSearchDoneMsg{id: first.id, nodes: []*snapshot.Node{typed.tree.NodesByPath["/b"]}})
+	if typed = model.(Model); len(typed.dataMatches) != 0 || typed.dataSearch == nil {
+		t.Fatal("expected results of a replaced search to be dropped")
+	}
+}
+
+func TestSearchTextCacheKeepsTextsWithinBudget(t *testing.T) {
+	small := &snapshot.Node{Data: []byte("tiny")}
+	big := &snapshot.Node{Data: []byte(strings.Repeat("x", 64))}
+	c := newSearchTextCache(10)
+	if c.text(small) != "tiny" || c.text(big) != strings.Repeat("x", 64) {
+		t.Fatal("expected decoded texts")
+	}
+	if _, ok := c.texts[small]; !ok {
+		t.Fatal("expected the small text to be cached")
+	}
+	if _, ok := c.texts[big]; ok {
+		t.Fatal("expected the text over budget not to be cached")
+	}
+}
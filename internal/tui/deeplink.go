@@ -0,0 +1,18 @@
+package tui
+
+import "strings"
+
+// deepLink returns a command line that opens snapshotPath with the node at
+// nodePath selected.
+func deepLink(snapshotPath, nodePath string) string {
+	return "zooxplorer " + shellQuote(snapshotPath) + " -path " + shellQuote(printablePath(nodePath))
+}
+
+// shellQuote quotes s for POSIX shells unless it only contains characters
+// that need no quoting.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789/._-:=@+,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
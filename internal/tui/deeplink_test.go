@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCopyDeepLinkToSelectedNode(t *testing.T) {
+	m := NewModelWithOptions(sampleSnapshotTree(), Options{SnapshotPath: "/data/version-2/snapshot.1a"})
+	var copied string
+	m.copyContent = func(s string) error {
+		copied = s
+		return nil
+	}
+
+	var model tea.Model = m
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if want := "zooxplorer /data/version-2/snapshot.1a -path /b"; copied != want {
+		t.Fatalf("expected deep link %q, got %q", want, copied)
+	}
+}
+
+func TestCopySelectedPathFlashesHint(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	var copied string
+	m.copyContent = func(s string) error {
+		copied = s
+		return nil
+	}
+
+	var model tea.Model = m
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Y")})
+	if copied != "/b" || cmd == nil {
+		t.Fatalf("expected /b copied with a hint timer, got %q", copied)
+	}
+	if got := model.(Model).keyHint; got != "copied /b" {
+		t.Fatalf("expected a copied hint, got %q", got)
+	}
+
+	typed := model.(Model)
+	typed.copyContent = func(string) error { return errors.New("no clipboard tool") }
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Y")})
+	if got := model.(Model).keyHint; got != "clipboard unavailable" {
+		t.Fatalf("expected the clipboard to be reported unavailable, got %q", got)
+	}
+}
+
+func TestDeepLinkQuotesSpecialCharacters(t *testing.T) {
+	got := deepLink("/tmp/my snap", "/it's")
+	if want := `zooxplorer '/tmp/my snap' -path '/it'\''s'`; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestStartPathSelectsNode(t *testing.T) {
+	m := NewModelWithOptions(sampleSnapshotTree(), Options{StartPath: "/a/a1"})
+	if m.selected == nil || m.selected.Path != "/a/a1" {
+		t.Fatalf("expected /a/a1 selected, got %v", m.selected)
+	}
+	if m.selectedRowIndex() == -1 {
+		t.Fatal("expected start node to be visible")
+	}
+
+	m = NewModelWithOptions(sampleSnapshotTree(), Options{StartPath: "/missing"})
+	if m.selected.Path != "/a" {
+		t.Fatalf("expected unknown start path to keep default selection, got %q", m.selected.Path)
+	}
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"github.com/jowiho/zooxplorer/internal/format"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// togglePin pins the displayed node as the left side of content diffs, or
+// unpins it when it is already pinned.
+func (m *Model) togglePin() {
+	node := m.detailNode()
+	if node == nil {
+		return
+	}
+	if m.pinned == node {
+		m.pinned = nil
+		m.diffPinned = false
+	} else {
+		m.pinned = node
+	}
+	m.reloadContent()
+}
+
+// toggleDiff switches the content pane between the displayed node's content
+// and its diff against the pinned node.
+func (m *Model) toggleDiff() {
+	if m.pinned == nil {
+		return
+	}
+	m.diffPinned = !m.diffPinned
+	m.reloadContent()
+}
+
+// reloadContent re-renders the content pane from the top, e.g. after the way
+// the displayed node is rendered has changed.
+func (m *Model) reloadContent() {
+	m.contentNode = nil
+	m.contentOffset = 0
+	m.contentSelect = false
+	m.clearContentMatch()
+	m.refreshContentLines()
+}
+
+// diffContent renders the line diff of the pinned node's decoded content
+// against node's.
+func (m Model) diffContent(node *snapshot.Node) string {
+	return format.UnifiedDiff(
+		printablePath(m.pinned.Path),
+		printablePath(node.Path),
+		stripContentANSI(m.formattedContent(m.pinned)),
+		stripContentANSI(m.formattedContent(node)),
+	)
+}
+
+func stripContentANSI(s string) string {
+	return ansiEscapeRE.ReplaceAllString(s, "")
+}
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+func TestPinnedNodeDiffInContentPane(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	v1 := &snapshot.Node{ID: "v1", Path: "/v1", Parent: root, Data: []byte(`{"port":1,"tls":false}`)}
+	v2 := &snapshot.Node{ID: "v2", Path: "/v2", Parent: root, Data: []byte(`{"port":2,"tls":false}`)}
+	root.Children = []*snapshot.Node{v1, v2}
+
+	var model tea.Model = NewModel(&snapshot.Tree{Root: root})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	typed := model.(Model)
+	if typed.pinned != v1 || !typed.diffPinned {
+		t.Fatal("expected /v1 pinned and diff shown")
+	}
+
+	got := stripANSI(strings.Join(typed.contentLines, "\n"))
+	want := "--- /v1\n+++ /v2\n {\n-  \"port\": 1,\n+  \"port\": 2,\n   \"tls\": false\n }"
+	if got != want {
+		t.Fatalf("unexpected diff content:\n%s", got)
+	}
+
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	got = stripANSI(strings.Join(model.(Model).contentLines, "\n"))
+	if strings.Contains(got, "+++") {
+		t.Fatalf("expected plain content after toggling diff off, got:\n%s", got)
+	}
+}
+
+func TestPinKeyOnPinnedNodeUnpins(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	m.togglePin()
+	if m.pinned != m.selected {
+		t.Fatal("expected selection pinned")
+	}
+	m.toggleDiff()
+	m.togglePin()
+	if m.pinned != nil || m.diffPinned {
+		t.Fatal("expected pin and diff cleared")
+	}
+}
@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// Limits of the duplicates dialog.
+const (
+	maxDuplicateGroups  = 10
+	maxDuplicateMembers = 5
+)
+
+// duplicateGroup is a set of nodes with identical, non-empty data.
+type duplicateGroup struct {
+	hash  string
+	size  int
+	paths []string
+}
+
+// duplicateGroups lists the content shared by more than one node, the groups
+// wasting the most bytes first.
+func duplicateGroups(tree *snapshot.Tree) []duplicateGroup {
+	var groups []duplicateGroup
+	for hash, nodes := range tree.NodesByContentHash() {
+		if len(nodes) < 2 || nodes[0].DataLen() == 0 {
+			continue
+		}
+		paths := make([]string, 0, len(nodes))
+		for _, n := range nodes {
+			paths = append(paths, printablePath(n.Path))
+		}
+		sort.Strings(paths)
+		groups = append(groups, duplicateGroup{hash: hash, size: nodes[0].DataLen(), paths: paths})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		wi := groups[i].size * (len(groups[i].paths) - 1)
+		wj := groups[j].size * (len(groups[j].paths) - 1)
+		if wi != wj {
+			return wi > wj
+		}
+		return groups[i].hash < groups[j].hash
+	})
+	return groups
+}
+
+func (m *Model) openDuplicatesDialog() {
+	lines := []string{"Duplicate Content", ""}
+	groups := duplicateGroups(m.tree)
+	if len(groups) == 0 {
+		lines = append(lines, "No nodes share content.")
+	}
+	for i, g := range groups {
+		if i == maxDuplicateGroups {
+			lines = append(lines, fmt.Sprintf("... and %d more groups", len(groups)-maxDuplicateGroups))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s  %d bytes x %d nodes", g.hash, g.size, len(g.paths)))
+		for j, path := range g.paths {
+			if j == maxDuplicateMembers {
+				lines = append(lines, fmt.Sprintf("  ... and %d more", len(g.paths)-maxDuplicateMembers))
+				break
+			}
+			lines = append(lines, "  "+path)
+		}
+	}
+	lines = append(lines, "", "Press any key to close.")
+	m.duplicatesText = strings.Join(lines, "\n")
+	m.duplicatesOpen = true
+}
+
+func (m Model) renderDuplicatesDialog() string {
+	lines := strings.Split(m.duplicatesText, "\n")
+	for i := range lines {
+		lines[i] = truncate(lines[i], m.screenWidth()-8)
+	}
+	lines[0] = statsLabelStyle.Render(lines[0])
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+}
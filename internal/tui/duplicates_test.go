@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+func TestDuplicateGroupsListsSharedContent(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	for _, n := range []struct{ path, data string }{
+		{"/svc1", "shared-config"},
+		{"/svc2", "shared-config"},
+		{"/svc3", "shared-config"},
+		{"/unique", "one-off"},
+		{"/empty1", ""},
+		{"/empty2", ""},
+	} {
+		root.Children = append(root.Children, &snapshot.Node{ID: n.path[1:], Path: n.path, Parent: root, Data: []byte(n.data)})
+	}
+	tree := &snapshot.Tree{Root: root}
+
+	groups := duplicateGroups(tree)
+	if len(groups) != 1 {
+		t.Fatalf("expected one duplicate group, got %+v", groups)
+	}
+	g := groups[0]
+	if strings.Join(g.paths, ",") != "/svc1,/svc2,/svc3" || g.size != len("shared-config") {
+		t.Fatalf("unexpected group: %+v", g)
+	}
+	if g.hash != root.Children[0].ContentHash() {
+		t.Fatalf("expected group hash %q, got %q", root.Children[0].ContentHash(), g.hash)
+	}
+
+	var model tea.Model = NewModel(tree)
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B")})
+	text := model.(Model).duplicatesText
+	if !strings.Contains(text, g.hash+"  13 bytes x 3 nodes\n  /svc1\n  /svc2\n  /svc3") {
+		t.Fatalf("expected group in dialog, got: %q", text)
+	}
+	if strings.Contains(text, "/unique") {
+		t.Fatalf("expected unique node not listed, got: %q", text)
+	}
+}
+
+func TestDuplicatesDialogUsesTheScreenWidth(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	long := "/" + strings.Repeat("shared-config-", 8)
+	for _, path := range []string{"/svc1", long} {
+		root.Children = append(root.Children, &snapshot.Node{ID: path[1:], Path: path, Parent: root, Data: []byte("shared-config")})
+	}
+	var model tea.Model = NewModel(&snapshot.Tree{Root: root})
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B")})
+	if view := model.(Model).renderDuplicatesDialog(); !strings.Contains(view, long) {
+		t.Fatalf("expected the full %d-character path in the dialog, got:\n%s", len(long), view)
+	}
+}
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jowiho/zooxplorer/internal/format"
+)
+
+// openEncodingMenu lists the viable interpretations of the displayed node with
+// the one currently in use preselected.
+func (m *Model) openEncodingMenu() {
+	node := m.detailNode()
+	if node == nil {
+		return
+	}
+	m.encodingOptions = format.Interpretations(node.Bytes())
+	current := m.nodeEncoding(node)
+	m.encodingCursor = 0
+	for i, enc := range m.encodingOptions {
+		if enc == current {
+			m.encodingCursor = i
+		}
+	}
+	m.encodingMenuOpen = true
+}
+
+func (m Model) updateEncodingMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+q":
+		return m, tea.Quit
+	case "esc":
+		m.encodingMenuOpen = false
+	case "up":
+		if m.encodingCursor > 0 {
+			m.encodingCursor--
+		}
+	case "down":
+		if m.encodingCursor < len(m.encodingOptions)-1 {
+			m.encodingCursor++
+		}
+	case "enter":
+		m.encodingMenuOpen = false
+		if node := m.detailNode(); node != nil && m.encodingCursor < len(m.encodingOptions) {
+			enc := m.encodingOptions[m.encodingCursor]
+			if enc == format.DetectEncoding(node.Bytes()) {
+				delete(m.encodings, node)
+			} else {
+				m.encodings[node] = enc
+			}
+			m.reloadContent()
+		}
+	}
+	return m, nil
+}
+
+func (m Model) renderEncodingMenu() string {
+	lines := []string{statsLabelStyle.Render("Interpret content as"), ""}
+	detected := format.Encoding(-1)
+	if node := m.detailNode(); node != nil {
+		detected = format.DetectEncoding(node.Bytes())
+	}
+	for i, enc := range m.encodingOptions {
+		label := enc.String()
+		if enc == detected {
+			label += " (detected)"
+		}
+		if i == m.encodingCursor {
+			lines = append(lines, contentSelectionStyle.Render("> "+label))
+		} else {
+			lines = append(lines, "  "+label)
+		}
+	}
+	lines = append(lines, "", "Enter = apply | Esc = cancel")
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+}
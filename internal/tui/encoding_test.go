@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/format"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+func TestEncodingMenuSwitchesBase64JSONRendering(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a"].Data = []byte("eyJhIjoxfQ==") // {"a":1}
+	var model tea.Model = NewModel(tree)
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	typed := model.(Model)
+	if !typed.encodingMenuOpen {
+		t.Fatal("expected encodings menu to be open")
+	}
+	want := []format.Encoding{format.EncodingText, format.EncodingHex, format.EncodingBase64}
+	if len(typed.encodingOptions) != len(want) {
+		t.Fatalf("unexpected options: %v", typed.encodingOptions)
+	}
+	for i := range want {
+		if typed.encodingOptions[i] != want[i] {
+			t.Fatalf("unexpected options: %v", typed.encodingOptions)
+		}
+	}
+	if typed.encodingOptions[typed.encodingCursor] != format.EncodingBase64 {
+		t.Fatalf("expected detected base64 encoding preselected, got %v", typed.encodingOptions[typed.encodingCursor])
+	}
+	if !strings.Contains(typed.View(), "Base64-decoded (detected)") {
+		t.Fatal("expected menu to mark the detected encoding")
+	}
+	if got := stripANSI(strings.Join(typed.contentLines, "\n")); got != "(decoded from base64)\n{\n  \"a\": 1\n}" {
+		t.Fatalf("expected base64-decoded JSON content, got %q", got)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	typed = model.(Model)
+	if typed.encodingMenuOpen {
+		t.Fatal("expected menu closed after enter")
+	}
+	if got := stripANSI(strings.Join(typed.contentLines, "\n")); got != "eyJhIjoxfQ==" {
+		t.Fatalf("expected raw text content, got %q", got)
+	}
+
+	// The choice sticks to the node across navigation.
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	typed = model.(Model)
+	if got := stripANSI(strings.Join(typed.contentLines, "\n")); got != "eyJhIjoxfQ==" {
+		t.Fatalf("expected encoding choice to persist, got %q", got)
+	}
+}
+
+func TestEncodingMenuEscKeepsContent(t *testing.T) {
+	m := NewModel(&snapshot.Tree{Root: &snapshot.Node{ID: "/", Path: "", Children: []*snapshot.Node{{ID: "x", Path: "/x", Data: []byte("plain")}}}})
+	var model tea.Model = m
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	typed := model.(Model)
+	if typed.encodingMenuOpen || strings.Join(typed.contentLines, "\n") != "plain" {
+		t.Fatalf("expected esc to close menu without changes, got %q", typed.contentLines)
+	}
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// externalDoneMsg reports that a pager or editor opened on node content has
+// exited. tempFile, if set, is removed.
+type externalDoneMsg struct {
+	err      error
+	tempFile string
+}
+
+// commandFromEnv splits the command in environment variable name, falling
+// back to fallback when it is unset.
+func commandFromEnv(name string, fallback ...string) []string {
+	if args := strings.Fields(os.Getenv(name)); len(args) > 0 {
+		return args
+	}
+	return fallback
+}
+
+// pagerCmd pipes content into $PAGER, or less -R.
+func pagerCmd(content string) *exec.Cmd {
+	args := commandFromEnv("PAGER", "less", "-R")
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(content + "\n")
+	return cmd
+}
+
+// editorCmd writes content to a read-only temp file and opens it in $EDITOR,
+// or vi. The caller removes the file.
+func editorCmd(content, name string) (*exec.Cmd, string, error) {
+	f, err := os.CreateTemp("", "zooxplorer-*-"+name)
+	if err != nil {
+		return nil, "", err
+	}
+	path := f.Name()
+	if _, err := f.WriteString(content + "\n"); err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return nil, "", err
+	}
+	if err := os.Chmod(path, 0o444); err != nil {
+		os.Remove(path)
+		return nil, "", err
+	}
+	args := commandFromEnv("EDITOR", "vi")
+	return exec.Command(args[0], append(args[1:], path)...), path, nil
+}
+
+// openInPager shows the selected node's content, highlighted as in the
+// content pane, in the pager while the program is suspended.
+func (m *Model) openInPager() tea.Cmd {
+	return tea.ExecProcess(pagerCmd(m.formattedContent(m.selected)), func(err error) tea.Msg {
+		return externalDoneMsg{err: err}
+	})
+}
+
+// openInEditor opens the selected node's decoded content in the editor for
+// viewing; changes cannot be saved back.
+func (m *Model) openInEditor() tea.Cmd {
+	cmd, path, err := editorCmd(m.plainContent(m.selected), contentFileName(m.selected))
+	if err != nil {
+		return m.flashHint("editor failed: " + err.Error())
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return externalDoneMsg{err: err, tempFile: path}
+	})
+}
+
+// finishExternal cleans up after a pager or editor and reports its failure.
+func (m *Model) finishExternal(msg externalDoneMsg) tea.Cmd {
+	if msg.tempFile != "" {
+		os.Remove(msg.tempFile)
+	}
+	if msg.err != nil {
+		return m.flashHint("external viewer failed: " + msg.err.Error())
+	}
+	return nil
+}
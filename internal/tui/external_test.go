@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPagerCmdUsesEnvironment(t *testing.T) {
+	t.Setenv("PAGER", "")
+	cmd := pagerCmd("content")
+	if want := []string{"less", "-R"}; !reflect.DeepEqual(cmd.Args, want) {
+		t.Fatalf("expected default pager %v, got %v", want, cmd.Args)
+	}
+	if in, _ := io.ReadAll(cmd.Stdin); string(in) != "content\n" {
+		t.Fatalf("expected content on stdin, got %q", in)
+	}
+
+	t.Setenv("PAGER", "more -s")
+	if got := pagerCmd("").Args; !reflect.DeepEqual(got, []string{"more", "-s"}) {
+		t.Fatalf("expected $PAGER with its arguments, got %v", got)
+	}
+}
+
+func TestEditorCmdOpensReadOnlyTempFile(t *testing.T) {
+	t.Setenv("EDITOR", "code --wait")
+	cmd, path, err := editorCmd("hello", "leader")
+	if err != nil {
+		t.Fatalf("editorCmd() error = %v", err)
+	}
+	defer os.Remove(path)
+	if want := []string{"code", "--wait", path}; !reflect.DeepEqual(cmd.Args, want) {
+		t.Fatalf("expected %v, got %v", want, cmd.Args)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0o222 != 0 {
+		t.Fatalf("expected a read-only file, got %v (%v)", info.Mode(), err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "hello\n" {
+		t.Fatalf("unexpected file content %q", data)
+	}
+
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	model, cmdOut := model.Update(externalDoneMsg{err: errors.New("exit status 1"), tempFile: path})
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("expected the temp file removed")
+	}
+	if got := model.(Model).keyHint; got != "external viewer failed: exit status 1" || cmdOut == nil {
+		t.Fatalf("expected a failure hint, got %q", got)
+	}
+}
+
+func TestEnterOpensPagerOnlyFromContentPane(t *testing.T) {
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Fatal("expected enter in the tree to do nothing")
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil {
+		t.Fatal("expected enter in the content pane to run the pager")
+	}
+}
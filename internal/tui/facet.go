@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// facetPathPrefix marks the paths of facet groups, which cannot clash with
+// znode paths since those start with "/".
+const facetPathPrefix = "facet:"
+
+// buildFacetRoot returns a pseudo-root whose children are groups named after
+// the distinct values of path segment `segment` (1-based). Each group lists
+// the nodes at that depth with that name; they keep their real subtrees.
+func buildFacetRoot(root *snapshot.Node, segment int) *snapshot.Node {
+	facetRoot := &snapshot.Node{ID: "/", Path: ""}
+	groups := make(map[string]*snapshot.Node)
+	var walk func(n *snapshot.Node, depth int)
+	walk = func(n *snapshot.Node, depth int) {
+		if depth == segment {
+			group, ok := groups[n.ID]
+			if !ok {
+				group = &snapshot.Node{ID: n.ID, Path: facetPathPrefix + n.ID, Parent: facetRoot}
+				groups[n.ID] = group
+				facetRoot.Children = append(facetRoot.Children, group)
+			}
+			group.Children = append(group.Children, n)
+			return
+		}
+		for _, child := range n.Children {
+			walk(child, depth+1)
+		}
+	}
+	for _, child := range root.Children {
+		walk(child, 1)
+	}
+	return facetRoot
+}
+
+// displayRoot is the root of the tree pane: the snapshot root, or the facet
+// groups while faceting.
+func (m Model) displayRoot() *snapshot.Node {
+	if m.facetRoot != nil {
+		return m.facetRoot
+	}
+	return m.tree.Root
+}
+
+// setFacet groups the tree by path segment `segment`; zero shows the plain
+// tree again.
+func (m *Model) setFacet(segment int) {
+	if m.tree == nil || m.tree.Root == nil {
+		return
+	}
+	m.facetSegment = segment
+	m.facetRoot = nil
+	if segment > 0 {
+		m.facetRoot = buildFacetRoot(m.tree.Root, segment)
+	}
+	m.refreshRows()
+	m.adjustTreeOffset()
+}
+
+func (m *Model) openFacetPrompt() {
+	input := ""
+	if m.facetSegment > 0 {
+		input = strconv.Itoa(m.facetSegment)
+	}
+	m.prompt = &prompt{
+		title: "Group by path segment (1 = first component; empty = off)",
+		label: "Segment: ",
+		input: input,
+		submit: func(m *Model, input string) error {
+			segment := 0
+			if input != "" {
+				n, err := strconv.Atoi(input)
+				if err != nil || n < 0 {
+					return fmt.Errorf("invalid segment %q", input)
+				}
+				segment = n
+			}
+			m.setFacet(segment)
+			return nil
+		},
+	}
+}
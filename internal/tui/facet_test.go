@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// facetTree builds /<env>/<region> nodes for two environments.
+func facetTree() *snapshot.Tree {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	for _, env := range []string{"prod", "dev"} {
+		e := &snapshot.Node{ID: env, Path: "/" + env, Parent: root}
+		for _, region := range []string{"us", "eu"} {
+			e.Children = append(e.Children, &snapshot.Node{ID: region, Path: e.Path + "/" + region, Parent: e})
+		}
+		root.Children = append(root.Children, e)
+	}
+	return &snapshot.Tree{Root: root}
+}
+
+func TestFacetBySegmentGroupsByPathComponent(t *testing.T) {
+	tree := facetTree()
+
+	byFirst := buildFacetRoot(tree.Root, 1)
+	if len(byFirst.Children) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(byFirst.Children))
+	}
+	for _, group := range byFirst.Children {
+		if len(group.Children) != 1 || group.Children[0].Path != "/"+group.ID {
+			t.Fatalf("expected group %q to hold /%s, got %v", group.ID, group.ID, group.Children)
+		}
+	}
+
+	byRegion := buildFacetRoot(tree.Root, 2)
+	got := map[string][]string{}
+	for _, group := range byRegion.Children {
+		for _, member := range group.Children {
+			got[group.ID] = append(got[group.ID], member.Path)
+		}
+	}
+	if strings.Join(got["us"], ",") != "/prod/us,/dev/us" || strings.Join(got["eu"], ",") != "/prod/eu,/dev/eu" {
+		t.Fatalf("unexpected region groups: %v", got)
+	}
+}
+
+func TestFacetPromptRegroupsTree(t *testing.T) {
+	var model tea.Model = NewModel(facetTree())
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	typed := model.(Model)
+
+	var ids []string
+	for _, r := range typed.rows {
+		ids = append(ids, r.Node.ID)
+	}
+	if strings.Join(ids, ",") != "eu,us" {
+		t.Fatalf("expected region groups as rows, got %v", ids)
+	}
+	if typed.rows[0].Node.SubtreeSize() != 0 || len(typed.rows[0].Node.Children) != 2 {
+		t.Fatal("expected group row to count its two members")
+	}
+
+	typed.setFacet(0)
+	ids = ids[:0]
+	for _, r := range typed.rows {
+		ids = append(ids, r.Node.ID)
+	}
+	if strings.Join(ids, ",") != "dev,prod" || typed.selectedRowIndex() == -1 {
+		t.Fatalf("expected plain tree with a visible selection, got %v", ids)
+	}
+}
@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyBinding documents one key, or keys sharing an action, in the help
+// overlay.
+type keyBinding struct {
+	keys        string
+	description string
+}
+
+// keyBindingSection groups related bindings under a heading.
+type keyBindingSection struct {
+	title    string
+	bindings []keyBinding
+}
+
+// keyBindings lists every key the model handles outside of dialogs. Keep it
+// in sync with Update; the help overlay is generated from it.
+var keyBindings = []keyBindingSection{
+	{title: "Navigation", bindings: []keyBinding{
+		{"Up/Down", "Move the selection, or scroll the content pane"},
+		{"PgUp/PgDn", "Move one page in the tree"},
+		{"Home/End", "Jump to the first/last row"},
+		{"Left/Right", "Collapse/expand the selected node"},
+		{"Shift+Right, *", "Expand the selected node's whole subtree"},
+		{"C", "Collapse everything below the selection"},
+		{"Alt+Up", "Jump to the parent node"},
+		{"g", "Jump to a typed path"},
+		{"1-9", "Jump to the Nth child"},
+		{"[, ]", "Go back/forward through the nodes jumped between"},
+		{"Tab", "Switch focus between tree and content"},
+		{"0", "Peek at the root node"},
+		{"w", "Wrap long node names"},
+		{"#", "Show the number of visible nodes"},
+		{"G", "Group the tree by a path segment"},
+		{"/", "Filter the tree by a path regex"},
+		{"M", "List only nodes of at least a data size"},
+		{"Ctrl+F", "Search nodes, or content when focused"},
+		{"F", "Find all nodes whose data contains a text"},
+		{"n, N", "Select the next/previous node found by F"},
+	}},
+	{title: "Content", bindings: []keyBinding{
+		{"e", "Choose how to interpret the data"},
+		{"z", "Wrap long content lines"},
+		{"Left/Right", "Scroll long content lines sideways (content focused)"},
+		{"t", "Show raw epoch millis next to timestamps"},
+		{"T", "Cycle absolute and relative timestamps"},
+		{"Z", "Switch timestamps between UTC and local time"},
+		{"H", "Show the full user:hash of digest ACLs"},
+		{"o", "Filter to nodes of the same session"},
+		{"Esc", "Clear the filter, or the nodes found by F"},
+		{"c", "Copy the decoded content (Y copies the path)"},
+		{"s", "Save the decoded content to a file"},
+		{"Enter", "Open the content in $PAGER (content focused)"},
+		{"E", "Open the content in $EDITOR"},
+		{"Ctrl+A", "Select all content"},
+		{"Ctrl+C", "Copy the selected content"},
+		{"p", "Pin the selected node"},
+		{"d", "Diff the pinned node with the selected one"},
+	}},
+	{title: "Sorting and columns", bindings: []keyBinding{
+		{"Ctrl+O", "Switch to the next sort column"},
+		{"Ctrl+R", "Reverse the sort order"},
+		{"x", "Show the mzxid column"},
+		{"U", "Cycle size units"},
+		{"W", "Set the size warning threshold"},
+	}},
+	{title: "Other", bindings: []keyBinding{
+		{"?", "Show this help"},
+		{"r, F5", "Reload the snapshot"},
+		{"Ctrl+S", "Snapshot statistics"},
+		{"A", "Audit report"},
+		{"B", "Nodes with identical content"},
+		{"S", "Session expiry projection"},
+		{"L", "Tree legend of markers and colors"},
+		{"y", "Copy a command line opening this node"},
+		{"Y", "Copy the selected path"},
+		{"Ctrl+Q", "Quit"},
+	}},
+}
+
+// helpLines renders keyBindings as one column of section headings and
+// aligned bindings.
+func helpLines() []string {
+	width := 0
+	for _, section := range keyBindings {
+		for _, b := range section.bindings {
+			if w := lipgloss.Width(b.keys); w > width {
+				width = w
+			}
+		}
+	}
+	var lines []string
+	for i, section := range keyBindings {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, statsLabelStyle.Render(section.title))
+		for _, b := range section.bindings {
+			lines = append(lines, fmt.Sprintf("%-*s  %s", width, b.keys, b.description))
+		}
+	}
+	return lines
+}
+
+// renderHelpDialog lays the bindings out in as many columns as it takes to
+// fit height rows, breaking columns between sections where possible.
+func (m Model) renderHelpDialog(height int) string {
+	// Border, padding, title and footer take eight rows.
+	rows := height - 8
+	if rows < 10 {
+		rows = 10
+	}
+	lines := helpLines()
+	var columns []string
+	for len(lines) > 0 {
+		n := len(lines)
+		if n > rows {
+			n = rows
+			for n > rows/2 && lines[n-1] != "" {
+				n--
+			}
+			if n == rows/2 {
+				n = rows
+			}
+		}
+		columns = append(columns, strings.Join(lines[:n], "\n"))
+		lines = lines[n:]
+		for len(lines) > 0 && lines[0] == "" {
+			lines = lines[1:]
+		}
+		if len(lines) > 0 {
+			columns = append(columns, "    ")
+		}
+	}
+	body := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2).
+		Render(statsLabelStyle.Render("Key Bindings") + "\n\n" + body + "\n\nPress any key to close.")
+}
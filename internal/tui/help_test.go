@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHelpKeyOpensAndAnyKeyCloses(t *testing.T) {
+	var model tea.Model = NewModel(sampleSnapshotTree())
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	typed := model.(Model)
+	if !typed.helpOpen {
+		t.Fatal("expected help to be open")
+	}
+	view := stripANSI(typed.View())
+	for _, section := range keyBindings {
+		for _, b := range section.bindings {
+			if !strings.Contains(view, b.description) {
+				t.Fatalf("expected help to list %q, got:\n%s", b.description, view)
+			}
+		}
+	}
+
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyDown})
+	typed = model.(Model)
+	if typed.helpOpen {
+		t.Fatal("expected help to be closed")
+	}
+	if typed.selected.Path != "/a" {
+		t.Fatalf("expected selection unchanged when closing help, got %q", typed.selected.Path)
+	}
+}
+
+// helpKeys maps the names of special keys in the help to the messages
+// bubbletea sends for them.
+var helpKeys = map[string]tea.KeyMsg{
+	"Up":          {Type: tea.KeyUp},
+	"Down":        {Type: tea.KeyDown},
+	"PgUp":        {Type: tea.KeyPgUp},
+	"PgDn":        {Type: tea.KeyPgDown},
+	"Home":        {Type: tea.KeyHome},
+	"End":         {Type: tea.KeyEnd},
+	"Left":        {Type: tea.KeyLeft},
+	"Right":       {Type: tea.KeyRight},
+	"Shift+Right": {Type: tea.KeyShiftRight},
+	"Alt+Up":      {Type: tea.KeyUp, Alt: true},
+	"Tab":         {Type: tea.KeyTab},
+	"Esc":         {Type: tea.KeyEsc},
+	"Enter":       {Type: tea.KeyEnter},
+	"F5":          {Type: tea.KeyF5},
+	"Ctrl+A":      {Type: tea.KeyCtrlA},
+	"Ctrl+C":      {Type: tea.KeyCtrlC},
+	"Ctrl+F":      {Type: tea.KeyCtrlF},
+	"Ctrl+O":      {Type: tea.KeyCtrlO},
+	"Ctrl+Q":      {Type: tea.KeyCtrlQ},
+	"Ctrl+R":      {Type: tea.KeyCtrlR},
+	"Ctrl+S":      {Type: tea.KeyCtrlS},
+}
+
+// helpKeyMsgs returns the key messages for a help entry such as "n, N",
+// "Up/Down" or "1-9".
+func helpKeyMsgs(t *testing.T, keys string) []tea.KeyMsg {
+	var msgs []tea.KeyMsg
+	for _, name := range strings.Split(keys, ", ") {
+		names := []string{name}
+		if len(name) > 1 && strings.Contains(name, "/") {
+			names = strings.Split(name, "/")
+		} else if len(name) == 3 && name[1] == '-' {
+			names = nil
+			for r := name[0]; r <= name[2]; r++ {
+				names = append(names, string(r))
+			}
+		}
+		for _, n := range names {
+			if len([]rune(n)) == 1 {
+				msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(n)})
+				continue
+			}
+			msg, ok := helpKeys[n]
+			if !ok {
+				t.Fatalf("help lists %q, which helpKeys does not know", n)
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+func TestHelpListsOnlyBoundKeys(t *testing.T) {
+	t.Chdir(t.TempDir()) // s saves the selected content
+	for _, section := range keyBindings {
+		for _, b := range section.bindings {
+			for _, msg := range helpKeyMsgs(t, b.keys) {
+				model, _ := NewModel(sampleSnapshotTree()).Update(msg)
+				if hint := model.(Model).keyHint; strings.HasPrefix(hint, "Unknown key") {
+					t.Fatalf("help lists %q for %q, but %s is not bound", b.keys, b.description, msg)
+				}
+			}
+		}
+	}
+}
+
+func TestHelpSplitsIntoColumnsToFitHeight(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	tall := m.renderHelpDialog(200)
+	short := m.renderHelpDialog(30)
+	if h := strings.Count(short, "\n") + 1; h > 30 {
+		t.Fatalf("expected help to fit 30 rows, got %d", h)
+	}
+	if strings.Count(short, "\n") >= strings.Count(tall, "\n") {
+		t.Fatal("expected a short terminal to get more columns")
+	}
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// historyLimit caps how many selections the back/forward history keeps.
+const historyLimit = 100
+
+// jumpTo selects node like selectNode, remembering both the spot it leaves
+// and the one it lands on so [ and ] can step between them. Single-step
+// moves select nodes directly and leave no history.
+func (m *Model) jumpTo(node *snapshot.Node) {
+	if node == nil {
+		return
+	}
+	m.recordHistory()
+	m.selectNode(node)
+	m.recordHistory()
+}
+
+// recordHistory makes the selection the current history entry. A selection
+// other than the current entry drops the entries ahead of it, as a browser
+// does when following a link after going back.
+func (m *Model) recordHistory() {
+	if m.selected == nil {
+		return
+	}
+	if len(m.history) > 0 {
+		if m.history[m.historyIndex] == m.selected {
+			return
+		}
+		m.history = m.history[:m.historyIndex+1]
+	}
+	m.history = append(m.history, m.selected)
+	if len(m.history) > historyLimit {
+		m.history = m.history[len(m.history)-historyLimit:]
+	}
+	m.historyIndex = len(m.history) - 1
+}
+
+// stepHistory selects the previous (delta -1) or next (delta 1) history
+// entry the tree still has, skipping nodes a reload removed.
+func (m *Model) stepHistory(delta int) tea.Cmd {
+	m.recordHistory()
+	for i := m.historyIndex + delta; i >= 0 && i < len(m.history); i += delta {
+		node := m.history[i]
+		if node == nil || m.tree == nil || m.tree.NodesByPath[node.Path] != node {
+			continue
+		}
+		m.historyIndex = i
+		if m.filter != nil && !m.filter.keep(node) {
+			m.clearFilter()
+		}
+		m.selectNode(node)
+		m.centerSelectedRowInTree()
+		return nil
+	}
+	if delta < 0 {
+		return m.flashHint("no earlier node in history")
+	}
+	return m.flashHint("no later node in history")
+}
+
+// historyPaths returns the paths of the history entries, with "" for the
+// ones a reload removed.
+func (m Model) historyPaths() []string {
+	if len(m.history) == 0 {
+		return nil
+	}
+	paths := make([]string, len(m.history))
+	for i, node := range m.history {
+		if node != nil {
+			paths[i] = node.Path
+		}
+	}
+	return paths
+}
+
+// restoreHistory looks paths up in tree, keeping a nil entry for each node it
+// no longer has so the position in the history is unchanged.
+func (m *Model) restoreHistory(tree *snapshot.Tree, paths []string, index int) {
+	if len(paths) == 0 || index < 0 || index >= len(paths) {
+		return
+	}
+	m.history = make([]*snapshot.Node, len(paths))
+	for i, path := range paths {
+		if node := tree.NodesByPath[path]; node != nil && node != tree.Root {
+			m.history[i] = node
+		}
+	}
+	m.historyIndex = index
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func jumpKeys(model tea.Model, path string) tea.Model {
+	model = typeKeys(model, "g"+path)
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	return model
+}
+
+func TestHistoryStepsBackAndForwardThroughJumps(t *testing.T) {
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	model = jumpKeys(model, "/a/a1")
+	model = jumpKeys(model, "/b")
+
+	model = typeKeys(model, "[")
+	if got := model.(Model).selected.Path; got != "/a/a1" {
+		t.Fatalf("expected [ to go back to /a/a1, got %q", got)
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlLeft})
+	if got := model.(Model).selected.Path; got != "/a" {
+		t.Fatalf("expected ctrl+left to go back to /a, got %q", got)
+	}
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("[")})
+	if cmd == nil || model.(Model).keyHint != "no earlier node in history" || model.(Model).selected.Path != "/a" {
+		t.Fatalf("expected a hint at the start of the history, got %q", model.(Model).keyHint)
+	}
+
+	model = typeKeys(model, "]]")
+	if got := model.(Model).selected.Path; got != "/b" {
+		t.Fatalf("expected ]] to go forward to /b, got %q", got)
+	}
+}
+
+func TestHistoryIgnoresArrowMovesButKeepsTheirSpot(t *testing.T) {
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if len(model.(Model).history) != 0 {
+		t.Fatal("expected an arrow move to leave no history")
+	}
+
+	model = jumpKeys(model, "/a/a1")
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	from := model.(Model).selected.Path
+	model = typeKeys(model, "[")
+	if got := model.(Model).selected.Path; got != "/a/a1" {
+		t.Fatalf("expected [ to return to the jump target first, got %q", got)
+	}
+	model = typeKeys(model, "]")
+	if got := model.(Model).selected.Path; got != from {
+		t.Fatalf("expected ] to return to %q, got %q", from, got)
+	}
+}
+
+func TestHistorySkipsNodesRemovedByReload(t *testing.T) {
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	model = jumpKeys(model, "/a/a1")
+	model = jumpKeys(model, "/b")
+	state := model.(Model).ViewState()
+
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a"].Children = nil
+	delete(tree.NodesByPath, "/a/a1")
+	model = NewModelWithOptions(tree, Options{State: &state})
+	if got := model.(Model).selected.Path; got != "/b" {
+		t.Fatalf("expected the selection restored, got %q", got)
+	}
+	model = typeKeys(model, "[")
+	if got := model.(Model).selected.Path; got != "/a" {
+		t.Fatalf("expected [ to skip the removed node, got %q", got)
+	}
+}
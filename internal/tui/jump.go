@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+)
+
+// openJumpPrompt asks for the absolute path of a node to select.
+func (m *Model) openJumpPrompt() {
+	m.prompt = &prompt{
+		title: "Jump to node (absolute path, e.g. /services/foo/leader)",
+		label: "Path: ",
+		submit: func(m *Model, input string) error {
+			return m.jumpToPath(input)
+		},
+	}
+}
+
+// jumpToPath selects the node at path, expanding its ancestors and clearing
+// a filter that hides it.
+func (m *Model) jumpToPath(path string) error {
+	if m.tree == nil || path == "" {
+		return errors.New("enter a path")
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	node := m.tree.NodesByPath[path]
+	if node == nil {
+		return errors.New("no such node: " + path)
+	}
+	if node == m.tree.Root {
+		return errors.New("the root node is hidden; press 0 to peek at it")
+	}
+	if m.filter != nil && !m.filter.keep(node) {
+		m.clearFilter()
+	}
+	m.jumpTo(node)
+	m.centerSelectedRowInTree()
+	return nil
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func typeKeys(model tea.Model, text string) tea.Model {
+	for _, r := range text {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return model
+}
+
+func TestJumpToPathSelectsAndExpands(t *testing.T) {
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	model = typeKeys(model, "g")
+	if model.(Model).prompt == nil {
+		t.Fatal("expected g to open the jump prompt")
+	}
+	model = typeKeys(model, "/a/a1/")
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	typed := model.(Model)
+	if typed.prompt != nil || typed.selected.Path != "/a/a1" {
+		t.Fatalf("expected /a/a1 selected, got %q", typed.selected.Path)
+	}
+	if !typed.expanded["/a"] || typed.selectedRowIndex() < 0 {
+		t.Fatal("expected the ancestors expanded and the node visible")
+	}
+}
+
+func TestJumpToUnknownPathKeepsPromptOpen(t *testing.T) {
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	model = typeKeys(model, "g/nope")
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	typed := model.(Model)
+	if typed.prompt == nil || typed.prompt.message != "no such node: /nope" {
+		t.Fatalf("expected an inline error, got %+v", typed.prompt)
+	}
+	if !strings.Contains(typed.renderPrompt(80), "no such node") {
+		t.Fatal("expected the error in the dialog")
+	}
+	if typed.selected.Path != "/a" {
+		t.Fatalf("expected the selection unchanged, got %q", typed.selected.Path)
+	}
+}
+
+func TestJumpToPathClearsHidingFilter(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	m.selectNode(m.tree.NodesByPath["/b"])
+	m.toggleSessionFilter()
+	if err := m.jumpToPath("a"); err != nil {
+		t.Fatalf("jumpToPath() error = %v", err)
+	}
+	if m.filter != nil || m.selected.Path != "/a" {
+		t.Fatalf("expected the filter cleared and /a selected, got %q", m.selected.Path)
+	}
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyHintTimeout is how long a transient hint, such as the one about an
+// unbound key, stays in the status bar unless another key clears it first.
+const keyHintTimeout = 3 * time.Second
+
+// keyHintExpiredMsg ends the hint with the given sequence number; later hints
+// have replaced older ones.
+type keyHintExpiredMsg struct {
+	seq int
+}
+
+// showKeyHint tells the user that key is not bound to anything, pointing at
+// the help, and returns the command that clears the hint again.
+func (m *Model) showKeyHint(key string) tea.Cmd {
+	return m.flashHint(fmt.Sprintf("Unknown key %q — press ? for help", key))
+}
+
+// flashHint shows text in the status bar for keyHintTimeout and returns the
+// command that clears it again.
+func (m *Model) flashHint(text string) tea.Cmd {
+	m.keyHintSeq++
+	m.keyHint = text
+	seq := m.keyHintSeq
+	return tea.Tick(keyHintTimeout, func(time.Time) tea.Msg {
+		return keyHintExpiredMsg{seq: seq}
+	})
+}
+
+// copyWithHint copies text to the clipboard and flashes "copied <what>", or
+// that the clipboard is unavailable.
+func (m *Model) copyWithHint(text, what string) tea.Cmd {
+	if m.copyContent == nil || m.copyContent(text) != nil {
+		return m.flashHint("clipboard unavailable")
+	}
+	return m.flashHint("copied " + what)
+}
@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestUnknownKeyShowsTransientHint(t *testing.T) {
+	var model tea.Model = NewModel(sampleSnapshotTree())
+
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	typed := model.(Model)
+	if typed.keyHint != `Unknown key "j" — press ? for help` || cmd == nil {
+		t.Fatalf("expected a hint with a timer, got %q", typed.keyHint)
+	}
+	if !strings.Contains(typed.renderStatusBar(200), `Unknown key "j" — press ? for help`) {
+		t.Fatal("expected the hint in the status bar")
+	}
+
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	model, _ = model.Update(keyHintExpiredMsg{seq: 1})
+	if model.(Model).keyHint == "" {
+		t.Fatal("expected an outdated timer to keep the newer hint")
+	}
+
+	model, cmd = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if typed = model.(Model); typed.keyHint != "" || !onlyPrefetch(cmd) {
+		t.Fatalf("expected a bound key to clear the hint, got %q", typed.keyHint)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	model, _ = model.Update(keyHintExpiredMsg{seq: model.(Model).keyHintSeq})
+	if model.(Model).keyHint != "" {
+		t.Fatal("expected the timer to clear the hint")
+	}
+}
@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func legendText() string {
+	width := 0
+	for _, marker := range treeMarkers {
+		if w := lipgloss.Width(marker.glyph); w > width {
+			width = w
+		}
+	}
+	lines := []string{"Tree Legend", ""}
+	for _, marker := range treeMarkers {
+		lines = append(lines, fmt.Sprintf("%-*s  %s", width, marker.glyph, marker.description))
+	}
+	lines = append(lines, "")
+	for _, color := range treeColors {
+		lines = append(lines, color.style.Render("name")+"  "+color.description)
+	}
+	lines = append(lines, "", "Press any key to close.")
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) renderLegendDialog() string {
+	lines := strings.Split(legendText(), "\n")
+	lines[0] = statsLabelStyle.Render(lines[0])
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLegendListsEveryMarker(t *testing.T) {
+	legend := legendText()
+	for _, marker := range treeMarkers {
+		if !strings.Contains(legend, marker.glyph+"  "+marker.description) {
+			t.Fatalf("expected legend entry for %q, got:\n%s", marker.glyph, legend)
+		}
+	}
+}
+
+func TestLegendExplainsNameColors(t *testing.T) {
+	legend := legendText()
+	for _, color := range treeColors {
+		if !strings.Contains(legend, color.style.Render("name")+"  "+color.description) {
+			t.Fatalf("expected legend entry for %q, got:\n%s", color.description, legend)
+		}
+	}
+}
+
+func TestLegendKeyOpensAndAnyKeyCloses(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	var model tea.Model = m
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	typed := model.(Model)
+	if !typed.legendOpen {
+		t.Fatal("expected legend to be open")
+	}
+	if !strings.Contains(typed.View(), "Tree Legend") {
+		t.Fatal("expected legend to be rendered")
+	}
+
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyDown})
+	typed = model.(Model)
+	if typed.legendOpen {
+		t.Fatal("expected legend to be closed")
+	}
+	if typed.selected.Path != "/a" {
+		t.Fatalf("expected selection unchanged when closing legend, got %q", typed.selected.Path)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -14,6 +15,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jowiho/zooxplorer/internal/format"
+	"github.com/jowiho/zooxplorer/internal/policy"
 	"github.com/jowiho/zooxplorer/internal/snapshot"
 )
 
@@ -22,7 +24,7 @@ type focusPane int
 const (
 	focusTree focusPane = iota
 	focusContent
-	metadataInnerHeight = 5
+	metadataInnerHeight = 6
 )
 
 type searchScope int
@@ -47,25 +49,68 @@ var metadataPathStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Bol
 var statsLabelStyle = lipgloss.NewStyle().Bold(true)
 var statusBarStyle = lipgloss.NewStyle().Reverse(true)
 var statusKeyStyle = lipgloss.NewStyle().Reverse(true).Bold(true)
+var statusWarningStyle = lipgloss.NewStyle().Reverse(true).Bold(true).Foreground(lipgloss.Color("196"))
 var contentSelectionStyle = lipgloss.NewStyle().Reverse(true)
 var searchMatchStyle = lipgloss.NewStyle().Background(lipgloss.Color("226")).Foreground(lipgloss.Color("0"))
 var searchInputStyle = lipgloss.NewStyle().Reverse(true)
 var ansiEscapeRE = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
 
 type Model struct {
+	snapshotPath          string
 	tree                  *snapshot.Tree
 	selected              *snapshot.Node
+	history               []*snapshot.Node
+	historyIndex          int
 	rows                  []row
 	rowIndex              map[*snapshot.Node]int
-	metrics               map[*snapshot.Node]treeMetrics
 	sortOrder             sortColumn
-	sortDesc              [5]bool
+	sortDesc              [sortColumnCount]bool
 	expanded              map[string]bool
+	filter                *treeFilter
+	unfilteredExpanded    map[string]bool
+	minDataSize           int
 	treeOffset            int
 	contentOffset         int
+	contentHScroll        int
 	contentLines          []string
+	contentSize           string
+	contentClass          string
+	contentTitle          string
+	wrapContent           bool
+	showEpoch             bool
+	times                 timeFormatter
+	sizeWarning           int
+	prompt                *prompt
+	auditOpen             bool
+	duplicatesOpen        bool
+	duplicatesText        string
+	sessionsOpen          bool
+	sessionsText          string
+	keyHint               string
+	warning               string
+	reloading             bool
+	keyHintSeq            int
+	showRowCount          bool
+	wrapNames             bool
+	sizeUnit              sizeUnit
+	showMzxid             bool
+	showDigests           bool
+	baselineDiff          *snapshot.BaselineDiff
+	baselineMarks         map[*snapshot.Node]string
+	totalNodes            int
+	facetSegment          int
+	facetRoot             *snapshot.Node
+	violations            []policy.Violation
+	violating             map[*snapshot.Node]bool
+	pinned                *snapshot.Node
+	diffPinned            bool
+	displayLines          []string
+	displaySource         []int
 	contentNode           *snapshot.Node
 	contentSelect         bool
+	content               *contentCache
+	rowCache              *rowCache
+	rowsGeneration        uint64
 	copyContent           func(string) error
 	searchOpen            bool
 	searchScope           searchScope
@@ -81,29 +126,79 @@ type Model struct {
 	matchNode             *snapshot.Node
 	nodeMatchQuery        string
 	nodeMatchNode         *snapshot.Node
+	searchTexts           *searchTextCache
+	compression           *compressionCache
+	dataSearch            *dataSearch
+	dataSearchSeq         int
+	dataMatches           []*snapshot.Node
+	dataMatchQuery        string
+	dataMatchIndex        int
 	focus                 focusPane
 	statsOpen             bool
 	statsText             string
+	statsOffset           int
+	legendOpen            bool
+	helpOpen              bool
+	peekRoot              bool
+	encodings             map[*snapshot.Node]format.Encoding
+	encodingMenuOpen      bool
+	encodingOptions       []format.Encoding
+	encodingCursor        int
 	width                 int
 	height                int
 }
 
+// Options configures a Model beyond the tree it shows.
+type Options struct {
+	// SnapshotPath is the file the tree was parsed from, used in deep links.
+	SnapshotPath string
+	// StartPath is the path of the node selected initially. Unknown paths
+	// are ignored.
+	StartPath string
+	// Policy, if set, is checked against the node ACLs; violations are
+	// marked in the tree and listed in the audit report.
+	Policy *policy.Policy
+	// Baseline, if set, is compared with the tree; added and changed nodes
+	// are marked in the tree and removed ones listed in the audit report.
+	Baseline *snapshot.Baseline
+	// Warning is shown in the status bar for as long as the tree is open,
+	// e.g. when only part of the snapshot could be read.
+	Warning string
+	// State, if set, restores where the user was when last viewing the
+	// snapshot. StartPath takes precedence over its selection.
+	State *ViewState
+}
+
 func NewModel(tree *snapshot.Tree) Model {
+	return NewModelWithOptions(tree, Options{})
+}
+
+func NewModelWithOptions(tree *snapshot.Tree, opts Options) Model {
 	m := Model{
-		tree:      tree,
-		rowIndex:  make(map[*snapshot.Node]int),
-		metrics:   make(map[*snapshot.Node]treeMetrics),
-		expanded:  make(map[string]bool),
-		focus:     focusTree,
-		sortOrder: sortByNodeName,
-		sortDesc: [5]bool{
+		tree:         tree,
+		snapshotPath: opts.SnapshotPath,
+		rowIndex:     make(map[*snapshot.Node]int),
+		expanded:     make(map[string]bool),
+		encodings:    make(map[*snapshot.Node]format.Encoding),
+		focus:        focusTree,
+		sortOrder:    sortByNodeName,
+		sortDesc: [sortColumnCount]bool{
 			sortByNodeName:    false,
 			sortByNodeSize:    true,
 			sortBySubtreeSize: true,
 			sortByChildren:    true,
+			sortByDescendants: true,
 			sortByModified:    false,
+			sortByCreated:     false,
+			sortByVersion:     true,
 		},
-		width: 120,
+		width:       120,
+		times:       defaultTimeFormatter,
+		sizeWarning: defaultSizeWarning,
+		content:     newContentCache(contentCacheCapacity),
+		rowCache:    newRowCache(),
+		searchTexts: newSearchTextCache(searchTextBudget),
+		compression: newCompressionCache(),
 		copyContent: func(s string) error {
 			return copyToClipboard(s)
 		},
@@ -115,9 +210,24 @@ func NewModel(tree *snapshot.Tree) Model {
 		} else {
 			m.selected = tree.Root
 		}
-		m.metrics = buildTreeMetrics(tree.Root)
+		m.totalNodes = len(flattenAllNodes(tree.Root))
+		m.violations = opts.Policy.Evaluate(tree)
+		m.violating = make(map[*snapshot.Node]bool, len(m.violations))
+		for _, v := range m.violations {
+			m.violating[v.Node] = true
+		}
+		m.warning = opts.Warning
+		if opts.Baseline != nil {
+			m.setBaseline(opts.Baseline.Compare(tree))
+		}
 		m.refreshRows()
 		m.refreshContentLines()
+		if opts.State != nil {
+			m.applyViewState(tree, opts.State)
+		}
+		if node := tree.NodesByPath[opts.StartPath]; node != nil && node != tree.Root {
+			m.selectNode(node)
+		}
 	}
 	return m
 }
@@ -128,10 +238,31 @@ func (m Model) Init() tea.Cmd {
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	needsRowRefresh := false
+	previous := m.selected
+	var cmd tea.Cmd
 	switch msg := msg.(type) {
+	case keyHintExpiredMsg:
+		if msg.seq == m.keyHintSeq {
+			m.keyHint = ""
+		}
+		return m, nil
+	case prefetchDoneMsg:
+		// The content cache already holds what the prefetch formatted.
+		return m, nil
+	case externalDoneMsg:
+		return m, m.finishExternal(msg)
+	case ReloadFailedMsg:
+		return m, m.finishReloadFailed(msg)
 	case tea.WindowSizeMsg:
+		top := m.sourceLineAt(m.contentOffset)
 		m.width = msg.Width
 		m.height = msg.Height
+		if m.wrapContent {
+			m.refreshContentLayout()
+			m.contentOffset = m.displayLineOf(top)
+		}
+	case dataSearchProgressMsg, dataSearchDoneMsg:
+		cmd = m.updateDataSearch(msg)
 	case searchSpinnerMsg:
 		if m.searchRunning {
 			m.searchSpinStep = (m.searchSpinStep + 1) % 4
@@ -145,7 +276,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.searchMessage = ""
 		if msg.scope == searchNodes {
-			m.selectNode(msg.node)
+			m.jumpTo(msg.node)
 			m.centerSelectedRowInTree()
 			m.nodeMatchQuery = msg.query
 			m.nodeMatchNode = msg.node
@@ -222,10 +353,47 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		}
+		if m.prompt != nil {
+			return m.updatePrompt(msg)
+		}
+		if m.encodingMenuOpen {
+			return m.updateEncodingMenu(msg)
+		}
 		if m.statsOpen {
+			if msg.String() == "s" {
+				return m, m.saveStats()
+			}
+			if delta, ok := m.statsScrollDelta(msg.String()); ok {
+				m.scrollStats(delta)
+				return m, nil
+			}
 			m.statsOpen = false
 			return m, nil
 		}
+		if m.legendOpen {
+			m.legendOpen = false
+			return m, nil
+		}
+		if m.helpOpen {
+			m.helpOpen = false
+			return m, nil
+		}
+		if m.auditOpen {
+			m.auditOpen = false
+			return m, nil
+		}
+		if m.duplicatesOpen {
+			m.duplicatesOpen = false
+			return m, nil
+		}
+		if m.sessionsOpen {
+			m.sessionsOpen = false
+			return m, nil
+		}
+		if m.peekRoot && !m.keepsRootPeek(msg.String()) {
+			m.setRootPeek(false)
+		}
+		m.keyHint = ""
 		switch msg.String() {
 		case "ctrl+q":
 			return m, tea.Quit
@@ -244,6 +412,113 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+s":
 			m.openStatsDialog()
 			return m, nil
+		case "L":
+			m.legendOpen = true
+			return m, nil
+		case "?":
+			m.helpOpen = true
+			return m, nil
+		case "0":
+			m.setRootPeek(!m.peekRoot)
+		case "e":
+			m.openEncodingMenu()
+			return m, nil
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if m.focus == focusTree {
+				m.selectNthChild(int(msg.String()[0] - '0'))
+			}
+		case "z":
+			m.toggleWrap()
+		case "t":
+			m.showEpoch = !m.showEpoch
+		case "T":
+			m.cycleTimeMode()
+		case "W":
+			m.openSizeWarningPrompt()
+			return m, nil
+		case "A":
+			m.auditOpen = true
+			return m, nil
+		case "B":
+			m.openDuplicatesDialog()
+			return m, nil
+		case "S":
+			m.openSessionsDialog()
+			return m, nil
+		case "#":
+			m.showRowCount = !m.showRowCount
+		case "w":
+			m.wrapNames = !m.wrapNames
+		case "U":
+			m.sizeUnit = m.sizeUnit.next()
+		case "x":
+			m.showMzxid = !m.showMzxid
+			if leftOuter, _, _ := m.layout(); m.showMzxid && !m.treeDisplay().mzxidShown(leftOuter-2) {
+				cmd = m.flashHint("the tree is too narrow for the mzxid column")
+			}
+		case "H":
+			m.showDigests = !m.showDigests
+		case "Z":
+			m.toggleLocalTimes()
+		case "G":
+			m.openFacetPrompt()
+			return m, nil
+		case "g":
+			m.openJumpPrompt()
+			return m, nil
+		case "F":
+			m.openDataSearchPrompt()
+			return m, nil
+		case "/":
+			m.openPathFilterPrompt()
+			return m, nil
+		case "M":
+			m.openMinSizePrompt()
+			return m, nil
+		case "r", "f5":
+			return m, m.requestReload()
+		case "n":
+			m.stepDataMatch(1)
+		case "N":
+			m.stepDataMatch(-1)
+		case "y":
+			if m.selected != nil {
+				cmd = m.copyWithHint(deepLink(m.snapshotPath, m.selected.Path), "command line")
+			}
+		case "Y":
+			if m.selected != nil {
+				path := printablePath(m.selected.Path)
+				cmd = m.copyWithHint(path, path)
+			}
+		case "c":
+			if m.selected != nil {
+				cmd = m.copySelectedContent()
+			}
+		case "enter":
+			if m.focus == focusContent && m.selected != nil {
+				cmd = m.openInPager()
+			}
+		case "E":
+			if m.selected != nil {
+				cmd = m.openInEditor()
+			}
+		case "s":
+			if m.selected != nil {
+				cmd = m.saveSelectedContent()
+			}
+		case "p":
+			m.togglePin()
+		case "d":
+			m.toggleDiff()
+		case "o":
+			m.toggleSessionFilter()
+		case "esc":
+			if m.filter != nil {
+				m.clearFilter()
+			} else if m.dataSearch != nil || len(m.dataMatches) > 0 {
+				m.cancelDataSearch()
+				m.clearDataMatches()
+			}
 		case "ctrl+f":
 			m.searchOpen = true
 			m.searchFirstKeyPending = true
@@ -258,7 +533,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		case "ctrl+o":
-			m.sortOrder = (m.sortOrder + 1) % 5
+			m.sortOrder = (m.sortOrder + 1) % sortColumnCount
 			if !isFlatMode(m.sortOrder) {
 				m.expandSelectedAncestors()
 			}
@@ -285,10 +560,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "alt+up", "meta+up":
 			if m.focus == focusTree {
-				m.selected = visibleParentNode(m.selected)
-				m.contentOffset = 0
-				m.refreshContentLines()
+				m.jumpTo(visibleParentNode(m.selected))
 			}
+		case "[", "ctrl+left":
+			cmd = m.stepHistory(-1)
+		case "]", "ctrl+right":
+			cmd = m.stepHistory(1)
 		case "down":
 			if m.focus == focusContent {
 				m.scrollContent(1)
@@ -308,15 +585,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.moveSelectionToBoundary(false)
 			}
 		case "left":
-			if m.focus == focusTree && m.selected != nil {
+			if m.focus == focusContent {
+				m.scrollContentH(-contentHScrollStep)
+			} else if m.selected != nil {
 				delete(m.expanded, m.selected.Path)
 				needsRowRefresh = true
 			}
+		case "C":
+			if m.focus == focusTree && m.selected != nil {
+				m.collapseBelowSelection()
+				needsRowRefresh = true
+			}
+		case "shift+right", "*":
+			if m.focus == focusTree && m.selected != nil {
+				m.expandSubtree()
+				needsRowRefresh = true
+			}
 		case "right":
-			if m.focus == focusTree && m.selected != nil && len(m.selected.Children) > 0 {
+			if m.focus == focusContent {
+				m.scrollContentH(contentHScrollStep)
+			} else if m.selected != nil && len(m.selected.Children) > 0 {
 				m.expanded[m.selected.Path] = true
 				needsRowRefresh = true
 			}
+		default:
+			cmd = m.showKeyHint(msg.String())
 		}
 	}
 	if needsRowRefresh {
@@ -326,7 +619,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if _, ok := msg.(tea.WindowSizeMsg); ok {
 		m.adjustContentOffset()
 	}
-	return m, nil
+	if m.selected != previous {
+		cmd = tea.Batch(cmd, m.prefetchNeighbors())
+	}
+	return m, cmd
 }
 
 func (m Model) View() string {
@@ -344,6 +640,7 @@ func (m Model) View() string {
 	rightInner := rightOuter - 2
 	treeInnerHeight := mainHeight - 2
 
+	// The breadcrumb takes the first row of the tree box.
 	treeLines := renderTreeWindow(
 		m.rows,
 		m.selected,
@@ -351,12 +648,13 @@ func (m Model) View() string {
 		m.expanded,
 		m.sortOrder,
 		m.sortDesc[m.sortOrder],
-		m.metrics,
+		m.treeDisplay(),
 		m.nodeMatchNode,
 		m.nodeMatchQuery,
 		m.treeOffset,
-		treeInnerHeight,
+		treeInnerHeight-1,
 	)
+	treeLines = append([]string{m.renderBreadcrumb(leftInner)}, treeLines...)
 	treeStyle := lipgloss.NewStyle().Border(lipgloss.NormalBorder())
 	if m.focus == focusTree {
 		treeStyle = treeStyle.BorderForeground(lipgloss.Color("39"))
@@ -385,17 +683,49 @@ func (m Model) View() string {
 
 	contentLines := m.renderContentWindowLines(rightInner, contentInnerHeight)
 	contentStyle := lipgloss.NewStyle().Border(lipgloss.NormalBorder())
+	contentBorderStyle := lipgloss.NewStyle()
 	if m.focus == focusContent {
 		contentStyle = contentStyle.BorderForeground(lipgloss.Color("39"))
+		contentBorderStyle = contentBorderStyle.Foreground(lipgloss.Color("39"))
 	}
 	contentBox := contentStyle.
 		Width(rightInner).
 		Height(contentInnerHeight).
 		Render(strings.Join(contentLines, "\n"))
+	contentBox = withBorderTitle(contentBox, m.contentTitle, contentBorderStyle)
+	contentBox = withBorderFooter(contentBox, m.contentHScrollFooter(rightInner, contentInnerHeight), contentBorderStyle)
 
 	rightPane := lipgloss.JoinVertical(lipgloss.Left, metadataBox, aclBox, contentBox)
 	mainView := lipgloss.JoinHorizontal(lipgloss.Top, treeBox, " ", rightPane)
 	statusBar := m.renderStatusBar(totalWidth)
+	if m.legendOpen {
+		overlay := lipgloss.Place(totalWidth, mainHeight, lipgloss.Center, lipgloss.Center, m.renderLegendDialog())
+		return overlay + "\n" + statusBar
+	}
+	if m.helpOpen {
+		overlay := lipgloss.Place(totalWidth, mainHeight, lipgloss.Center, lipgloss.Center, m.renderHelpDialog(mainHeight))
+		return overlay + "\n" + statusBar
+	}
+	if m.encodingMenuOpen {
+		overlay := lipgloss.Place(totalWidth, mainHeight, lipgloss.Center, lipgloss.Center, m.renderEncodingMenu())
+		return overlay + "\n" + statusBar
+	}
+	if m.duplicatesOpen {
+		overlay := lipgloss.Place(totalWidth, mainHeight, lipgloss.Center, lipgloss.Center, m.renderDuplicatesDialog())
+		return overlay + "\n" + statusBar
+	}
+	if m.sessionsOpen {
+		overlay := lipgloss.Place(totalWidth, mainHeight, lipgloss.Center, lipgloss.Center, m.renderSessionsDialog())
+		return overlay + "\n" + statusBar
+	}
+	if m.auditOpen {
+		overlay := lipgloss.Place(totalWidth, mainHeight, lipgloss.Center, lipgloss.Center, m.renderAuditDialog())
+		return overlay + "\n" + statusBar
+	}
+	if m.prompt != nil {
+		overlay := lipgloss.Place(totalWidth, mainHeight, lipgloss.Center, lipgloss.Center, m.renderPrompt(totalWidth))
+		return overlay + "\n" + statusBar
+	}
 	if !m.statsOpen {
 		if !m.searchOpen {
 			return mainView + "\n" + statusBar
@@ -408,21 +738,102 @@ func (m Model) View() string {
 }
 
 func (m *Model) refreshRows() {
+	m.rowsGeneration++
 	if m.tree == nil || m.tree.Root == nil {
 		m.rows = nil
 		m.rowIndex = map[*snapshot.Node]int{}
-		m.metrics = map[*snapshot.Node]treeMetrics{}
 		return
 	}
-	if len(m.metrics) == 0 {
-		m.metrics = buildTreeMetrics(m.tree.Root)
-	}
-	m.rows = flatten(m.tree.Root, m.expanded, m.sortOrder, m.sortDesc[m.sortOrder], m.metrics)
+	m.rows = flattenFiltered(m.displayRoot(), m.expanded, m.sortOrder, m.sortDesc[m.sortOrder], m.filter)
 	idx := make(map[*snapshot.Node]int, len(m.rows))
 	for i := range m.rows {
 		idx[m.rows[i].Node] = i
 	}
 	m.rowIndex = idx
+	m.ensureSelectionVisible()
+}
+
+// ensureSelectionVisible moves the selection to its nearest visible ancestor,
+// or to the first row, when the selected node is no longer part of the rows.
+func (m *Model) ensureSelectionVisible() {
+	if len(m.rows) == 0 || m.selectedRowIndex() != -1 {
+		return
+	}
+	next := m.rows[0].Node
+	if m.selected != nil {
+		for p := m.selected.Parent; p != nil; p = p.Parent {
+			if _, ok := m.rowIndex[p]; ok {
+				next = p
+				break
+			}
+		}
+	}
+	m.selected = next
+	m.contentOffset = 0
+	m.contentSelect = false
+	m.clearNodeMatch()
+	m.clearContentMatch()
+	m.refreshContentLines()
+}
+
+// setFilter restricts the tree to nodes matching filter. Ancestors of matches
+// are expanded so the matches are visible; the previous expansion state is
+// restored by clearFilter.
+func (m *Model) setFilter(filter *treeFilter) {
+	if m.tree == nil || m.tree.Root == nil || filter == nil {
+		return
+	}
+	if m.filter == nil {
+		m.unfilteredExpanded = make(map[string]bool, len(m.expanded))
+		for path, open := range m.expanded {
+			m.unfilteredExpanded[path] = open
+		}
+	}
+	m.filter = filter
+	m.minDataSize = 0
+	for _, node := range flattenAllNodes(m.tree.Root) {
+		if !filter.keep(node) {
+			continue
+		}
+		for p := node.Parent; p != nil && p.Parent != nil; p = p.Parent {
+			m.expanded[p.Path] = true
+		}
+	}
+	m.refreshRows()
+	m.adjustTreeOffset()
+}
+
+func (m *Model) clearFilter() {
+	if m.filter == nil {
+		return
+	}
+	m.filter = nil
+	m.minDataSize = 0
+	if m.unfilteredExpanded != nil {
+		m.expanded = m.unfilteredExpanded
+		m.unfilteredExpanded = nil
+	}
+	m.expandSelectedAncestors()
+	m.refreshRows()
+	m.adjustTreeOffset()
+}
+
+// toggleSessionFilter filters the tree to the ephemeral nodes created by the
+// session owning the selected node, or clears that filter again.
+func (m *Model) toggleSessionFilter() {
+	if m.selected == nil || m.selected.Stat.EphemeralOwner == 0 {
+		return
+	}
+	owner := m.selected.Stat.EphemeralOwner
+	label := fmt.Sprintf("session 0x%x", owner)
+	if m.filter != nil && m.filter.label == label {
+		m.clearFilter()
+		return
+	}
+	m.setFilter(&treeFilter{
+		label: label,
+		keep:  func(node *snapshot.Node) bool { return node.Stat.EphemeralOwner == owner },
+	})
 }
 
 func (m *Model) moveSelection(delta int) {
@@ -449,15 +860,19 @@ func (m *Model) moveSelectionPage(direction int) {
 	if len(m.rows) == 0 || m.selected == nil {
 		return
 	}
+	i := m.selectedRowIndex()
+	if i == -1 {
+		return
+	}
 	step := m.treeVisibleDataRows()
 	if step < 1 {
 		step = 1
 	}
-	if direction < 0 {
-		m.moveSelection(-step)
-		return
+	// Clamp so that a partial page still reaches the first or last row.
+	target := min(max(i+direction*step, 0), len(m.rows)-1)
+	if target != i {
+		m.moveSelection(target - i)
 	}
-	m.moveSelection(step)
 }
 
 func (m *Model) moveSelectionToBoundary(toStart bool) {
@@ -495,22 +910,194 @@ func (m *Model) expandSelectedAncestors() {
 	}
 }
 
+// collapseBelowSelection collapses every node deeper than the selected node,
+// keeping the path to the selection expanded.
+func (m *Model) collapseBelowSelection() {
+	depth := strings.Count(m.selected.Path, "/")
+	for path := range m.expanded {
+		if strings.Count(path, "/") > depth {
+			delete(m.expanded, path)
+		}
+	}
+	m.expandSelectedAncestors()
+}
+
+// expandSubtree expands the selected node and all of its descendants.
+func (m *Model) expandSubtree() {
+	var walk func(n *snapshot.Node)
+	walk = func(n *snapshot.Node) {
+		if len(n.Children) == 0 {
+			return
+		}
+		m.expanded[n.Path] = true
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(m.selected)
+}
+
 func (m *Model) refreshContentLines() {
-	if m.selected == nil {
+	node := m.detailNode()
+	if node == nil {
 		m.contentNode = nil
 		m.contentLines = nil
+		m.contentSize = ""
+		m.contentTitle = ""
+		m.refreshContentLayout()
 		return
 	}
-	if m.contentNode == m.selected {
+	if m.contentNode == node {
 		return
 	}
-	m.contentNode = m.selected
-	body := format.ZNodeContent(m.selected.Data)
+	m.contentNode = node
+	m.contentHScroll = 0
+	desc := m.content.describe(node)
+	m.contentSize = desc.size
+	m.contentClass = desc.class
+	m.contentTitle = m.contentTypeTitle(node, node.Bytes())
+	body := m.formattedContent(node)
+	if m.diffPinned && m.pinned != nil && m.pinned != node {
+		body = m.diffContent(node)
+	}
 	lines := strings.Split(body, "\n")
 	if len(lines) == 1 && lines[0] == "" {
 		lines = nil
 	}
 	m.contentLines = lines
+	m.refreshContentLayout()
+}
+
+// refreshContentLayout recomputes the lines shown in the content pane: the
+// content lines as-is, or each of them wrapped at the pane width.
+func (m *Model) refreshContentLayout() {
+	if !m.wrapContent {
+		m.displayLines = m.contentLines
+		m.displaySource = nil
+		return
+	}
+	width := m.contentWrapWidth()
+	m.displayLines = make([]string, 0, len(m.contentLines))
+	m.displaySource = make([]int, 0, len(m.contentLines))
+	for i, line := range m.contentLines {
+		for _, segment := range wrapANSI(line, width) {
+			m.displayLines = append(m.displayLines, segment)
+			m.displaySource = append(m.displaySource, i)
+		}
+	}
+}
+
+// toggleWrap switches content wrapping, keeping the source line at the top of
+// the content pane in view.
+func (m *Model) toggleWrap() {
+	top := m.sourceLineAt(m.contentOffset)
+	m.wrapContent = !m.wrapContent
+	m.contentHScroll = 0
+	m.refreshContentLayout()
+	m.contentOffset = m.displayLineOf(top)
+	m.adjustContentOffset()
+}
+
+// contentWrapWidth is the width wrapped content is broken at. One column is
+// always left for the scrollbar so that the width does not depend on the
+// wrapped line count.
+func (m Model) contentWrapWidth() int {
+	_, rightOuter, _ := m.layout()
+	width := rightOuter - 3
+	if width < 1 {
+		width = 1
+	}
+	return width
+}
+
+// sourceLineAt returns the content line the given display line belongs to.
+func (m Model) sourceLineAt(display int) int {
+	if m.displaySource == nil {
+		return display
+	}
+	if display >= len(m.displaySource) {
+		display = len(m.displaySource) - 1
+	}
+	if display < 0 {
+		return 0
+	}
+	return m.displaySource[display]
+}
+
+// displayLineOf returns the first display line of the given content line.
+func (m Model) displayLineOf(source int) int {
+	if m.displaySource == nil {
+		return source
+	}
+	return sort.SearchInts(m.displaySource, source)
+}
+
+// keepsRootPeek reports whether key leaves an active root peek in place: the
+// peek toggle itself and scrolling the peeked content.
+func (m Model) keepsRootPeek(key string) bool {
+	switch key {
+	case "0", "tab", "e", "z", "t", "T", "p", "d", "H":
+		return true
+	case "up", "down":
+		return m.focus == focusContent
+	}
+	return false
+}
+
+// detailNode is the node shown in the metadata, ACL and content panes: the
+// selection, or the hidden root while it is being peeked at.
+func (m Model) detailNode() *snapshot.Node {
+	if m.peekRoot && m.tree != nil && m.tree.Root != nil {
+		return m.tree.Root
+	}
+	return m.selected
+}
+
+func (m *Model) setRootPeek(on bool) {
+	if m.peekRoot == on {
+		return
+	}
+	m.peekRoot = on
+	m.contentOffset = 0
+	m.contentSelect = false
+	m.refreshContentLines()
+}
+
+// nodeEncoding returns the encoding used to display node: the user's choice
+// from the encodings menu, or the detected one.
+func (m Model) nodeEncoding(node *snapshot.Node) format.Encoding {
+	if enc, ok := m.encodings[node]; ok {
+		return enc
+	}
+	return format.DetectEncoding(node.Bytes())
+}
+
+func (m Model) formattedContent(node *snapshot.Node) string {
+	if enc, ok := m.encodings[node]; ok {
+		return format.RenderAs(node.Bytes(), enc)
+	}
+	return m.content.describe(node).content
+}
+
+// prefetchNeighbors returns a command formatting the rows just above and
+// below the selection in the background so that moving the selection feels
+// instant.
+func (m *Model) prefetchNeighbors() tea.Cmd {
+	if m.content == nil {
+		return nil
+	}
+	i := m.selectedRowIndex()
+	if i == -1 {
+		return nil
+	}
+	nodes := make([]*snapshot.Node, 0, 2)
+	if i+1 < len(m.rows) {
+		nodes = append(nodes, m.rows[i+1].Node)
+	}
+	if i > 0 {
+		nodes = append(nodes, m.rows[i-1].Node)
+	}
+	return prefetchCmd(m.content, m.content.startPrefetch(), nodes)
 }
 
 func (m Model) selectedContentText() string {
@@ -533,10 +1120,10 @@ func (m *Model) clearNodeMatch() {
 
 func (m Model) startNodeSearchCmd(query string) tea.Cmd {
 	tree := m.tree
-	metrics := m.metrics
 	selected := m.selected
+	texts := m.searchTexts
 	return func() tea.Msg {
-		nodes := depthFirstNodesByName(tree, metrics)
+		nodes := depthFirstNodesByName(tree)
 		if len(nodes) == 0 {
 			return searchDoneMsg{scope: searchNodes, query: query, found: false, contentMatch: -1}
 		}
@@ -560,7 +1147,7 @@ func (m Model) startNodeSearchCmd(query string) tea.Cmd {
 					contentMatch: -1,
 				}
 			}
-			if matchAt := strings.Index(plainNodeContent(node), query); matchAt >= 0 {
+			if matchAt := strings.Index(texts.text(node), query); matchAt >= 0 {
 				return searchDoneMsg{
 					scope:        searchNodes,
 					query:        query,
@@ -579,11 +1166,12 @@ func (m Model) startContentSearchCmd(query string) tea.Cmd {
 	matchNode := m.matchNode
 	matchQuery := m.matchQuery
 	matchIndex := m.matchIndex
+	enc, override := m.encodings[selected]
 	return func() tea.Msg {
 		if selected == nil {
 			return searchDoneMsg{scope: searchContent, query: query, found: false, contentMatch: -1}
 		}
-		text := plainNodeContent(selected)
+		text := contentTextAs(selected, enc, override, nil)
 		if text == "" {
 			return searchDoneMsg{scope: searchContent, query: query, found: false, contentMatch: -1}
 		}
@@ -623,6 +1211,28 @@ func (m *Model) selectNode(node *snapshot.Node) {
 	m.adjustTreeOffset()
 }
 
+// selectNthChild expands the selection and selects its nth visible child
+// (1-based) in display order.
+func (m *Model) selectNthChild(n int) {
+	if m.selected == nil || n < 1 || n > len(m.selected.Children) {
+		return
+	}
+	parent := m.selected
+	m.expanded[parent.Path] = true
+	m.refreshRows()
+	seen := 0
+	for _, r := range m.rows {
+		if r.Node.Parent != parent {
+			continue
+		}
+		seen++
+		if seen == n {
+			m.jumpTo(r.Node)
+			return
+		}
+	}
+}
+
 func (m *Model) centerSelectedRowInTree() {
 	if len(m.rows) == 0 {
 		m.treeOffset = 0
@@ -650,22 +1260,19 @@ func (m *Model) centerSelectedRowInTree() {
 	m.treeOffset = target
 }
 
-func depthFirstNodesByName(tree *snapshot.Tree, metrics map[*snapshot.Node]treeMetrics) []*snapshot.Node {
+func depthFirstNodesByName(tree *snapshot.Tree) []*snapshot.Node {
 	if tree == nil || tree.Root == nil {
 		return nil
 	}
-	if len(metrics) == 0 {
-		return flattenAllNodes(tree.Root)
-	}
 	out := make([]*snapshot.Node, 0, 256)
 	var walk func(node *snapshot.Node)
 	walk = func(node *snapshot.Node) {
 		out = append(out, node)
-		for _, child := range sortedChildren(node.Children, sortByNodeName, false, metrics) {
+		for _, child := range sortedChildren(node.Children, sortByNodeName, false) {
 			walk(child)
 		}
 	}
-	for _, child := range sortedChildren(tree.Root.Children, sortByNodeName, false, metrics) {
+	for _, child := range sortedChildren(tree.Root.Children, sortByNodeName, false) {
 		walk(child)
 	}
 	return out
@@ -675,7 +1282,7 @@ func plainNodeContent(node *snapshot.Node) string {
 	if node == nil {
 		return ""
 	}
-	return ansiEscapeRE.ReplaceAllString(format.ZNodeContent(node.Data), "")
+	return ansiEscapeRE.ReplaceAllString(format.ZNodeContent(node.Bytes()), "")
 }
 
 func searchSpinnerTickCmd() tea.Cmd {
@@ -692,7 +1299,7 @@ func (m *Model) scrollMatchIntoView() {
 	if m.matchIndex > len(text) {
 		return
 	}
-	line := strings.Count(text[:m.matchIndex], "\n")
+	line := m.displayLineOf(strings.Count(text[:m.matchIndex], "\n"))
 	height := m.contentInnerHeight()
 	if height < 1 {
 		height = 1
@@ -701,7 +1308,7 @@ func (m *Model) scrollMatchIntoView() {
 	if target < 0 {
 		target = 0
 	}
-	maxOffset := len(m.contentLines) - height
+	maxOffset := len(m.displayLines) - height
 	if maxOffset < 0 {
 		maxOffset = 0
 	}
@@ -712,27 +1319,50 @@ func (m *Model) scrollMatchIntoView() {
 }
 
 func (m Model) renderMetadata() string {
-	if m.selected == nil {
+	node := m.detailNode()
+	if node == nil {
 		return ""
 	}
+	size, class := m.contentSize, m.contentClass
+	if m.contentNode != node {
+		size = m.compression.sizeSummary(node)
+		class = format.ClassifyData(node.Bytes())
+	}
+	if summary := m.compression.compressionSummary(node); summary != "" {
+		size += ", " + summary
+	}
+	if node.DiskSize > 0 {
+		size += fmt.Sprintf(", %d bytes on disk", node.DiskSize)
+	}
+	size += ", hash " + node.ContentHash()
 	return fmt.Sprintf(
-		"%s ID %d (version %d)\nMTime: %s\nCTime: %s\n%s\n%s",
-		printablePath(m.selected.Path),
-		m.selected.ACLRef,
-		m.selected.Stat.Version,
-		formatSnapshotTimeUTC(m.selected.Stat.Mtime),
-		formatSnapshotTimeUTC(m.selected.Stat.Ctime),
-		format.DataSizeSummary(m.selected.Data),
-		nodeMetadata(m.selected),
+		"%s ID %d (version %d)\nMTime: %s\nCTime: %s\n%s\nType: %s\n%s",
+		printablePath(node.Path),
+		node.ACLRef,
+		node.Stat.Version,
+		m.formatMetadataTime(node.Stat.Mtime),
+		m.formatMetadataTime(node.Stat.Ctime),
+		size,
+		class,
+		nodeMetadata(node, m.tree),
 	)
 }
 
-func formatSnapshotTimeUTC(epochMillis int64) string {
-	return time.UnixMilli(epochMillis).UTC().Format(time.RFC3339)
+// formatMetadataTime formats a timestamp for the metadata pane, followed by
+// the raw epoch millis when enabled for correlating with logs.
+func (m Model) formatMetadataTime(epochMillis int64) string {
+	formatted := m.times.format(epochMillis)
+	if m.showEpoch {
+		formatted += fmt.Sprintf(" (epoch %d)", epochMillis)
+	}
+	return formatted
 }
 
-func nodeMetadata(node *snapshot.Node) string {
-	return fmt.Sprintf(
+// nodeMetadata lists the node's zxids and versions. Ephemeral nodes also get
+// their owner's session ID in hex, as ZooKeeper logs it, and its timeout when
+// the session is in the tree's session table.
+func nodeMetadata(node *snapshot.Node, tree *snapshot.Tree) string {
+	meta := fmt.Sprintf(
 		"Metadata: czxid=%d mzxid=%d pzxid=%d child_version=%d ephOwner=%d",
 		node.Stat.Czxid,
 		node.Stat.Mzxid,
@@ -740,6 +1370,14 @@ func nodeMetadata(node *snapshot.Node) string {
 		node.Stat.Cversion,
 		node.Stat.EphemeralOwner,
 	)
+	owner := node.Stat.EphemeralOwner
+	if owner == 0 {
+		return meta
+	}
+	if timeout, ok := tree.SessionTimeout(owner); ok {
+		return meta + fmt.Sprintf(" (session 0x%x, timeout %s)", owner, time.Duration(timeout)*time.Millisecond)
+	}
+	return meta + fmt.Sprintf(" (session 0x%x)", owner)
 }
 
 func (m Model) renderMetadataLines(width, height int) []string {
@@ -749,8 +1387,8 @@ func (m Model) renderMetadataLines(width, height int) []string {
 		lines = lines[:height]
 	}
 	pathToken := ""
-	if m.selected != nil {
-		pathToken = printablePath(m.selected.Path)
+	if node := m.detailNode(); node != nil {
+		pathToken = printablePath(node.Path)
 	}
 	for i := range lines {
 		lines[i] = truncate(lines[i], width)
@@ -765,16 +1403,17 @@ func (m Model) renderMetadataLines(width, height int) []string {
 }
 
 func (m Model) renderACL() string {
-	if m.selected == nil {
+	node := m.detailNode()
+	if node == nil {
 		return "ACL ID: n/a\nACL Version: n/a\n\nNo node selected."
 	}
 
 	lines := []string{
-		fmt.Sprintf("ACL ID %d (version %d)", m.selected.ACLRef, m.selected.Stat.Aversion),
+		fmt.Sprintf("ACL ID %d (version %d)", node.ACLRef, node.Stat.Aversion),
 		"",
 	}
 
-	if m.selected.ACLRef == -1 {
+	if node.ACLRef == -1 {
 		lines = append(lines, "OPEN_ACL_UNSAFE")
 		return strings.Join(lines, "\n")
 	}
@@ -782,13 +1421,13 @@ func (m Model) renderACL() string {
 		lines = append(lines, "No ACL cache available.")
 		return strings.Join(lines, "\n")
 	}
-	entries, ok := m.tree.ACLs[m.selected.ACLRef]
+	entries, ok := m.tree.ACLs[node.ACLRef]
 	if !ok || len(entries) == 0 {
 		lines = append(lines, "No ACL entries found.")
 		return strings.Join(lines, "\n")
 	}
 	for i, entry := range entries {
-		lines = append(lines, fmt.Sprintf("%d. %s", i+1, aclDetail(entry)))
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, aclDetail(entry, m.showDigests)))
 	}
 	return strings.Join(lines, "\n")
 }
@@ -805,12 +1444,14 @@ func (m Model) renderACLLines(width, height int) []string {
 	return lines
 }
 
-func aclDetail(entry snapshot.ACL) string {
+// aclDetail describes entry. Digest IDs are cut to the user name unless
+// showDigest is set, keeping the password hash off screen by default.
+func aclDetail(entry snapshot.ACL, showDigest bool) string {
 	perms := formatACLPermissions(entry.Perms)
 	switch entry.Scheme {
 	case "digest":
 		username := entry.ID
-		if idx := strings.Index(username, ":"); idx >= 0 {
+		if idx := strings.Index(username, ":"); idx >= 0 && !showDigest {
 			username = username[:idx]
 		}
 		return fmt.Sprintf("%s: %s", username, perms)
@@ -853,12 +1494,9 @@ func (m Model) renderContentWindowLines(width, height int) []string {
 		height = 1
 	}
 
-	lines := m.contentLines
+	lines := m.displayLines
 	needsScroll := len(lines) > height
-	textWidth := width
-	if needsScroll && width > 1 {
-		textWidth = width - 1
-	}
+	textWidth := contentTextWidth(width, height, len(lines))
 
 	offset := m.contentOffset
 	maxOffset := len(lines) - height
@@ -879,8 +1517,18 @@ func (m Model) renderContentWindowLines(width, height int) []string {
 		line := ""
 		if idx >= 0 && idx < len(lines) {
 			line = lines[idx]
-			if m.matchNode == m.selected && m.matchQuery != "" && m.matchIndex >= 0 {
-				line = highlightMatchedLine(lines, idx, m.matchQuery, m.matchIndex)
+			if m.matchNode == m.contentNode && m.matchQuery != "" && m.matchIndex >= 0 {
+				src := m.sourceLineAt(idx)
+				line = highlightMatchedLine(m.contentLines, src, m.matchQuery, m.matchIndex)
+				if m.wrapContent {
+					segments := wrapANSI(line, m.contentWrapWidth())
+					if k := idx - m.displayLineOf(src); k < len(segments) {
+						line = segments[k]
+					}
+				}
+			}
+			if !m.wrapContent {
+				line = skipColumnsANSI(line, m.contentHScroll)
 			}
 			line = truncateANSI(line, textWidth)
 		}
@@ -932,17 +1580,54 @@ func (m *Model) adjustTreeOffset() {
 	if m.treeOffset < 0 {
 		m.treeOffset = 0
 	}
+	if m.wrapNames {
+		m.fitWrappedSelection(sel, visibleHeight)
+	}
+}
+
+// fitWrappedSelection moves the tree offset down until the selected row and
+// all rows above it from the offset fit, counting wrapped continuation lines.
+func (m *Model) fitWrappedSelection(sel, visibleHeight int) {
+	leftOuter, _, _ := m.layout()
+	width := leftOuter - 2
+	display := m.treeDisplay()
+	for m.treeOffset < sel {
+		used := 0
+		for _, r := range m.rows[m.treeOffset : sel+1] {
+			used += treeRowHeight(r, width, display)
+		}
+		if used <= visibleHeight {
+			return
+		}
+		m.treeOffset++
+	}
+}
+
+// treeDisplay collects the settings that change how tree rows are rendered.
+func (m Model) treeDisplay() treeDisplay {
+	return treeDisplay{
+		times:       m.times,
+		sizeWarning: m.sizeWarning,
+		violating:   m.violating,
+		baseline:    m.baselineMarks,
+		wrapNames:   m.wrapNames,
+		sizeUnit:    m.sizeUnit,
+		showMzxid:   m.showMzxid,
+		rowCache:    m.rowCache,
+		generation:  m.rowsGeneration,
+		compression: m.compression,
+	}
 }
 
 func (m *Model) treeVisibleDataRows() int {
 	_, _, paneHeight := m.layout()
 	// Keep this in sync with View(): mainHeight = paneHeight - 1, treeInnerHeight = mainHeight - 2,
-	// and one tree row is consumed by the table header.
-	return paneHeight - 4
+	// and two tree rows are consumed by the breadcrumb and the table header.
+	return paneHeight - 5
 }
 
 func (m *Model) scrollContent(delta int) {
-	lines := m.contentLines
+	lines := m.displayLines
 	if len(lines) == 0 {
 		m.contentOffset = 0
 		return
@@ -984,7 +1669,7 @@ func copyToClipboard(text string) error {
 }
 
 func (m *Model) adjustContentOffset() {
-	lines := m.contentLines
+	lines := m.displayLines
 	contentInnerHeight := m.contentInnerHeight()
 	maxOffset := len(lines) - contentInnerHeight
 	if maxOffset < 0 {
@@ -1079,6 +1764,13 @@ func (m Model) layout() (leftOuter, rightOuter, paneHeight int) {
 	return leftOuter, rightOuter, paneHeight
 }
 
+// screenWidth returns the width both panes span together, which is what the
+// dialogs drawn over them have to fit in.
+func (m Model) screenWidth() int {
+	leftOuter, rightOuter, _ := m.layout()
+	return leftOuter + 1 + rightOuter
+}
+
 func printablePath(path string) string {
 	if path == "" {
 		return "/"
@@ -1101,6 +1793,55 @@ func padToWidthANSI(s string, width int) string {
 	return s + strings.Repeat(" ", width-w)
 }
 
+// wrapANSI breaks s into lines of at most width cells. Escape sequences are
+// kept intact, and styles active at a break are reset at the end of the line
+// and reapplied at the start of the next one.
+func wrapANSI(s string, width int) []string {
+	if width < 1 || lipgloss.Width(s) <= width {
+		return []string{s}
+	}
+	var out []string
+	var b strings.Builder
+	active := ""
+	col := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '[' {
+			j := i + 2
+			for j < len(s) {
+				c := s[j]
+				j++
+				if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') {
+					break
+				}
+			}
+			seq := s[i:j]
+			if seq == "\x1b[0m" || seq == "\x1b[m" {
+				active = ""
+			} else {
+				active += seq
+			}
+			b.WriteString(seq)
+			i = j
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		rw := lipgloss.Width(string(r))
+		if col+rw > width && col > 0 {
+			if active != "" {
+				b.WriteString("\x1b[0m")
+			}
+			out = append(out, b.String())
+			b.Reset()
+			b.WriteString(active)
+			col = 0
+		}
+		b.WriteString(s[i : i+size])
+		col += rw
+		i += size
+	}
+	return append(out, b.String())
+}
+
 func truncateANSI(s string, max int) string {
 	if max <= 0 {
 		return ""
@@ -1148,14 +1889,46 @@ func truncateANSI(s string, max int) string {
 }
 
 func (m Model) renderStatusBar(width int) string {
-	items := []string{
-		statusKeyStyle.Render("^Q") + " Quit",
-		statusKeyStyle.Render("^S") + " Show stats",
-		statusKeyStyle.Render("^F") + " Search",
-		statusKeyStyle.Render("Tab") + " Switch panels",
-		statusKeyStyle.Render("^O") + " Change sort order",
-		statusKeyStyle.Render("^R") + " Reverse sort order",
+	items := []string{}
+	if m.warning != "" {
+		items = append(items, statusWarningStyle.Render(m.warning))
+	}
+	if m.reloading {
+		items = append(items, "reloading…")
+	}
+	if m.keyHint != "" {
+		items = append(items, m.keyHint)
+	}
+	if m.showRowCount {
+		items = append(items, m.rowCountText())
+	}
+	if m.filter != nil {
+		items = append(items, statusKeyStyle.Render("Esc")+" Clear filter: "+m.filter.label)
+	}
+	if status := m.dataSearchStatus(); status != "" {
+		items = append(items, status)
 	}
+	if m.facetRoot != nil {
+		items = append(items, fmt.Sprintf("Grouped by segment %d", m.facetSegment))
+	}
+	if m.sizeUnit != unitBytes {
+		items = append(items, "Sizes in "+m.sizeUnit.String())
+	}
+	if m.times.location == time.Local {
+		items = append(items, "Times in local time")
+	}
+	if m.pinned != nil {
+		items = append(items, statusKeyStyle.Render("D")+" Diff with "+printablePath(m.pinned.Path))
+	}
+	items = append(items,
+		statusKeyStyle.Render("?")+" Help",
+		statusKeyStyle.Render("^Q")+" Quit",
+		statusKeyStyle.Render("^S")+" Show stats",
+		statusKeyStyle.Render("^F")+" Search",
+		statusKeyStyle.Render("Tab")+" Switch panels",
+		statusKeyStyle.Render("^O")+" Change sort order",
+		statusKeyStyle.Render("^R")+" Reverse sort order",
+	)
 	if m.focus == focusContent {
 		items = append(items,
 			statusKeyStyle.Render("^A")+" Select all",
@@ -1163,15 +1936,31 @@ func (m Model) renderStatusBar(width int) string {
 		)
 	}
 	text := strings.Join(items, " | ")
+	path := ""
+	if m.selected != nil {
+		path = printablePath(m.selected.Path)
+	}
 	if width < 1 {
 		width = lipgloss.Width(text)
+		if path != "" {
+			width += lipgloss.Width(path) + 3
+		}
 	}
 	if width == 1 {
 		return " "
 	}
+	innerWidth := width - 1
 	line := text
+	if path != "" {
+		// The selected path always leads; the other items get what is left.
+		path = truncateLeft(path, innerWidth)
+		rest := innerWidth - lipgloss.Width(path) - 3
+		line = path
+		if rest > 0 {
+			line += " | " + truncate(text, rest)
+		}
+	}
 	lineWidth := lipgloss.Width(line)
-	innerWidth := width - 1
 	if lineWidth < innerWidth {
 		line += strings.Repeat(" ", innerWidth-lineWidth)
 	} else if lineWidth > innerWidth {
@@ -1180,6 +1969,44 @@ func (m Model) renderStatusBar(width int) string {
 	return " " + statusBarStyle.Width(innerWidth).Render(line)
 }
 
+// truncateLeft shortens s to max columns by dropping its start, keeping the
+// tail, which is the informative end of a path.
+func truncateLeft(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	if lipgloss.Width(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > max {
+		runes = runes[1:]
+	}
+	return "…" + string(runes)
+}
+
+// rowCountText compares the rows in the tree with the number of nodes in the
+// snapshot, which tells how much is hidden by collapsed nodes and filters.
+func (m Model) rowCountText() string {
+	return fmt.Sprintf("Showing %s of %s nodes", formatCount(len(m.rows)), formatCount(m.totalNodes))
+}
+
+// formatCount renders n with thousands separators.
+func formatCount(n int) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	digits := strconv.Itoa(n)
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
 func (m Model) renderSearchDialog(totalWidth int) string {
 	title := "Search nodes (name + content)"
 	if m.searchScope == searchContent {
@@ -1276,10 +2103,44 @@ type snapshotStats struct {
 	ephemeralNodes int
 	emptyNodes     int
 	totalSize      int
-	biggestSize    int
-	biggestPath    string
+	distinctBlobs  int
+	// depthCounts holds how many nodes sit at each depth, the root being
+	// depth 0.
+	depthCounts []int
+	// aclCounts holds how many nodes use each ACL ref.
+	aclCounts map[int64]int
+	// largest holds the largestListed nodes with the most data, largest
+	// first.
+	largest []sizedNode
+	// newestWrite is the latest ctime/mtime in the tree, which approximates
+	// when the snapshot was taken.
+	newestWrite int64
+	// nearLimit holds the nodes within nearLimitRatio of jute.maxbuffer,
+	// largest first.
+	nearLimit []sizedNode
+}
+
+type sizedNode struct {
+	path string
+	size int
 }
 
+// Nodes at nearLimitPercent of ZooKeeper's default jute.maxbuffer are
+// reported in the stats; the largest nearLimitListed of them are listed.
+const (
+	nearLimitPercent = 90
+	nearLimitListed  = 3
+)
+
+// depthListed is how many depths the stats break the node count down by.
+const depthListed = 8
+
+// aclListed is how many of the most used ACL refs the stats list.
+const aclListed = 5
+
+// largestListed is how many of the nodes with the most data the stats rank.
+const largestListed = 10
+
 func (m *Model) openStatsDialog() {
 	stats := collectSnapshotStats(m.tree)
 	avgSize := 0.0
@@ -1294,11 +2155,15 @@ func (m *Model) openStatsDialog() {
 		len(strconv.Itoa(stats.emptyNodes)),
 	)
 	avgRounded := int(math.Round(avgSize))
+	biggest := sizedNode{path: "/"}
+	if len(stats.largest) > 0 {
+		biggest = stats.largest[0]
+	}
 	sizeWidth := maxLen([]string{
 		strconv.Itoa(avgRounded),
-		strconv.Itoa(stats.biggestSize),
+		strconv.Itoa(biggest.size),
 	})
-	m.statsText = strings.Join([]string{
+	lines := []string{
 		"Snapshot Statistics",
 		"",
 		fmt.Sprintf("%-*s: %*d", labelWidth, "Total nodes", countWidth, stats.totalNodes),
@@ -1306,50 +2171,244 @@ func (m *Model) openStatsDialog() {
 		fmt.Sprintf("%-*s: %*d", labelWidth, "Empty nodes", countWidth, stats.emptyNodes),
 		"",
 		fmt.Sprintf("Average node: %*d bytes", sizeWidth, avgRounded),
-		fmt.Sprintf("Biggest node: %*d bytes at %s", sizeWidth, stats.biggestSize, stats.biggestPath),
+		fmt.Sprintf("Biggest node: %*d bytes at %s", sizeWidth, biggest.size, biggest.path),
+		fmt.Sprintf("Distinct blobs: %d of %d nodes", stats.distinctBlobs, stats.totalNodes),
+		"",
+	}
+	lines = append(lines, depthLines(stats.depthCounts)...)
+	lines = append(lines, "")
+	lines = append(lines, largestLines(stats.largest)...)
+	lines = append(lines, "")
+	var acls map[int64][]snapshot.ACL
+	if m.tree != nil {
+		acls = m.tree.ACLs
+	}
+	lines = append(lines, aclUsageLines(stats.aclCounts, acls)...)
+	lines = append(lines, "")
+	lines = append(lines, nearLimitLines(stats.nearLimit)...)
+	lines = append(lines,
 		"",
-		"Press any key to close.",
-	}, "\n")
+		capturedLine(stats.newestWrite, m.times),
+	)
+	m.statsText = strings.Join(lines, "\n")
+	m.statsOffset = 0
 	m.statsOpen = true
 }
 
+// nearLimitLines reports how many nodes are close to the jute.maxbuffer limit
+// and lists the largest of them.
+func nearLimitLines(nodes []sizedNode) []string {
+	lines := []string{fmt.Sprintf("Near 1MB limit: %d nodes at %d%% or more", len(nodes), nearLimitPercent)}
+	for i, n := range nodes {
+		if i == nearLimitListed {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(nodes)-nearLimitListed))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %d bytes at %s", n.size, n.path))
+	}
+	return lines
+}
+
+// depthLines lists the node count per depth below the root, summing up the
+// depths beyond depthListed.
+func depthLines(counts []int) []string {
+	if len(counts) <= 1 {
+		return []string{"Nodes by depth: none below the root"}
+	}
+	counts = counts[1:]
+	listed := min(len(counts), depthListed)
+	depthWidth := len(strconv.Itoa(listed))
+	countWidth := 0
+	for _, c := range counts[:listed] {
+		countWidth = max(countWidth, len(strconv.Itoa(c)))
+	}
+	lines := []string{fmt.Sprintf("Nodes by depth: %d levels", len(counts))}
+	for i, c := range counts[:listed] {
+		lines = append(lines, fmt.Sprintf("  depth %*d: %*d", depthWidth, i+1, countWidth, c))
+	}
+	if rest := counts[listed:]; len(rest) > 0 {
+		deeper := 0
+		for _, c := range rest {
+			deeper += c
+		}
+		lines = append(lines, fmt.Sprintf("  ... and %d nodes %d to %d deep", deeper, listed+1, len(counts)))
+	}
+	return lines
+}
+
+// aclUsageLines counts the nodes open to anyone through OPEN_ACL_UNSAFE and
+// lists the most used ACL refs with their entries.
+func aclUsageLines(counts map[int64]int, acls map[int64][]snapshot.ACL) []string {
+	refs := make([]int64, 0, len(counts))
+	for ref := range counts {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if counts[refs[i]] != counts[refs[j]] {
+			return counts[refs[i]] > counts[refs[j]]
+		}
+		return refs[i] < refs[j]
+	})
+	lines := []string{fmt.Sprintf("ACL usage: %d refs, %d nodes with OPEN_ACL_UNSAFE", len(refs), counts[-1])}
+	for i, ref := range refs {
+		if i == aclListed {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(refs)-aclListed))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  ACL %d: %d nodes, %s", ref, counts[ref], aclSummary(ref, acls)))
+	}
+	return lines
+}
+
+// aclSummary describes the entries of an ACL ref on one line.
+func aclSummary(ref int64, acls map[int64][]snapshot.ACL) string {
+	if ref == -1 {
+		return "OPEN_ACL_UNSAFE"
+	}
+	entries := acls[ref]
+	if len(entries) == 0 {
+		return "no entries found"
+	}
+	details := make([]string, len(entries))
+	for i, entry := range entries {
+		details[i] = aclDetail(entry, false)
+	}
+	return strings.Join(details, "; ")
+}
+
+// largestLines ranks the nodes with the most data as "path — size" lines.
+func largestLines(nodes []sizedNode) []string {
+	if len(nodes) == 0 {
+		return []string{"Largest nodes: none with data"}
+	}
+	lines := []string{fmt.Sprintf("Largest nodes: top %d", len(nodes))}
+	rankWidth := len(strconv.Itoa(len(nodes)))
+	for i, n := range nodes {
+		lines = append(lines, fmt.Sprintf("  %*d. %s — %d bytes", rankWidth, i+1, n.path, n.size))
+	}
+	return lines
+}
+
+// keepLargest inserts n into nodes, which is sorted largest first, and
+// drops whatever falls beyond the first limit entries.
+func keepLargest(nodes []sizedNode, n sizedNode, limit int) []sizedNode {
+	if len(nodes) == limit && n.size <= nodes[limit-1].size {
+		return nodes
+	}
+	i := sort.Search(len(nodes), func(i int) bool { return nodes[i].size < n.size })
+	if len(nodes) < limit {
+		nodes = append(nodes, sizedNode{})
+	}
+	copy(nodes[i+1:], nodes[i:])
+	nodes[i] = n
+	return nodes
+}
+
+func capturedLine(newestWrite int64, times timeFormatter) string {
+	if newestWrite <= 0 {
+		return "Snapshot captured: unknown"
+	}
+	return "Snapshot captured ~" + times.absolute().format(newestWrite)
+}
+
 func collectSnapshotStats(tree *snapshot.Tree) snapshotStats {
-	stats := snapshotStats{biggestPath: "/"}
+	stats := snapshotStats{}
 	if tree == nil || tree.Root == nil {
 		return stats
 	}
 
-	var walk func(node *snapshot.Node)
-	walk = func(node *snapshot.Node) {
+	blobs := make(map[string]struct{})
+	stats.aclCounts = make(map[int64]int)
+	var walk func(node *snapshot.Node, depth int)
+	walk = func(node *snapshot.Node, depth int) {
 		stats.totalNodes++
-		size := len(node.Data)
-		stats.totalSize += size
+		if depth == len(stats.depthCounts) {
+			stats.depthCounts = append(stats.depthCounts, 0)
+		}
+		stats.depthCounts[depth]++
+		blobs[node.ContentHash()] = struct{}{}
+		stats.aclCounts[node.ACLRef]++
+		size := node.DataLen()
 		if node.Stat.EphemeralOwner != 0 {
 			stats.ephemeralNodes++
 		}
 		if size == 0 {
 			stats.emptyNodes++
 		}
-		if size > stats.biggestSize {
-			stats.biggestSize = size
-			stats.biggestPath = printablePath(node.Path)
+		if size > 0 {
+			stats.largest = keepLargest(stats.largest, sizedNode{path: printablePath(node.Path), size: size}, largestListed)
+		}
+		if size*100 >= defaultSizeWarning*nearLimitPercent {
+			stats.nearLimit = append(stats.nearLimit, sizedNode{path: printablePath(node.Path), size: size})
 		}
 		for _, child := range node.Children {
-			walk(child)
+			walk(child, depth+1)
 		}
 	}
-	walk(tree.Root)
+	walk(tree.Root, 0)
+	stats.totalSize = tree.Root.SubtreeSize()
+	stats.distinctBlobs = len(blobs)
+	stats.newestWrite = newestWrite(tree.Root)
+	sort.SliceStable(stats.nearLimit, func(i, j int) bool {
+		return stats.nearLimit[i].size > stats.nearLimit[j].size
+	})
 
 	return stats
 }
 
+// statsBodyHeight is how many report lines fit in the stats dialog between
+// its border and the close hint.
+func (m Model) statsBodyHeight() int {
+	_, _, paneHeight := m.layout()
+	// The status bar, the border and the blank line and hint below the
+	// report.
+	if h := paneHeight - 5; h > 1 {
+		return h
+	}
+	return 1
+}
+
+// statsScrollDelta returns how many lines key scrolls the stats report. Only
+// a report taller than the dialog scrolls; otherwise every key but s closes
+// it.
+func (m Model) statsScrollDelta(key string) (int, bool) {
+	height := m.statsBodyHeight()
+	if strings.Count(m.statsText, "\n")+1 <= height {
+		return 0, false
+	}
+	switch key {
+	case "up":
+		return -1, true
+	case "down":
+		return 1, true
+	case "pgup":
+		return -height, true
+	case "pgdown":
+		return height, true
+	}
+	return 0, false
+}
+
+// scrollStats moves the stats report delta lines, keeping the last page in
+// view.
+func (m *Model) scrollStats(delta int) {
+	maxOffset := strings.Count(m.statsText, "\n") + 1 - m.statsBodyHeight()
+	m.statsOffset = max(0, min(m.statsOffset+delta, maxOffset))
+}
+
 func (m Model) renderStatsDialog() string {
-	totalWidth, _, _ := m.layout()
-	dialogWidth := totalWidth - 2
+	dialogWidth := m.screenWidth() - 2
 	if dialogWidth < 32 {
 		dialogWidth = 32
 	}
 	lines := strings.Split(m.statsText, "\n")
+	hint := statsCloseHint
+	if height := m.statsBodyHeight(); len(lines) > height {
+		offset := min(m.statsOffset, len(lines)-height)
+		lines = lines[offset : offset+height]
+		hint = statsScrollHint
+	}
+	lines = append(lines, "", hint)
 	for i := range lines {
 		lines[i] = truncate(lines[i], dialogWidth)
 		lines[i] = styleStatsLine(lines[i])
@@ -1363,13 +2422,19 @@ func (m Model) renderStatsDialog() string {
 
 func styleStatsLine(line string) string {
 	labels := map[string]struct{}{
-		"Snapshot Statistics":     {},
-		"Total nodes":             {},
-		"Ephemeral nodes":         {},
-		"Empty nodes":             {},
-		"Average node":            {},
-		"Biggest node":            {},
-		"Press any key to close.": {},
+		"Snapshot Statistics": {},
+		"Total nodes":         {},
+		"Ephemeral nodes":     {},
+		"Empty nodes":         {},
+		"Average node":        {},
+		"Biggest node":        {},
+		"Largest nodes":       {},
+		"Nodes by depth":      {},
+		"ACL usage":           {},
+		"Distinct blobs":      {},
+		"Near 1MB limit":      {},
+		statsCloseHint:        {},
+		statsScrollHint:       {},
 	}
 	if idx := strings.Index(line, ":"); idx > 0 {
 		label := strings.TrimRight(line[:idx], " ")
@@ -1,10 +1,12 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/jowiho/zooxplorer/internal/snapshot"
 )
 
@@ -162,7 +164,7 @@ func TestCtrlFNodeSearchCentersTreeSelection(t *testing.T) {
 
 	model := NewModel(&snapshot.Tree{Root: root})
 	var m tea.Model = model
-	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 8}) // tree visible rows = 4
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 9}) // tree visible rows = 4
 	m = applyAndFlushCmd(m, tea.KeyMsg{Type: tea.KeyCtrlF})
 	m = applyAndFlushCmd(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
 	m = applyAndFlushCmd(m, tea.KeyMsg{Type: tea.KeyEnter})
@@ -179,7 +181,7 @@ func TestCtrlFNodeSearchCentersTreeSelection(t *testing.T) {
 func TestCtrlFContentSearchCentersMatchedLine(t *testing.T) {
 	m := NewModel(sampleSnapshotTree())
 	var model tea.Model = m
-	model, _ = model.Update(tea.WindowSizeMsg{Width: 100, Height: 20}) // content height = 4
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 100, Height: 21}) // content height = 4
 	model = applyAndFlushCmd(model, tea.KeyMsg{Type: tea.KeyTab})      // focus content
 	model = applyAndFlushCmd(model, tea.KeyMsg{Type: tea.KeyCtrlF})
 	model = applyAndFlushCmd(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("line7")})
@@ -265,7 +267,7 @@ func TestModelArrowNavigation(t *testing.T) {
 	}
 
 	model, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRight})
-	if cmd != nil {
+	if !onlyPrefetch(cmd) {
 		t.Fatal("unexpected command")
 	}
 	typed = model.(Model)
@@ -277,7 +279,7 @@ func TestModelArrowNavigation(t *testing.T) {
 	}
 
 	model, cmd = model.Update(tea.KeyMsg{Type: tea.KeyDown})
-	if cmd != nil {
+	if !onlyPrefetch(cmd) {
 		t.Fatal("unexpected command")
 	}
 	typed = model.(Model)
@@ -286,7 +288,7 @@ func TestModelArrowNavigation(t *testing.T) {
 	}
 
 	model, cmd = model.Update(tea.KeyMsg{Type: tea.KeyLeft})
-	if cmd != nil {
+	if !onlyPrefetch(cmd) {
 		t.Fatal("unexpected command")
 	}
 	typed = model.(Model)
@@ -296,7 +298,7 @@ func TestModelArrowNavigation(t *testing.T) {
 
 	// Move to parent and collapse it.
 	model, cmd = model.Update(tea.KeyMsg{Type: tea.KeyUp})
-	if cmd != nil {
+	if !onlyPrefetch(cmd) {
 		t.Fatal("unexpected command")
 	}
 	typed = model.(Model)
@@ -305,7 +307,7 @@ func TestModelArrowNavigation(t *testing.T) {
 	}
 
 	model, cmd = model.Update(tea.KeyMsg{Type: tea.KeyLeft})
-	if cmd != nil {
+	if !onlyPrefetch(cmd) {
 		t.Fatal("unexpected command")
 	}
 	typed = model.(Model)
@@ -317,7 +319,7 @@ func TestModelArrowNavigation(t *testing.T) {
 	}
 
 	model, cmd = model.Update(tea.KeyMsg{Type: tea.KeyDown})
-	if cmd != nil {
+	if !onlyPrefetch(cmd) {
 		t.Fatal("unexpected command")
 	}
 	typed = model.(Model)
@@ -332,6 +334,16 @@ func TestModelArrowNavigation(t *testing.T) {
 	}
 }
 
+// onlyPrefetch reports whether cmd is nil or just prefetches the content of
+// the rows next to a new selection.
+func onlyPrefetch(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return true
+	}
+	_, ok := cmd().(prefetchDoneMsg)
+	return ok
+}
+
 func TestModelTreeScrollOffsetTracksSelection(t *testing.T) {
 	root := &snapshot.Node{ID: "/", Path: ""}
 	nodes := make([]*snapshot.Node, 0, 10)
@@ -371,8 +383,8 @@ func TestTreeScrollDownFromBottomVisibleRow(t *testing.T) {
 
 	model := NewModel(&snapshot.Tree{Root: root})
 	var m tea.Model = model
-	// With height=8, visible tree data rows should be 4 (after status bar, borders, and table header).
-	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 8})
+	// With height=9, visible tree data rows should be 4 (after status bar, borders, breadcrumb and table header).
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 9})
 
 	for i := 0; i < 3; i++ {
 		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
@@ -437,6 +449,42 @@ func TestRenderMetadataIncludesSize(t *testing.T) {
 	}
 }
 
+func TestRenderMetadataIncludesContentHash(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	if meta := m.renderMetadata(); !strings.Contains(meta, "hash "+m.selected.ContentHash()) {
+		t.Fatalf("expected content hash in metadata, got: %q", meta)
+	}
+}
+
+func TestRenderMetadataIncludesDiskSizeWhenKnown(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	if strings.Contains(m.renderMetadata(), "on disk") {
+		t.Fatal("expected no on-disk size for nodes without one")
+	}
+	m.selected.DiskSize = 123
+	if meta := m.renderMetadata(); !strings.Contains(meta, "123 bytes on disk") {
+		t.Fatalf("expected on-disk size in metadata, got: %q", meta)
+	}
+}
+
+func TestRenderMetadataIncludesSessionTimeout(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.Sessions = map[int64]int32{42: 30000}
+	m := NewModel(tree)
+	m.selectNode(tree.NodesByPath["/b"])
+	if meta := m.renderMetadata(); !strings.HasSuffix(meta, "ephOwner=42 (session 0x2a, timeout 30s)") {
+		t.Fatalf("expected the owner's session timeout, got: %q", meta)
+	}
+	delete(tree.Sessions, 42)
+	if meta := m.renderMetadata(); !strings.HasSuffix(meta, "ephOwner=42 (session 0x2a)") {
+		t.Fatalf("expected only the hex session for an unknown session, got: %q", meta)
+	}
+	m.selectNode(tree.NodesByPath["/a"])
+	if meta := m.renderMetadata(); !strings.HasSuffix(meta, "ephOwner=0") {
+		t.Fatalf("expected persistent nodes unchanged, got: %q", meta)
+	}
+}
+
 func TestModelCtrlOCyclesSortColumn(t *testing.T) {
 	m := NewModel(sampleSnapshotTree())
 	var model tea.Model = m
@@ -445,7 +493,10 @@ func TestModelCtrlOCyclesSortColumn(t *testing.T) {
 		sortByNodeSize,
 		sortBySubtreeSize,
 		sortByChildren,
+		sortByDescendants,
 		sortByModified,
+		sortByCreated,
+		sortByVersion,
 		sortByNodeName,
 	}
 	for _, want := range expected {
@@ -459,17 +510,17 @@ func TestModelCtrlOCyclesSortColumn(t *testing.T) {
 
 func TestCtrlOSwitchFromFlatToHierarchyKeepsSelectionVisible(t *testing.T) {
 	m := NewModel(sampleSnapshotTree())
-	m.sortOrder = sortByModified // flat mode
+	m.sortOrder = sortByCreated // flat mode
 	m.selected = m.tree.NodesByPath["/a/a1"]
 	m.expanded = map[string]bool{}
 	m.refreshRows()
 
 	var model tea.Model = m
-	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlO}) // -> sortByNodeName (hierarchical)
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlO}) // -> sortByVersion (hierarchical)
 	typed := model.(Model)
 
-	if typed.sortOrder != sortByNodeName {
-		t.Fatalf("expected sortByNodeName, got %v", typed.sortOrder)
+	if typed.sortOrder != sortByVersion {
+		t.Fatalf("expected sortByVersion, got %v", typed.sortOrder)
 	}
 	if !typed.expanded["/a"] {
 		t.Fatalf("expected /a expanded so /a/a1 stays visible")
@@ -548,11 +599,25 @@ func TestRenderACLIncludesDigestUsernameAndPermissions(t *testing.T) {
 	}
 }
 
+func TestHKeyTogglesDigestHashes(t *testing.T) {
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("H")})
+	if acl := model.(Model).renderACL(); !strings.Contains(acl, "alice:secret: create|read|write") {
+		t.Fatalf("expected the full digest ID, got: %q", acl)
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("H")})
+	if acl := model.(Model).renderACL(); strings.Contains(acl, "secret") {
+		t.Fatalf("expected the hash hidden again, got: %q", acl)
+	}
+}
+
 func TestModelCtrlSShowsStatsAndAnyKeyCloses(t *testing.T) {
 	m := NewModel(sampleSnapshotTree())
 	var model tea.Model = m
 	selectedPath := m.selected.Path
 
+	// Tall enough for the whole report, so arrow keys do not scroll it.
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 100, Height: 60})
 	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
 	typed := model.(Model)
 	if !typed.statsOpen {
@@ -571,8 +636,14 @@ func TestModelCtrlSShowsStatsAndAnyKeyCloses(t *testing.T) {
 	if !strings.Contains(stats, "Empty nodes    : 3") {
 		t.Fatalf("expected empty count, got: %q", stats)
 	}
-	if !strings.Contains(stats, "Biggest node:") || !strings.Contains(stats, "/a") {
-		t.Fatalf("expected biggest node details, got: %q", stats)
+	if !strings.Contains(stats, "Largest nodes: top 1\n  1. /a — ") {
+		t.Fatalf("expected largest node details, got: %q", stats)
+	}
+	if !strings.Contains(stats, "Distinct blobs: 2 of 4 nodes") {
+		t.Fatalf("expected distinct blob count, got: %q", stats)
+	}
+	if width := lipgloss.Width(typed.renderStatsDialog()); width != 100 {
+		t.Fatalf("expected the dialog to span the 100-column screen, got %d", width)
 	}
 
 	// Any key closes the dialog and should not trigger normal key handling.
@@ -586,6 +657,154 @@ func TestModelCtrlSShowsStatsAndAnyKeyCloses(t *testing.T) {
 	}
 }
 
+func TestStatsReportApproximateCaptureTime(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a"].Stat.Mtime = 1_700_000_000_000
+	tree.NodesByPath["/a/a1"].Stat.Ctime = 1_700_000_500_000
+	tree.NodesByPath["/b"].Stat.Mtime = 1_600_000_000_000
+
+	stats := collectSnapshotStats(tree)
+	if stats.newestWrite != 1_700_000_500_000 {
+		t.Fatalf("expected newest write to be the latest node timestamp, got %d", stats.newestWrite)
+	}
+
+	m := NewModel(tree)
+	m.openStatsDialog()
+	if !strings.Contains(m.statsText, "Snapshot captured ~"+formatSnapshotTimeUTC(1_700_000_500_000)) {
+		t.Fatalf("expected capture time in stats, got: %q", m.statsText)
+	}
+}
+
+func TestStatsReportNodesNearJuteLimit(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/b"].Data = make([]byte, 1000*1024)
+	tree.NodesByPath["/a/a1"].Data = make([]byte, 512*1024)
+
+	stats := collectSnapshotStats(tree)
+	if len(stats.nearLimit) != 1 || stats.nearLimit[0].path != "/b" {
+		t.Fatalf("expected only /b near the limit, got %+v", stats.nearLimit)
+	}
+
+	m := NewModel(tree)
+	m.openStatsDialog()
+	if !strings.Contains(m.statsText, "Near 1MB limit: 1 nodes at 90% or more\n  1024000 bytes at /b") {
+		t.Fatalf("expected near-limit report, got: %q", m.statsText)
+	}
+	if strings.Contains(m.statsText, "at /a/a1") {
+		t.Fatalf("expected small node not to be reported, got: %q", m.statsText)
+	}
+}
+
+func TestStatsRankLargestNodes(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a"].Data = make([]byte, 10)
+	tree.NodesByPath["/a/a1"].Data = make([]byte, 30)
+	tree.NodesByPath["/b"].Data = make([]byte, 20)
+
+	stats := collectSnapshotStats(tree)
+	var paths []string
+	for _, n := range stats.largest {
+		paths = append(paths, n.path)
+	}
+	if got := strings.Join(paths, " "); got != "/a/a1 /b /a" {
+		t.Fatalf("expected the nodes ranked by size, got %q", got)
+	}
+
+	m := NewModel(tree)
+	m.openStatsDialog()
+	if !strings.Contains(m.statsText, "Largest nodes: top 3\n  1. /a/a1 — 30 bytes\n  2. /b — 20 bytes\n  3. /a — 10 bytes") {
+		t.Fatalf("expected the ranked list, got: %q", m.statsText)
+	}
+	if !strings.Contains(m.statsText, "Average node: 15 bytes\nBiggest node: 30 bytes at /a/a1") {
+		t.Fatalf("expected the biggest node next to the average, got: %q", m.statsText)
+	}
+}
+
+func TestStatsDialogScrollsWithinTheScreen(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	nodes := map[string]*snapshot.Node{"": root}
+	for i := 0; i < 12; i++ {
+		n := &snapshot.Node{ID: fmt.Sprint("n", i), Path: fmt.Sprint("/n", i), Parent: root, Data: make([]byte, i+1)}
+		root.Children = append(root.Children, n)
+		nodes[n.Path] = n
+	}
+	var model tea.Model = NewModel(&snapshot.Tree{Root: root, NodesByPath: nodes})
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	view := model.View()
+	if got := strings.Count(view, "\n") + 1; got != 30 {
+		t.Fatalf("expected the dialog to fit a 30-line view, got %d lines", got)
+	}
+	if !strings.Contains(view, statsScrollHint) || !strings.Contains(view, "Snapshot Statistics") {
+		t.Fatalf("expected the top of a scrollable report, got:\n%s", view)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	typed := model.(Model)
+	view = stripANSI(typed.View())
+	if !typed.statsOpen || strings.Contains(view, "Snapshot Statistics") || !strings.Contains(view, "Snapshot captured") {
+		t.Fatalf("expected the report scrolled to its end, got:\n%s", view)
+	}
+}
+
+func TestStatsCountNodesByDepth(t *testing.T) {
+	stats := collectSnapshotStats(sampleSnapshotTree())
+	if fmt.Sprint(stats.depthCounts) != "[1 2 1]" {
+		t.Fatalf("expected the root, two children and a grandchild, got %v", stats.depthCounts)
+	}
+
+	m := NewModel(sampleSnapshotTree())
+	m.openStatsDialog()
+	if !strings.Contains(m.statsText, "Nodes by depth: 2 levels\n  depth 1: 2\n  depth 2: 1\n") {
+		t.Fatalf("expected the depth breakdown, got: %q", m.statsText)
+	}
+}
+
+func TestDepthLinesSumUpDeepLevels(t *testing.T) {
+	counts := []int{1}
+	for depth := 1; depth <= depthListed+2; depth++ {
+		counts = append(counts, depth*10)
+	}
+	lines := depthLines(counts)
+	if len(lines) != depthListed+2 {
+		t.Fatalf("expected %d depth rows and a summary, got %q", depthListed, lines)
+	}
+	if last := lines[len(lines)-1]; last != "  ... and 190 nodes 9 to 10 deep" {
+		t.Fatalf("unexpected summary line %q", last)
+	}
+}
+
+func TestStatsSummarizeACLUsage(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/b"].ACLRef = -1
+
+	stats := collectSnapshotStats(tree)
+	if stats.aclCounts[0] != 2 || stats.aclCounts[1] != 1 || stats.aclCounts[-1] != 1 {
+		t.Fatalf("unexpected ACL ref counts %v", stats.aclCounts)
+	}
+
+	m := NewModel(tree)
+	m.openStatsDialog()
+	want := "ACL usage: 3 refs, 1 nodes with OPEN_ACL_UNSAFE\n" +
+		"  ACL 0: 2 nodes, no entries found\n" +
+		"  ACL -1: 1 nodes, OPEN_ACL_UNSAFE\n" +
+		"  ACL 1: 1 nodes, alice: create|read|write; scheme=world id=anyone perms=all\n"
+	if !strings.Contains(m.statsText, want) {
+		t.Fatalf("expected the ACL usage summary, got: %q", m.statsText)
+	}
+}
+
+func TestKeepLargestIsBounded(t *testing.T) {
+	var nodes []sizedNode
+	for _, size := range []int{5, 1, 9, 3, 7, 9} {
+		nodes = keepLargest(nodes, sizedNode{path: fmt.Sprint(size), size: size}, 3)
+	}
+	if len(nodes) != 3 || nodes[0].size != 9 || nodes[1].size != 9 || nodes[2].size != 7 {
+		t.Fatalf("expected the three largest sizes, got %+v", nodes)
+	}
+}
+
 func TestModelPageHomeEndNavigation(t *testing.T) {
 	root := &snapshot.Node{ID: "/", Path: ""}
 	nodes := make([]*snapshot.Node, 0, 12)
@@ -597,7 +816,7 @@ func TestModelPageHomeEndNavigation(t *testing.T) {
 
 	model := NewModel(&snapshot.Tree{Root: root})
 	var m tea.Model = model
-	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 8}) // page step = 4 rows
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 9}) // page step = 4 rows
 
 	typed := m.(Model)
 	if typed.selected != nodes[0] {
@@ -629,6 +848,396 @@ func TestModelPageHomeEndNavigation(t *testing.T) {
 	}
 }
 
+func TestModelPageNavigationClampsToRows(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	nodes := make([]*snapshot.Node, 0, 6)
+	for i := 0; i < 6; i++ {
+		n := &snapshot.Node{ID: string(rune('a' + i)), Path: "/n" + string(rune('a'+i)), Parent: root}
+		nodes = append(nodes, n)
+	}
+	root.Children = nodes
+
+	var m tea.Model = NewModel(&snapshot.Tree{Root: root})
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 9}) // page step = 4 rows
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	if typed := m.(Model); typed.selected != nodes[0] {
+		t.Fatalf("expected page up on the first row to stay there, got %q", typed.selected.Path)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	typed := m.(Model)
+	if typed.selected != nodes[len(nodes)-1] {
+		t.Fatalf("expected page down past the end to select the last row, got %q", typed.selected.Path)
+	}
+	if sel := typed.selectedRowIndex(); sel < typed.treeOffset || sel >= typed.treeOffset+typed.treeVisibleDataRows() {
+		t.Fatalf("expected the last row on screen, offset %d", typed.treeOffset)
+	}
+}
+
+func TestFilterExcludingSelectionFallsBackToVisibleNode(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	if m.selected.Path != "/a" {
+		t.Fatalf("expected initial /a selected, got %q", m.selected.Path)
+	}
+
+	m.setFilter(&treeFilter{label: "b", keep: func(n *snapshot.Node) bool { return n.ID == "b" }})
+	if m.selected.Path != "/b" {
+		t.Fatalf("expected selection to fall back to first visible row /b, got %q", m.selected.Path)
+	}
+	if m.selectedRowIndex() == -1 {
+		t.Fatal("expected fallback selection to be visible")
+	}
+	if m.contentNode != m.selected {
+		t.Fatal("expected content to follow the fallback selection")
+	}
+
+	var model tea.Model = m
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	typed := model.(Model)
+	if typed.filter != nil || len(typed.rows) != 2 {
+		t.Fatalf("expected esc to clear filter and restore rows, got %d rows", len(typed.rows))
+	}
+}
+
+func TestSessionFilterShowsNodesOfSameOwner(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a/a1"].Stat.EphemeralOwner = 42
+	m := NewModel(tree)
+	m.selectNode(tree.NodesByPath["/b"])
+
+	var model tea.Model = m
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	typed := model.(Model)
+	if typed.filter == nil || typed.filter.label != "session 0x2a" {
+		t.Fatalf("expected a session filter, got %+v", typed.filter)
+	}
+	var paths []string
+	for _, r := range typed.rows {
+		paths = append(paths, r.Node.Path)
+	}
+	if strings.Join(paths, ",") != "/a,/a/a1,/b" {
+		t.Fatalf("expected the session's nodes and their ancestors, got %v", paths)
+	}
+	if typed.filter.keep(tree.NodesByPath["/a"]) {
+		t.Fatal("expected the non-ephemeral ancestor not to match")
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	if model.(Model).filter != nil {
+		t.Fatal("expected the second press to clear the session filter")
+	}
+}
+
+func TestCollapseBelowSelectionKeepsShallowerExpansion(t *testing.T) {
+	tree := sampleSnapshotTree()
+	a1 := tree.NodesByPath["/a/a1"]
+	deep := &snapshot.Node{ID: "deep", Path: "/a/a1/deep", Parent: a1}
+	deepest := &snapshot.Node{ID: "x", Path: "/a/a1/deep/x", Parent: deep}
+	a1.Children = []*snapshot.Node{deep}
+	deep.Children = []*snapshot.Node{deepest}
+	tree.NodesByPath[deep.Path] = deep
+	tree.NodesByPath[deepest.Path] = deepest
+
+	m := NewModel(tree)
+	m.expanded["/a"] = true
+	m.expanded["/a/a1"] = true
+	m.expanded["/a/a1/deep"] = true
+	m.refreshRows()
+	m.selectNode(a1)
+
+	var model tea.Model = m
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	typed := model.(Model)
+	if !typed.expanded["/a"] || !typed.expanded["/a/a1"] {
+		t.Fatalf("expected the selection and its ancestors to stay expanded, got %v", typed.expanded)
+	}
+	if typed.expanded["/a/a1/deep"] {
+		t.Fatal("expected nodes deeper than the selection to be collapsed")
+	}
+	if _, ok := typed.rowIndex[deepest]; ok {
+		t.Fatal("expected the collapsed subtree's rows to be hidden")
+	}
+	if typed.selected != a1 {
+		t.Fatalf("expected the selection to stay on /a/a1, got %q", typed.selected.Path)
+	}
+}
+
+func TestExpandSubtreeExpandsAllDescendants(t *testing.T) {
+	tree := sampleSnapshotTree()
+	a1 := tree.NodesByPath["/a/a1"]
+	deep := &snapshot.Node{ID: "deep", Path: "/a/a1/deep", Parent: a1}
+	deepest := &snapshot.Node{ID: "x", Path: "/a/a1/deep/x", Parent: deep}
+	a1.Children = []*snapshot.Node{deep}
+	deep.Children = []*snapshot.Node{deepest}
+	tree.NodesByPath[deep.Path] = deep
+	tree.NodesByPath[deepest.Path] = deepest
+
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyShiftRight}, {Type: tea.KeyRunes, Runes: []rune("*")}} {
+		var model tea.Model = NewModel(tree)
+		model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+		model, _ = model.Update(key)
+		typed := model.(Model)
+		if !typed.expanded["/a"] || !typed.expanded["/a/a1"] || !typed.expanded["/a/a1/deep"] {
+			t.Fatalf("%s: expected the whole subtree expanded, got %v", key, typed.expanded)
+		}
+		if typed.expanded["/a/a1/deep/x"] {
+			t.Fatalf("%s: expected leaves left alone", key)
+		}
+		if _, ok := typed.rowIndex[deepest]; !ok {
+			t.Fatalf("%s: expected the deepest node visible", key)
+		}
+		if typed.selected.Path != "/a" || typed.selectedRowIndex() < typed.treeOffset {
+			t.Fatalf("%s: expected /a to stay selected and on screen, got %q", key, typed.selected.Path)
+		}
+	}
+}
+
+func TestFilterFallsBackToNearestVisibleAncestor(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	m.selectNode(m.tree.NodesByPath["/a/a1"])
+
+	m.setFilter(&treeFilter{label: "a", keep: func(n *snapshot.Node) bool { return n.ID == "a" }})
+	if m.selected.Path != "/a" {
+		t.Fatalf("expected selection to fall back to ancestor /a, got %q", m.selected.Path)
+	}
+
+	m.clearFilter()
+	if !m.expanded["/a"] {
+		t.Fatal("expected expansion state restored after clearing filter")
+	}
+}
+
+func TestRootPeekShowsRootUntilNextNavigation(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.Root.Data = []byte("root-data")
+	tree.Root.ACLRef = -1
+	m := NewModel(tree)
+	var model tea.Model = m
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("0")})
+	typed := model.(Model)
+	if typed.selected.Path != "/a" {
+		t.Fatalf("expected peek to keep selection /a, got %q", typed.selected.Path)
+	}
+	if !strings.HasPrefix(typed.renderMetadata(), "/ ID -1") {
+		t.Fatalf("expected root metadata while peeking, got: %q", typed.renderMetadata())
+	}
+	if !strings.Contains(typed.renderACL(), "OPEN_ACL_UNSAFE") {
+		t.Fatalf("expected root ACL while peeking, got: %q", typed.renderACL())
+	}
+	if got := strings.Join(typed.contentLines, "\n"); got != "root-data" {
+		t.Fatalf("expected root content while peeking, got %q", got)
+	}
+
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyDown})
+	typed = model.(Model)
+	if typed.peekRoot {
+		t.Fatal("expected navigation to end the root peek")
+	}
+	if !strings.HasPrefix(typed.renderMetadata(), "/b ID") {
+		t.Fatalf("expected metadata of the new selection, got: %q", typed.renderMetadata())
+	}
+	if got := strings.Join(typed.contentLines, "\n"); got != "<empty>" {
+		t.Fatalf("expected content of the new selection, got %q", got)
+	}
+}
+
+func TestNumberKeySelectsNthChild(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	parent := &snapshot.Node{ID: "p", Path: "/p", Parent: root}
+	for _, id := range []string{"d", "b", "c", "a"} {
+		parent.Children = append(parent.Children, &snapshot.Node{ID: id, Path: "/p/" + id, Parent: parent})
+	}
+	root.Children = []*snapshot.Node{parent}
+
+	var model tea.Model = NewModel(&snapshot.Tree{Root: root})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	typed := model.(Model)
+	if !typed.expanded["/p"] {
+		t.Fatal("expected parent expanded")
+	}
+	// Children are shown sorted by name, so the third one is "c".
+	if typed.selected.Path != "/p/c" {
+		t.Fatalf("expected third child /p/c selected, got %q", typed.selected.Path)
+	}
+
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("9")})
+	if got := model.(Model).selected.Path; got != "/p/c" {
+		t.Fatalf("expected out-of-range child key to keep selection, got %q", got)
+	}
+}
+
+func TestWrapToggleKeepsTopSourceLine(t *testing.T) {
+	lines := make([]string, 40)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line%02d %s", i, strings.Repeat("x", 100))
+	}
+	root := &snapshot.Node{ID: "/", Path: ""}
+	node := &snapshot.Node{ID: "n", Path: "/n", Parent: root, Data: []byte(strings.Join(lines, "\n"))}
+	root.Children = []*snapshot.Node{node}
+
+	var model tea.Model = NewModel(&snapshot.Tree{Root: root})
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	for i := 0; i < 5; i++ {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	typed := model.(Model)
+	if typed.contentOffset != 5 {
+		t.Fatalf("expected content scrolled to line 5, got offset %d", typed.contentOffset)
+	}
+
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	typed = model.(Model)
+	if !typed.wrapContent || len(typed.displayLines) <= len(lines) {
+		t.Fatalf("expected wrapped content, got %d display lines", len(typed.displayLines))
+	}
+	if got := typed.sourceLineAt(typed.contentOffset); got != 5 {
+		t.Fatalf("expected source line 5 at top after wrapping, got %d", got)
+	}
+	if !strings.HasPrefix(stripANSI(typed.displayLines[typed.contentOffset]), "line05 ") {
+		t.Fatalf("expected first segment of line 5 at top, got %q", typed.displayLines[typed.contentOffset])
+	}
+
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	typed = model.(Model)
+	if typed.wrapContent || typed.contentOffset != 5 {
+		t.Fatalf("expected unwrapped content at line 5, got wrap=%v offset=%d", typed.wrapContent, typed.contentOffset)
+	}
+}
+
+func TestContentOffsetClampingMatchesRenderedLines(t *testing.T) {
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line%02d %s", i, strings.Repeat("y", 90))
+	}
+	root := &snapshot.Node{ID: "/", Path: ""}
+	node := &snapshot.Node{ID: "n", Path: "/n", Parent: root, Data: []byte(strings.Join(lines, "\n"))}
+	root.Children = []*snapshot.Node{node}
+
+	var model tea.Model = NewModel(&snapshot.Tree{Root: root})
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	for i := 0; i < 200; i++ {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	typed := model.(Model)
+	_, rightOuter, _ := typed.layout()
+	height := typed.contentInnerHeight()
+	rendered := typed.renderContentWindowLines(rightOuter-2, height)
+
+	if want := len(typed.displayLines) - height; typed.contentOffset != want {
+		t.Fatalf("expected offset clamped to %d rendered lines, got %d", want, typed.contentOffset)
+	}
+	last := stripANSI(typed.displayLines[len(typed.displayLines)-1])
+	if got := stripANSI(rendered[len(rendered)-1]); !strings.HasPrefix(got, last) {
+		t.Fatalf("expected the last wrapped segment %q at the bottom, got %q", last, got)
+	}
+	for _, line := range rendered {
+		if w := lipgloss.Width(line); w > rightOuter-2 {
+			t.Fatalf("expected rendered lines within the pane width %d, got %d: %q", rightOuter-2, w, line)
+		}
+	}
+}
+
+func TestWrapANSIReappliesStyles(t *testing.T) {
+	got := wrapANSI("\x1b[31mabcdef\x1b[0mgh", 3)
+	want := []string{"\x1b[31mabc\x1b[0m", "\x1b[31mdef\x1b[0m", "gh"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d segments, got %q", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("segment %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestEpochToggleShowsRawTimestamps(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a"].Stat.Mtime = 1_700_000_000_000
+	tree.NodesByPath["/a"].Stat.Ctime = 1_600_000_000_000
+	var model tea.Model = NewModel(tree)
+
+	if strings.Contains(model.(Model).renderMetadata(), "epoch") {
+		t.Fatalf("expected no epoch values by default, got: %q", model.(Model).renderMetadata())
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	meta := model.(Model).renderMetadata()
+	if !strings.Contains(meta, "MTime: "+formatSnapshotTimeUTC(1_700_000_000_000)+" (epoch 1700000000000)") {
+		t.Fatalf("expected raw mtime epoch, got: %q", meta)
+	}
+	if !strings.Contains(meta, "(epoch 1600000000000)") {
+		t.Fatalf("expected raw ctime epoch, got: %q", meta)
+	}
+}
+
+func TestRowCountIndicatorComparesVisibleRowsToTotal(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	for i := 0; i < 3; i++ {
+		parent := &snapshot.Node{ID: fmt.Sprintf("p%d", i), Path: fmt.Sprintf("/p%d", i), Parent: root}
+		for j := 0; j < 500; j++ {
+			parent.Children = append(parent.Children, &snapshot.Node{ID: fmt.Sprintf("c%d", j), Path: fmt.Sprintf("/p%d/c%d", i, j), Parent: parent})
+		}
+		root.Children = append(root.Children, parent)
+	}
+	m := NewModel(&snapshot.Tree{Root: root})
+	m.expanded["/p0"] = true
+	m.refreshRows()
+
+	var model tea.Model = m
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("#")})
+	typed := model.(Model)
+	if got := typed.rowCountText(); got != "Showing 503 of 1,503 nodes" {
+		t.Fatalf("unexpected indicator with /p0 expanded: %q", got)
+	}
+
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	typed = model.(Model)
+	if !strings.Contains(stripANSI(typed.renderStatusBar(200)), "Showing 3 of 1,503 nodes") {
+		t.Fatalf("expected collapsed count in status bar, got %q", stripANSI(typed.renderStatusBar(200)))
+	}
+}
+
+func TestStatusBarLeadsWithSelectedPath(t *testing.T) {
+	tree := sampleSnapshotTree()
+	m := NewModel(tree)
+	m.selectNode(tree.NodesByPath["/a/a1"])
+	if got := stripANSI(m.renderStatusBar(200)); !strings.HasPrefix(got, " /a/a1 | ") {
+		t.Fatalf("expected the selected path first, got %q", got)
+	}
+
+	deep := &snapshot.Node{ID: "leaf", Path: "/" + strings.Repeat("segment/", 10) + "leaf"}
+	m.selected = deep
+	got := stripANSI(m.renderStatusBar(31))
+	if !strings.HasPrefix(got, " …") || !strings.HasSuffix(got, "segment/leaf") || lipgloss.Width(got) != 31 {
+		t.Fatalf("expected the path truncated from the left, got %q", got)
+	}
+}
+
+func TestMetadataShowsDataType(t *testing.T) {
+	tree := sampleSnapshotTree()
+	m := NewModel(tree)
+	if lines := m.renderMetadataLines(200, metadataInnerHeight); lines[4] != "Type: UTF-8 text" {
+		t.Fatalf("expected the data type line, got %q", lines)
+	}
+	m.selectNode(tree.NodesByPath["/b"])
+	if !strings.Contains(m.renderMetadata(), "\nType: empty\n") {
+		t.Fatalf("expected empty data, got %q", m.renderMetadata())
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := map[int]string{0: "0", 999: "999", 1000: "1,000", 1337: "1,337", 1234567: "1,234,567", -1500: "-1,500"}
+	for n, want := range tests {
+		if got := formatCount(n); got != want {
+			t.Fatalf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
 func sampleSnapshotTree() *snapshot.Tree {
 	root := &snapshot.Node{ID: "/", Path: ""}
 	a := &snapshot.Node{
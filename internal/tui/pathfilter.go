@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// pathFilterPrefix starts the label of path filters, followed by the regular
+// expression.
+const pathFilterPrefix = "path ~ "
+
+func (m *Model) openPathFilterPrompt() {
+	input := ""
+	if m.filter != nil && strings.HasPrefix(m.filter.label, pathFilterPrefix) {
+		input = strings.TrimPrefix(m.filter.label, pathFilterPrefix)
+	}
+	m.prompt = &prompt{
+		title: "Filter nodes by path (regular expression, empty clears)",
+		label: "Regex: ",
+		input: input,
+		submit: func(m *Model, input string) error {
+			return m.filterByPath(input)
+		},
+	}
+}
+
+// filterByPath hides every node whose path does not match expr and is not an
+// ancestor of a match. An empty expr clears the filter; an invalid one or one
+// matching nothing leaves the tree as it is.
+func (m *Model) filterByPath(expr string) error {
+	if expr == "" {
+		m.clearFilter()
+		return nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid regex: %w", err)
+	}
+	keep := func(node *snapshot.Node) bool { return re.MatchString(node.Path) }
+	if m.tree == nil || m.tree.Root == nil {
+		return errors.New("no nodes to filter")
+	}
+	matched := false
+	for _, node := range flattenAllNodes(m.tree.Root) {
+		if keep(node) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return errors.New("no node path matches " + expr)
+	}
+	m.setFilter(&treeFilter{label: pathFilterPrefix + expr, keep: keep})
+	return nil
+}
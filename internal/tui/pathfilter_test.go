@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func rowPaths(m Model) []string {
+	paths := make([]string, 0, len(m.rows))
+	for _, r := range m.rows {
+		paths = append(paths, r.Node.Path)
+	}
+	return paths
+}
+
+func TestPathFilterKeepsMatchesAndAncestors(t *testing.T) {
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a1$")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	typed := model.(Model)
+	if typed.prompt != nil {
+		t.Fatalf("expected the prompt to close, got message %q", typed.prompt.message)
+	}
+	if got := strings.Join(rowPaths(typed), ","); got != "/a,/a/a1" {
+		t.Fatalf("expected the match and its ancestor, got %s", got)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	if input := model.(Model).prompt.input; input != "a1$" {
+		t.Fatalf("expected the prompt to start with the active regex, got %q", input)
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := strings.Join(rowPaths(model.(Model)), ","); got != "/a,/b" {
+		t.Fatalf("expected clearing the filter to restore the tree, got %s", got)
+	}
+}
+
+func TestPathFilterRejectsInvalidRegexInline(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	m.expanded["/a"] = true
+	m.refreshRows()
+	before := strings.Join(rowPaths(m), ",")
+
+	var model tea.Model = m
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a(")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	typed := model.(Model)
+	if typed.prompt == nil || !strings.HasPrefix(typed.prompt.message, "invalid regex") {
+		t.Fatal("expected the prompt to stay open with the error")
+	}
+	if typed.filter != nil || strings.Join(rowPaths(typed), ",") != before {
+		t.Fatal("expected the tree to stay untouched")
+	}
+
+	if err := typed.filterByPath("^/nothing"); err == nil || typed.filter != nil {
+		t.Fatal("expected a regex without matches to be rejected")
+	}
+}
+
+func TestEmptyPathFilterClearsFilter(t *testing.T) {
+	m := NewModel(sampleSnapshotTree())
+	if err := m.filterByPath("^/b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.filterByPath(""); err != nil || m.filter != nil {
+		t.Fatalf("expected an empty regex to clear the filter, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// prompt is a single-line text input dialog. submit applies the entered
+// text; an error keeps the dialog open with the error shown. start, if set,
+// returns the command to run once the text was accepted.
+type prompt struct {
+	title   string
+	label   string
+	input   string
+	message string
+	submit  func(m *Model, input string) error
+	start   func(m *Model, input string) tea.Cmd
+}
+
+func (m Model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := *m.prompt
+	switch msg.String() {
+	case "ctrl+q":
+		return m, tea.Quit
+	case "esc":
+		m.prompt = nil
+		return m, nil
+	case "enter":
+		input := strings.TrimSpace(p.input)
+		if err := p.submit(&m, input); err != nil {
+			p.message = err.Error()
+			m.prompt = &p
+			return m, nil
+		}
+		m.prompt = nil
+		if p.start != nil {
+			return m, p.start(&m, input)
+		}
+		return m, nil
+	case "backspace", "ctrl+h":
+		if r := []rune(p.input); len(r) > 0 {
+			p.input = string(r[:len(r)-1])
+		}
+		p.message = ""
+	default:
+		if msg.Type == tea.KeyRunes {
+			p.input += string(msg.Runes)
+			p.message = ""
+		}
+	}
+	m.prompt = &p
+	return m, nil
+}
+
+func (m Model) renderPrompt(totalWidth int) string {
+	p := m.prompt
+	dialogWidth := totalWidth - 10
+	if dialogWidth < 40 {
+		dialogWidth = 40
+	}
+	if dialogWidth > 70 {
+		dialogWidth = 70
+	}
+	fieldWidth := dialogWidth - 6 - lipgloss.Width(p.label)
+	if fieldWidth < 8 {
+		fieldWidth = 8
+	}
+	lines := []string{
+		p.title,
+		"",
+		p.label + searchInputStyle.Width(fieldWidth).Render(rightCropToWidth(p.input+"█", fieldWidth)),
+		"",
+		"Enter = apply | Esc = cancel",
+	}
+	if p.message != "" {
+		lines = append(lines, p.message)
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2).
+		Width(dialogWidth).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,28 @@
+package tui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// ReloadMsg asks the program running the model to parse the snapshot again
+// and replace the model with one restoring its ViewState.
+type ReloadMsg struct{}
+
+// ReloadFailedMsg tells the model that parsing the snapshot again failed, so
+// it keeps exploring the tree it has.
+type ReloadFailedMsg struct {
+	Err error
+}
+
+// requestReload asks for the snapshot to be parsed again, unless that is
+// already underway.
+func (m *Model) requestReload() tea.Cmd {
+	if m.reloading {
+		return nil
+	}
+	m.reloading = true
+	return func() tea.Msg { return ReloadMsg{} }
+}
+
+func (m *Model) finishReloadFailed(msg ReloadFailedMsg) tea.Cmd {
+	m.reloading = false
+	return m.flashHint("reload failed: " + msg.Err.Error())
+}
@@ -0,0 +1,33 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestReloadKeyRequestsReloadOnce(t *testing.T) {
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd == nil {
+		t.Fatal("expected a reload command")
+	}
+	if _, ok := cmd().(ReloadMsg); !ok {
+		t.Fatal("expected the command to ask for a reload")
+	}
+	typed := model.(Model)
+	if !typed.reloading || !strings.Contains(typed.renderStatusBar(300), "reloading…") {
+		t.Fatal("expected a reloading indicator in the status bar")
+	}
+	if _, cmd = model.Update(tea.KeyMsg{Type: tea.KeyF5}); cmd != nil {
+		t.Fatal("expected no second reload while one is underway")
+	}
+
+	model, _ = model.Update(ReloadFailedMsg{Err: errors.New("bad magic")})
+	typed = model.(Model)
+	if typed.reloading || typed.keyHint != "reload failed: bad magic" {
+		t.Fatalf("expected the failure in the status bar, got %q", typed.keyHint)
+	}
+}
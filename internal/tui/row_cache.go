@@ -0,0 +1,72 @@
+package tui
+
+import "github.com/jowiho/zooxplorer/internal/snapshot"
+
+// rowCacheCapacity bounds the number of cached rows; the cache starts over
+// when it is full.
+const rowCacheCapacity = 4096
+
+// rowCacheContext holds the settings shared by all rows of a frame. Rows are
+// only reused while it stays the same.
+type rowCacheContext struct {
+	width      int
+	order      sortColumn
+	descending bool
+	times      timeFormatter
+	sizeWarn   int
+	wrapNames  bool
+	sizeUnit   sizeUnit
+	showMzxid  bool
+	// generation is the model's rowsGeneration, which changes whenever the
+	// rows are rebuilt.
+	generation uint64
+}
+
+// rowCacheKey identifies the rendering of one row within a context.
+type rowCacheKey struct {
+	node       *snapshot.Node
+	depth      int
+	selected   bool
+	expanded   bool
+	matchQuery string
+}
+
+// rowCache keeps the rendered lines of tree rows between frames, so moving
+// the selection only renders the rows that changed. Like contentCache it is
+// shared by pointer between copies of the model.
+type rowCache struct {
+	ctx   rowCacheContext
+	lines map[rowCacheKey][]string
+}
+
+func newRowCache() *rowCache {
+	return &rowCache{lines: make(map[rowCacheKey][]string)}
+}
+
+// prepare drops the cached rows unless they were rendered in the same
+// context.
+func (c *rowCache) prepare(ctx rowCacheContext) {
+	if c == nil || c.ctx == ctx {
+		return
+	}
+	c.ctx = ctx
+	clear(c.lines)
+}
+
+func (c *rowCache) get(key rowCacheKey) ([]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+	lines, ok := c.lines[key]
+	return lines, ok
+}
+
+func (c *rowCache) put(key rowCacheKey, lines []string) {
+	if c == nil {
+		return
+	}
+	if len(c.lines) >= rowCacheCapacity {
+		clear(c.lines)
+	}
+	c.lines[key] = lines
+}
@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+func wideSnapshotTree(children int) *snapshot.Tree {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	byPath := map[string]*snapshot.Node{"": root, "/": root}
+	for i := 0; i < children; i++ {
+		id := fmt.Sprintf("node-%05d", i)
+		child := &snapshot.Node{ID: id, Path: "/" + id, Parent: root, Data: []byte(id)}
+		root.Children = append(root.Children, child)
+		byPath[child.Path] = child
+	}
+	return &snapshot.Tree{Root: root, NodesByPath: byPath}
+}
+
+func TestRowCacheMatchesFreshRendering(t *testing.T) {
+	withANSIColors(t)
+	m := NewModel(wideSnapshotTree(50))
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m = updated.(Model)
+
+	render := func(m Model, display treeDisplay) string {
+		return strings.Join(renderTreeWindow(m.rows, m.selected, 58, m.expanded, m.sortOrder, m.sortDesc[m.sortOrder], display, nil, "", m.treeOffset, 20), "\n")
+	}
+	for i := 0; i < 5; i++ {
+		cached := render(m, m.treeDisplay())
+		fresh := m.treeDisplay()
+		fresh.rowCache = nil
+		if want := render(m, fresh); cached != want {
+			t.Fatalf("step %d: cached rendering differs:\n%s\nwant:\n%s", i, cached, want)
+		}
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = updated.(Model)
+	}
+
+	m.sizeUnit = unitKB
+	cached := render(m, m.treeDisplay())
+	if !strings.Contains(stripANSI(cached), "0.01") {
+		t.Fatalf("expected a changed display setting to bypass cached rows, got:\n%s", stripANSI(cached))
+	}
+}
+
+func TestRowCacheDropsRowsWhenMarkersChange(t *testing.T) {
+	m := NewModel(wideSnapshotTree(5))
+	fourthRow := func() string {
+		lines := renderTreeWindow(m.rows, m.selected, 100, m.expanded, m.sortOrder, m.sortDesc[m.sortOrder], m.treeDisplay(), nil, "", 0, 6)
+		return stripANSI(lines[4])
+	}
+	if strings.HasPrefix(fourthRow(), " "+markerAdded) {
+		t.Fatal("expected no baseline marker yet")
+	}
+	m.setBaseline(snapshot.BaselineDiff{Added: []*snapshot.Node{m.rows[3].Node}})
+	if got := fourthRow(); !strings.HasPrefix(got, " "+markerAdded) {
+		t.Fatalf("expected the new baseline marker despite cached rows, got %q", got)
+	}
+}
+
+func benchmarkTreeScroll(b *testing.B, cached bool) {
+	var model tea.Model = NewModel(wideSnapshotTree(5000))
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+	if !cached {
+		m := model.(Model)
+		m.rowCache = nil
+		model = m
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := tea.KeyMsg{Type: tea.KeyDown}
+		if i/200%2 == 1 {
+			key = tea.KeyMsg{Type: tea.KeyUp}
+		}
+		model, _ = model.Update(key)
+		_ = model.View()
+	}
+}
+
+func BenchmarkTreeScrollCached(b *testing.B)   { benchmarkTreeScroll(b, true) }
+func BenchmarkTreeScrollUncached(b *testing.B) { benchmarkTreeScroll(b, false) }
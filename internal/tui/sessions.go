@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// Limits of the session expiry dialog.
+const (
+	maxSessionLines = 10
+	maxSessionNodes = 5
+)
+
+// sessionProjection is when a session would expire if its client sent no
+// more heartbeats after the snapshot, with the ephemeral nodes that would be
+// deleted along with it.
+type sessionProjection struct {
+	id int64
+	// timeout is in milliseconds; 0 when the owner is missing from the
+	// session table, i.e. the session was already gone.
+	timeout int32
+	known   bool
+	// expires is the projected expiry in epoch milliseconds.
+	expires int64
+	paths   []string
+}
+
+// projectSessionExpiry projects the expiry of every session in the tree and
+// of every ephemeral owner missing from the session table, the soonest first.
+// capturedAt approximates when the snapshot was taken.
+func projectSessionExpiry(tree *snapshot.Tree, capturedAt int64) []sessionProjection {
+	byID := make(map[int64]*sessionProjection, len(tree.Sessions))
+	for id, timeout := range tree.Sessions {
+		byID[id] = &sessionProjection{id: id, timeout: timeout, known: true, expires: capturedAt + int64(timeout)}
+	}
+	if tree.Root != nil {
+		for _, node := range flattenAllNodes(tree.Root) {
+			owner := node.Stat.EphemeralOwner
+			if owner == 0 {
+				continue
+			}
+			p, ok := byID[owner]
+			if !ok {
+				p = &sessionProjection{id: owner, expires: capturedAt}
+				byID[owner] = p
+			}
+			p.paths = append(p.paths, printablePath(node.Path))
+		}
+	}
+	out := make([]sessionProjection, 0, len(byID))
+	for _, p := range byID {
+		sort.Strings(p.paths)
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].expires != out[j].expires {
+			return out[i].expires < out[j].expires
+		}
+		return out[i].id < out[j].id
+	})
+	return out
+}
+
+func (m *Model) openSessionsDialog() {
+	if m.tree == nil || m.tree.Root == nil {
+		return
+	}
+	capturedAt := newestWrite(m.tree.Root)
+	lines := []string{"Session Expiry Projection", "", capturedLine(capturedAt, m.times), ""}
+	projections := projectSessionExpiry(m.tree, capturedAt)
+	if len(projections) == 0 {
+		lines = append(lines, "No sessions or ephemeral nodes.")
+	}
+	for i, p := range projections {
+		if i == maxSessionLines {
+			lines = append(lines, fmt.Sprintf("... and %d more sessions", len(projections)-maxSessionLines))
+			break
+		}
+		expiry := "not in session table, already expired"
+		if p.known {
+			timeout := time.Duration(p.timeout) * time.Millisecond
+			expiry = fmt.Sprintf("timeout %s, expires %s", timeout, m.times.format(p.expires))
+			if capturedAt <= 0 {
+				expiry = fmt.Sprintf("timeout %s, expires %s after capture", timeout, timeout)
+			}
+		}
+		lines = append(lines, fmt.Sprintf("0x%x  %s  %d ephemeral nodes", p.id, expiry, len(p.paths)))
+		for j, path := range p.paths {
+			if j == maxSessionNodes {
+				lines = append(lines, fmt.Sprintf("  ... and %d more", len(p.paths)-maxSessionNodes))
+				break
+			}
+			lines = append(lines, "  "+path)
+		}
+	}
+	lines = append(lines, "", "Press any key to close.")
+	m.sessionsText = strings.Join(lines, "\n")
+	m.sessionsOpen = true
+}
+
+func (m Model) renderSessionsDialog() string {
+	lines := strings.Split(m.sessionsText, "\n")
+	for i := range lines {
+		lines[i] = truncate(lines[i], m.screenWidth()-8)
+	}
+	lines[0] = statsLabelStyle.Render(lines[0])
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestProjectSessionExpiry(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a/a1"].Stat.EphemeralOwner = 7
+	tree.Sessions = map[int64]int32{42: 30000, 99: 10000}
+
+	got := projectSessionExpiry(tree, 1_000_000)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 projections, got %+v", got)
+	}
+	if got[0].id != 7 || got[0].known || got[0].expires != 1_000_000 || strings.Join(got[0].paths, ",") != "/a/a1" {
+		t.Fatalf("expected the unknown owner of /a/a1 first, got %+v", got[0])
+	}
+	if got[1].id != 99 || got[1].expires != 1_010_000 || len(got[1].paths) != 0 {
+		t.Fatalf("expected session 0x63 without nodes next, got %+v", got[1])
+	}
+	if got[2].id != 42 || got[2].expires != 1_030_000 || strings.Join(got[2].paths, ",") != "/b" {
+		t.Fatalf("expected /b at risk with session 0x2a, got %+v", got[2])
+	}
+}
+
+func TestSessionsDialog(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.Sessions = map[int64]int32{42: 30000}
+	tree.NodesByPath["/a"].Stat.Mtime = 1_700_000_000_000
+	var model tea.Model = NewModel(tree)
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	typed := model.(Model)
+	if !typed.sessionsOpen {
+		t.Fatal("expected the session dialog open")
+	}
+	want := "0x2a  timeout 30s, expires 2023-11-14T22:13:50Z  1 ephemeral nodes\n  /b"
+	if !strings.Contains(typed.sessionsText, want) {
+		t.Fatalf("expected %q in the dialog, got:\n%s", want, typed.sessionsText)
+	}
+
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if typed = model.(Model); typed.sessionsOpen || typed.selected.Path != "/a" {
+		t.Fatal("expected any key to close the dialog without navigating")
+	}
+}
+
+func TestSessionsDialogUsesTheScreenWidth(t *testing.T) {
+	tree := sampleSnapshotTree()
+	path := "/" + strings.Repeat("ephemeral-", 12)
+	tree.NodesByPath["/b"].Path = path
+	tree.Sessions = map[int64]int32{42: 30000}
+	var model tea.Model = NewModel(tree)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	if view := model.(Model).renderSessionsDialog(); !strings.Contains(view, path) {
+		t.Fatalf("expected the full %d-character path in the dialog, got:\n%s", len(path), view)
+	}
+}
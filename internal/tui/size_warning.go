@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// defaultSizeWarning matches ZooKeeper's default jute.maxbuffer: nodes of
+// this size are about to be rejected by the server.
+const defaultSizeWarning = 1024 * 1024
+
+func (m *Model) openSizeWarningPrompt() {
+	input := ""
+	if m.sizeWarning > 0 {
+		input = formatSize(m.sizeWarning)
+	}
+	m.prompt = &prompt{
+		title: "Warn about nodes of at least (e.g. 512K, 1M; 0 = off)",
+		label: "Size: ",
+		input: input,
+		submit: func(m *Model, input string) error {
+			size, err := parseSize(input)
+			if err != nil {
+				return err
+			}
+			m.sizeWarning = size
+			return nil
+		},
+	}
+}
+
+func (m *Model) openMinSizePrompt() {
+	input := ""
+	if m.minDataSize > 0 {
+		input = formatSize(m.minDataSize)
+	}
+	m.prompt = &prompt{
+		title: "Show only nodes with data of at least (e.g. 64K, 1M; 0 = all)",
+		label: "Size: ",
+		input: input,
+		submit: func(m *Model, input string) error {
+			size, err := parseSize(input)
+			if err != nil {
+				return err
+			}
+			m.filterByMinSize(size)
+			return nil
+		},
+	}
+}
+
+// filterByMinSize lists only the nodes with at least size bytes of data,
+// biggest first; zero clears the filter.
+func (m *Model) filterByMinSize(size int) {
+	if size <= 0 {
+		m.clearFilter()
+		return
+	}
+	m.setFilter(&treeFilter{
+		label: "size >= " + formatSize(size),
+		keep:  func(node *snapshot.Node) bool { return node.DataLen() >= size },
+	})
+	m.minDataSize = size
+	if m.sortOrder != sortByNodeSize {
+		m.sortOrder = sortByNodeSize
+		m.sortDesc[sortByNodeSize] = true
+		m.refreshRows()
+		m.adjustTreeOffset()
+	}
+}
+
+// parseSize parses a byte count with an optional K, M or G suffix (powers of
+// 1024), e.g. "512", "64K", "1.5M". Empty input means zero.
+func parseSize(input string) (int, error) {
+	s := strings.ToUpper(strings.TrimSpace(input))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+	if s == "" {
+		return 0, nil
+	}
+	multiplier := 1.0
+	switch s[len(s)-1] {
+	case 'K':
+		multiplier = 1 << 10
+	case 'M':
+		multiplier = 1 << 20
+	case 'G':
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		s = s[:len(s)-1]
+	}
+	// Only plain decimals: ParseFloat would also take signs, exponents, hex
+	// floats, "inf" and "nan", none of which make a byte count.
+	s = strings.TrimSpace(s)
+	if strings.Trim(s, "0123456789.") != "" {
+		return 0, fmt.Errorf("invalid size %q", input)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", input)
+	}
+	if v*multiplier >= math.MaxInt {
+		return 0, fmt.Errorf("size %q is too large", input)
+	}
+	return int(v * multiplier), nil
+}
+
+// formatSize renders a byte count the way parseSize accepts it, using the
+// largest unit that divides it evenly.
+func formatSize(n int) string {
+	for _, u := range []struct {
+		size   int
+		suffix string
+	}{{1 << 30, "G"}, {1 << 20, "M"}, {1 << 10, "K"}} {
+		if n >= u.size && n%u.size == 0 {
+			return strconv.Itoa(n/u.size) + u.suffix
+		}
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+func TestOversizedNodesGetWarningStyle(t *testing.T) {
+	withANSIColors(t)
+	root := &snapshot.Node{ID: "/", Path: ""}
+	big := &snapshot.Node{ID: "big", Path: "/big", Parent: root, Data: make([]byte, 2048)}
+	small := &snapshot.Node{ID: "small", Path: "/small", Parent: root, Data: make([]byte, 10)}
+	root.Children = []*snapshot.Node{big, small}
+	rows := flatten(root, map[string]bool{}, sortByNodeName, false)
+
+	display := treeDisplay{times: defaultTimeFormatter, sizeWarning: 1024}
+	lines := renderTreeWindow(rows, nil, 120, map[string]bool{}, sortByNodeName, false, display, nil, "", 0, 3)
+	warning := oversizedStyle.Render(markerOversized + " 2048")
+	if !strings.Contains(lines[1], oversizedStyle.Render("big")) || !strings.Contains(lines[1], warning) {
+		t.Fatalf("expected oversized node's name and size to carry the warning style, got %q", lines[1])
+	}
+	if strings.Contains(lines[2], oversizedStyle.Render("small")) || strings.Contains(stripANSI(lines[2]), markerOversized+" ") || strings.Contains(lines[2], oversizedStyle.Render("10")) {
+		t.Fatalf("expected small node without warning, got %q", lines[2])
+	}
+
+	display.sizeWarning = 0
+	lines = renderTreeWindow(rows, nil, 120, map[string]bool{}, sortByNodeName, false, display, nil, "", 0, 3)
+	if strings.Contains(stripANSI(lines[1]), markerOversized+" ") {
+		t.Fatalf("expected no warning when disabled, got %q", lines[1])
+	}
+}
+
+func TestOversizedSizeLabelFitsTheColumn(t *testing.T) {
+	_, nodeW, _, _, _, _ := tableColumnWidths(120)
+	if got := sizeLabel([]string{markerOversized}, 2048, unitBytes, nodeW); got != markerOversized+" 2048" {
+		t.Fatalf("expected the exact size while it fits, got %q", got)
+	}
+	for _, unit := range []sizeUnit{unitBytes, unitKB, unitMB} {
+		got := sizeLabel([]string{markerOversized}, 2<<30, unit, nodeW)
+		if len(got) > nodeW {
+			t.Fatalf("expected at most %d cells in %v, got %q", nodeW, unit, got)
+		}
+	}
+	if got := sizeLabel([]string{markerOversized}, 2<<30, unitBytes, nodeW); got != markerOversized+" 2.0G" {
+		t.Fatalf("expected a compact size, got %q", got)
+	}
+}
+
+func TestSizeWarningPromptSetsThreshold(t *testing.T) {
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	if model.(Model).sizeWarning != defaultSizeWarning {
+		t.Fatal("expected the 1MB default threshold")
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("W")})
+	typed := model.(Model)
+	if typed.prompt == nil || typed.prompt.input != "1M" {
+		t.Fatalf("expected prompt prefilled with 1M, got %+v", typed.prompt)
+	}
+	typed.prompt.input = ""
+	model, _ = typed.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if typed = model.(Model); typed.prompt == nil || typed.prompt.message == "" {
+		t.Fatal("expected invalid input to keep the prompt open with an error")
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("512K")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if typed = model.(Model); typed.prompt != nil || typed.sizeWarning != 512*1024 {
+		t.Fatalf("expected 512K threshold applied, got %d", typed.sizeWarning)
+	}
+}
+
+func TestMinSizeFilterListsBigNodesFlat(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a/a1"].Data = make([]byte, 2048)
+	tree.NodesByPath["/b"].Data = make([]byte, 1024)
+	var model tea.Model = NewModel(tree)
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("M")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1K")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	typed := model.(Model)
+	if typed.minDataSize != 1024 || typed.sortOrder != sortByNodeSize {
+		t.Fatalf("expected a 1K threshold sorted by size, got %d and order %v", typed.minDataSize, typed.sortOrder)
+	}
+	if len(typed.rows) != 2 || typed.rows[0].Node.Path != "/a/a1" || typed.rows[1].Node.Path != "/b" {
+		t.Fatalf("expected the big nodes biggest first, got %d rows", len(typed.rows))
+	}
+	if !strings.Contains(typed.renderStatusBar(300), "size >= 1K") {
+		t.Fatal("expected the filter in the status bar")
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("M")})
+	if input := model.(Model).prompt.input; input != "1K" {
+		t.Fatalf("expected the prompt prefilled with the threshold, got %q", input)
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if typed = model.(Model); typed.filter != nil || typed.minDataSize != 0 || len(typed.rows) != 3 {
+		t.Fatalf("expected Esc to clear the threshold, got %d rows", len(typed.rows))
+	}
+}
+
+func TestMinSizeFilterRejectsNonFiniteSizes(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/b"].Data = make([]byte, 1024)
+	var model tea.Model = NewModel(tree)
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("M")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1K")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	for _, in := range []string{"nan", "inf"} {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("M")})
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(in)})
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		typed := model.(Model)
+		if typed.prompt == nil || typed.prompt.message == "" {
+			t.Fatalf("expected an error for %q", in)
+		}
+		if typed.filter == nil || typed.minDataSize != 1024 {
+			t.Fatalf("expected %q to keep the 1K filter, got %d", in, typed.minDataSize)
+		}
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int{
+		"":      0,
+		"512":   512,
+		"64K":   64 * 1024,
+		"1m":    1024 * 1024,
+		"1.5M":  1536 * 1024,
+		"2GiB":  2 << 30,
+		"10 KB": 10 * 1024,
+	}
+	for in, want := range tests {
+		got, err := parseSize(in)
+		if err != nil || got != want {
+			t.Fatalf("parseSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"abc", "-1", "1X", "inf", "nan", "1e400", "-0", "0x1p4", "1.2.3", "1e30G", "99999999999G"} {
+		if _, err := parseSize(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// ViewState is where the user was in a snapshot: the expanded nodes and the
+// selected one, by path. It is kept between runs; the back/forward history
+// only carries over a reload.
+type ViewState struct {
+	Expanded     []string `json:"expanded"`
+	Selected     string   `json:"selected"`
+	History      []string `json:"-"`
+	HistoryIndex int      `json:"-"`
+}
+
+// ViewState returns the current expanded paths, sorted, and the selected
+// path.
+func (m Model) ViewState() ViewState {
+	state := ViewState{Expanded: make([]string, 0, len(m.expanded))}
+	for path, open := range m.expanded {
+		if open {
+			state.Expanded = append(state.Expanded, path)
+		}
+	}
+	sort.Strings(state.Expanded)
+	if m.selected != nil {
+		state.Selected = m.selected.Path
+	}
+	state.History = m.historyPaths()
+	state.HistoryIndex = m.historyIndex
+	return state
+}
+
+// applyViewState expands and selects the nodes of state that tree still has,
+// ignoring the paths it no longer has.
+func (m *Model) applyViewState(tree *snapshot.Tree, state *ViewState) {
+	for _, path := range state.Expanded {
+		if node := tree.NodesByPath[path]; node != nil && len(node.Children) > 0 {
+			m.expanded[path] = true
+		}
+	}
+	m.refreshRows()
+	m.restoreHistory(tree, state.History, state.HistoryIndex)
+	if node := tree.NodesByPath[state.Selected]; node != nil && node != tree.Root {
+		m.selectNode(node)
+	}
+}
+
+// DefaultStateFile returns where view states are kept:
+// $XDG_STATE_HOME/zooxplorer/state.json, with XDG_STATE_HOME defaulting to
+// ~/.local/state.
+func DefaultStateFile() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("locate state directory: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "zooxplorer", "state.json"), nil
+}
+
+// LoadViewState reads the view state of snapshotPath from file. It returns
+// nil without an error when file does not exist or has no state for the
+// snapshot.
+func LoadViewState(file, snapshotPath string) (*ViewState, error) {
+	states, err := readViewStates(file)
+	if err != nil {
+		return nil, err
+	}
+	state, ok := states[snapshotPath]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// SaveViewState records state for snapshotPath in file, keeping the states
+// of other snapshots.
+func SaveViewState(file, snapshotPath string, state ViewState) error {
+	states, err := readViewStates(file)
+	if err != nil {
+		// Start over rather than never saving again.
+		states = make(map[string]ViewState)
+	}
+	states[snapshotPath] = state
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode view state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("write view state: %w", err)
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write view state: %w", err)
+	}
+	return nil
+}
+
+func readViewStates(file string) (map[string]ViewState, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]ViewState), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read view state: %w", err)
+	}
+	var states map[string]ViewState
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("parse view state: %w", err)
+	}
+	if states == nil {
+		states = make(map[string]ViewState)
+	}
+	return states, nil
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestViewStateRoundTripsThroughModel(t *testing.T) {
+	tree := sampleSnapshotTree()
+	m := NewModel(tree)
+	m.selectNode(tree.NodesByPath["/a/a1"])
+	state := m.ViewState()
+	if !reflect.DeepEqual(state, ViewState{Expanded: []string{"/a"}, Selected: "/a/a1"}) {
+		t.Fatalf("unexpected view state %+v", state)
+	}
+
+	restored := NewModelWithOptions(sampleSnapshotTree(), Options{State: &state})
+	if restored.selected.Path != "/a/a1" || !restored.expanded["/a"] || restored.selectedRowIndex() == -1 {
+		t.Fatalf("expected /a/a1 selected and visible, got %q", restored.selected.Path)
+	}
+}
+
+func TestViewStateIgnoresMissingPaths(t *testing.T) {
+	state := &ViewState{Expanded: []string{"/gone", "/a"}, Selected: "/gone/child"}
+	m := NewModelWithOptions(sampleSnapshotTree(), Options{State: state})
+	if m.expanded["/gone"] || m.selected.Path != "/a" {
+		t.Fatalf("expected missing paths ignored, got expanded %v and selection %q", m.expanded, m.selected.Path)
+	}
+	if !m.expanded["/a"] {
+		t.Fatal("expected existing paths to be expanded")
+	}
+
+	m = NewModelWithOptions(sampleSnapshotTree(), Options{State: &ViewState{Selected: "/a/a1"}, StartPath: "/b"})
+	if m.selected.Path != "/b" {
+		t.Fatalf("expected StartPath to win over the saved selection, got %q", m.selected.Path)
+	}
+}
+
+func TestSaveAndLoadViewStateBySnapshot(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "zooxplorer", "state.json")
+	if state, err := LoadViewState(file, "/data/snapshot.1"); err != nil || state != nil {
+		t.Fatalf("expected no state before saving, got %+v, %v", state, err)
+	}
+
+	one := ViewState{Expanded: []string{"/a"}, Selected: "/a/a1"}
+	two := ViewState{Selected: "/b"}
+	if err := SaveViewState(file, "/data/snapshot.1", one); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveViewState(file, "/data/snapshot.2", two); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadViewState(file, "/data/snapshot.1")
+	if err != nil || !reflect.DeepEqual(*got, one) {
+		t.Fatalf("expected the first snapshot's state, got %+v, %v", got, err)
+	}
+	if got, _ := LoadViewState(file, "/data/snapshot.2"); got == nil || got.Selected != "/b" {
+		t.Fatalf("expected the second snapshot's state, got %+v", got)
+	}
+
+	if err := os.WriteFile(file, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadViewState(file, "/data/snapshot.1"); err == nil {
+		t.Fatal("expected a corrupt state file to be reported")
+	}
+	if err := SaveViewState(file, "/data/snapshot.1", one); err != nil {
+		t.Fatalf("expected saving to replace a corrupt file, got %v", err)
+	}
+}
+
+func TestDefaultStateFileFollowsXDG(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/state")
+	if got, err := DefaultStateFile(); err != nil || got != "/tmp/state/zooxplorer/state.json" {
+		t.Fatalf("unexpected state file %q, %v", got, err)
+	}
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", "/home/someone")
+	if got, err := DefaultStateFile(); err != nil || got != "/home/someone/.local/state/zooxplorer/state.json" {
+		t.Fatalf("unexpected state file %q, %v", got, err)
+	}
+}
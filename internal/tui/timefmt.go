@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jowiho/zooxplorer/internal/snapshot"
+)
+
+// timeFormatter formats snapshot timestamps. The tree table, the metadata
+// pane and the stats dialog share one so that they always agree.
+type timeFormatter struct {
+	layout     string
+	location   *time.Location
+	mode       timeMode
+	capturedAt int64
+	// now is the wall clock in epoch milliseconds when timeAgo was picked,
+	// fixed so that cached rows stay valid.
+	now int64
+}
+
+// timeMode selects how timestamps are rendered.
+type timeMode int
+
+const (
+	// timeAbsolute renders timestamps with the layout and location.
+	timeAbsolute timeMode = iota
+	// timeSinceCapture renders how long before the capture, e.g. "3d4h before".
+	timeSinceCapture
+	// timeAgo renders how long before the wall clock, e.g. "3h ago".
+	timeAgo
+)
+
+var defaultTimeFormatter = timeFormatter{layout: time.RFC3339, location: time.UTC}
+
+// invalidTime is shown for timestamps that cannot be real, which happens in
+// corrupt snapshots.
+const invalidTime = "(invalid)"
+
+// maxValidTime bounds plausible timestamps: far past any real snapshot, and
+// before years stop fitting in four digits.
+var maxValidTime = time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+func (f timeFormatter) format(epochMillis int64) string {
+	if epochMillis < 0 || epochMillis >= maxValidTime {
+		return invalidTime
+	}
+	switch f.mode {
+	case timeSinceCapture:
+		return formatRelativeTime(time.Duration(f.capturedAt-epochMillis) * time.Millisecond)
+	case timeAgo:
+		return formatRelative(epochMillis, time.UnixMilli(f.now))
+	}
+	location := f.location
+	if location == nil {
+		location = time.UTC
+	}
+	return time.UnixMilli(epochMillis).In(location).Format(f.layout)
+}
+
+// formatRelativeTime renders how long before the snapshot capture something
+// happened, using the two largest units, e.g. "3d4h before".
+func formatRelativeTime(d time.Duration) string {
+	suffix := "before"
+	if d < 0 {
+		d = -d
+		suffix = "after"
+	}
+	if d < time.Second {
+		return "at capture"
+	}
+	return formatDuration(d) + " " + suffix
+}
+
+// formatRelative renders how long before now epochMillis was, using the
+// largest unit, e.g. "3h ago" or "12d ago". It stays well within the
+// Modified column.
+func formatRelative(epochMillis int64, now time.Time) string {
+	d := now.Sub(time.UnixMilli(epochMillis))
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < time.Second {
+		return "just now"
+	}
+	ago := largestUnit(d)
+	if future {
+		return "in " + ago
+	}
+	return ago + " ago"
+}
+
+type durationUnit struct {
+	size  time.Duration
+	label string
+}
+
+var durationUnits = []durationUnit{
+	{24 * time.Hour, "d"},
+	{time.Hour, "h"},
+	{time.Minute, "m"},
+	{time.Second, "s"},
+}
+
+// formatDuration renders d, of at least a second, in its two largest units.
+func formatDuration(d time.Duration) string {
+	out := ""
+	parts := 0
+	for _, u := range durationUnits {
+		if parts == 2 {
+			break
+		}
+		n := d / u.size
+		if n == 0 && parts == 0 {
+			continue
+		}
+		if n > 0 {
+			out += fmt.Sprintf("%d%s", n, u.label)
+		}
+		d -= n * u.size
+		parts++
+	}
+	return out
+}
+
+// largestUnit renders d, of at least a second, in its largest unit only.
+func largestUnit(d time.Duration) string {
+	for _, u := range durationUnits {
+		if n := d / u.size; n > 0 {
+			return fmt.Sprintf("%d%s", n, u.label)
+		}
+	}
+	return "0s"
+}
+
+// cycleTimeMode switches every displayed timestamp from absolute times to
+// times relative to the snapshot capture, to times relative to now, and back.
+func (m *Model) cycleTimeMode() {
+	m.times.mode = (m.times.mode + 1) % (timeAgo + 1)
+	switch m.times.mode {
+	case timeSinceCapture:
+		if m.tree != nil {
+			m.times.capturedAt = newestWrite(m.tree.Root)
+		}
+	case timeAgo:
+		m.times.now = time.Now().UnixMilli()
+	}
+}
+
+// toggleLocalTimes switches every displayed timestamp between UTC and the
+// local time zone.
+func (m *Model) toggleLocalTimes() {
+	if m.times.location == time.Local {
+		m.times.location = time.UTC
+	} else {
+		m.times.location = time.Local
+	}
+}
+
+// absolute returns f showing absolute times even in relative mode, for
+// timestamps that are the reference point themselves.
+func (f timeFormatter) absolute() timeFormatter {
+	f.mode = timeAbsolute
+	return f
+}
+
+func formatSnapshotTimeUTC(epochMillis int64) string {
+	return defaultTimeFormatter.format(epochMillis)
+}
+
+// newestWrite returns the latest ctime or mtime of any node under root,
+// ignoring invalid timestamps.
+func newestWrite(root *snapshot.Node) int64 {
+	var newest int64
+	var walk func(node *snapshot.Node)
+	walk = func(node *snapshot.Node) {
+		for _, t := range []int64{node.Stat.Ctime, node.Stat.Mtime} {
+			if t > newest && t < maxValidTime {
+				newest = t
+			}
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	if root != nil {
+		walk(root)
+	}
+	return newest
+}
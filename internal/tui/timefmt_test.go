@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTreeAndMetadataShareTimeFormatter(t *testing.T) {
+	tree := sampleSnapshotTree()
+	a := tree.NodesByPath["/a"]
+	a.Stat.Mtime = 1_700_000_000_000
+	tree.NodesByPath["/b"].Stat.Mtime = 1_700_000_090_000
+	var model tea.Model = NewModel(tree)
+
+	check := func(m Model, want string) {
+		t.Helper()
+		if !strings.Contains(m.renderMetadata(), "MTime: "+want) {
+			t.Fatalf("expected metadata mtime %q, got: %q", want, m.renderMetadata())
+		}
+		lines := renderTreeWindow(m.rows, nil, 120, m.expanded, m.sortOrder, false, treeDisplay{times: m.times}, nil, "", 0, len(m.rows)+1)
+		if !strings.Contains(stripANSI(lines[1]), want) {
+			t.Fatalf("expected tree mtime %q, got: %q", want, stripANSI(lines[1]))
+		}
+	}
+
+	check(model.(Model), formatSnapshotTimeUTC(a.Stat.Mtime))
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	typed := model.(Model)
+	if want := typed.times.format(a.Stat.Mtime); want != "1m30s before" {
+		t.Fatalf("expected relative time, got %q", want)
+	}
+	check(typed, "1m30s before")
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	typed = model.(Model)
+	if typed.times.mode != timeAgo {
+		t.Fatalf("expected T to cycle to times relative to now, got mode %d", typed.times.mode)
+	}
+	check(typed, formatRelative(a.Stat.Mtime, time.UnixMilli(typed.times.now)))
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	check(model.(Model), formatSnapshotTimeUTC(a.Stat.Mtime))
+}
+
+func TestFormatRelative(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	tests := map[time.Duration]string{
+		0:                           "just now",
+		90 * time.Second:            "1m ago",
+		3*time.Hour + time.Minute:   "3h ago",
+		12*24*time.Hour + time.Hour: "12d ago",
+		-2 * time.Minute:            "in 2m",
+	}
+	for d, want := range tests {
+		got := formatRelative(now.Add(-d).UnixMilli(), now)
+		if got != want {
+			t.Fatalf("formatRelative(now-%v) = %q, want %q", d, got, want)
+		}
+	}
+	_, _, _, _, _, modifiedW := tableColumnWidths(120)
+	if got := formatRelative(0, time.UnixMilli(maxValidTime)); len(got) > modifiedW {
+		t.Fatalf("expected %q to fit the Modified column", got)
+	}
+}
+
+func TestToggleLocalTimes(t *testing.T) {
+	saved := time.Local
+	time.Local = time.FixedZone("TEST", 2*60*60)
+	defer func() { time.Local = saved }()
+
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a"].Stat.Mtime = 1_700_000_000_000
+	var model tea.Model = NewModel(tree)
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	typed := model.(Model)
+	const local = "2023-11-15T00:13:20+02:00"
+	if !strings.Contains(typed.renderMetadata(), "MTime: "+local) {
+		t.Fatalf("expected local metadata mtime, got: %q", typed.renderMetadata())
+	}
+	lines := renderTreeWindow(typed.rows, nil, 120, typed.expanded, typed.sortOrder, false, typed.treeDisplay(), nil, "", 0, len(typed.rows)+1)
+	if !strings.Contains(stripANSI(lines[1]), local) {
+		t.Fatalf("expected local tree mtime, got: %q", stripANSI(lines[1]))
+	}
+	if !strings.Contains(typed.renderStatusBar(300), "Times in local time") {
+		t.Fatal("expected the status bar to show local time mode")
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	typed = model.(Model)
+	if !strings.Contains(typed.renderMetadata(), "MTime: 2023-11-14T22:13:20Z") || strings.Contains(typed.renderStatusBar(300), "local time") {
+		t.Fatal("expected Z to switch back to UTC")
+	}
+}
+
+func TestFormatRelativeTime(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                                  "at capture",
+		90 * time.Second:                   "1m30s before",
+		(3*24+4)*time.Hour + 5*time.Minute: "3d4h before",
+		2 * time.Hour:                      "2h before",
+		-5 * time.Second:                   "5s after",
+	}
+	for d, want := range tests {
+		if got := formatRelativeTime(d); got != want {
+			t.Fatalf("formatRelativeTime(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestInvalidTimestampsAreNotFormattedAsDates(t *testing.T) {
+	relative := timeFormatter{mode: timeSinceCapture, capturedAt: 1_700_000_000_000}
+	ago := timeFormatter{mode: timeAgo, now: 1_700_000_000_000}
+	for _, ms := range []int64{-1, -1_700_000_000_000, 1 << 62, 253402300800000} {
+		if got := formatSnapshotTimeUTC(ms); got != invalidTime {
+			t.Fatalf("formatSnapshotTimeUTC(%d) = %q, want %q", ms, got, invalidTime)
+		}
+		if got := relative.format(ms); got != invalidTime {
+			t.Fatalf("relative format(%d) = %q, want %q", ms, got, invalidTime)
+		}
+		if got := ago.format(ms); got != invalidTime {
+			t.Fatalf("ago format(%d) = %q, want %q", ms, got, invalidTime)
+		}
+	}
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a"].Stat.Mtime = 1_700_000_000_000
+	tree.NodesByPath["/b"].Stat.Mtime = 1 << 62
+	if got := newestWrite(tree.Root); got != 1_700_000_000_000 {
+		t.Fatalf("expected capture time to ignore invalid timestamps, got %d", got)
+	}
+	if got := formatSnapshotTimeUTC(0); got != "1970-01-01T00:00:00Z" {
+		t.Fatalf("expected the epoch itself to stay valid, got %q", got)
+	}
+}
+
+func TestTreeShowsInvalidModifiedTime(t *testing.T) {
+	tree := sampleSnapshotTree()
+	tree.NodesByPath["/a"].Stat.Mtime = -42
+	m := NewModel(tree)
+	lines := renderTreeWindow(m.rows, nil, 120, m.expanded, m.sortOrder, false, treeDisplay{times: m.times}, nil, "", 0, 3)
+	if !strings.Contains(stripANSI(lines[1]), invalidTime) {
+		t.Fatalf("expected invalid mtime in tree, got %q", stripANSI(lines[1]))
+	}
+	if !strings.Contains(m.renderMetadata(), "MTime: "+invalidTime) {
+		t.Fatalf("expected invalid mtime in metadata, got %q", m.renderMetadata())
+	}
+}
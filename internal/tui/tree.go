@@ -3,8 +3,8 @@ package tui
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jowiho/zooxplorer/internal/snapshot"
@@ -22,26 +22,45 @@ const (
 	sortByNodeSize
 	sortBySubtreeSize
 	sortByChildren
+	sortByDescendants
 	sortByModified
+	sortByCreated
+	sortByVersion
 )
 
-type treeMetrics struct {
-	nodeSize    int
-	subtreeSize int
+// sortColumnCount is the number of sort columns ctrl+o cycles through.
+const sortColumnCount = sortByVersion + 1
+
+// treeFilter restricts the tree to nodes accepted by keep. In hierarchical
+// modes the ancestors of accepted nodes stay visible so matches remain
+// reachable.
+type treeFilter struct {
+	label string
+	keep  func(node *snapshot.Node) bool
+}
+
+func flatten(root *snapshot.Node, expanded map[string]bool, order sortColumn, descending bool) []row {
+	return flattenFiltered(root, expanded, order, descending, nil)
 }
 
-func flatten(root *snapshot.Node, expanded map[string]bool, order sortColumn, descending bool, metrics map[*snapshot.Node]treeMetrics) []row {
+func flattenFiltered(root *snapshot.Node, expanded map[string]bool, order sortColumn, descending bool, filter *treeFilter) []row {
 	if root == nil {
 		return nil
 	}
-	if metrics == nil {
-		metrics = buildTreeMetrics(root)
-	}
 
-	if order == sortByNodeSize || order == sortByModified {
+	if isFlatMode(order) {
 		all := flattenAllNodes(root)
+		if filter != nil {
+			kept := all[:0]
+			for _, node := range all {
+				if filter.keep(node) {
+					kept = append(kept, node)
+				}
+			}
+			all = kept
+		}
 		sort.Slice(all, func(i, j int) bool {
-			return lessNodes(all[i], all[j], order, descending, metrics)
+			return lessNodes(all[i], all[j], order, descending)
 		})
 		out := make([]row, 0, len(all))
 		for _, node := range all {
@@ -50,25 +69,48 @@ func flatten(root *snapshot.Node, expanded map[string]bool, order sortColumn, de
 		return out
 	}
 
+	var visible map[*snapshot.Node]bool
+	if filter != nil {
+		visible = filteredNodeSet(root, filter)
+	}
+
 	out := make([]row, 0, 256)
 	var walk func(n *snapshot.Node, depth int)
 	walk = func(n *snapshot.Node, depth int) {
+		if visible != nil && !visible[n] {
+			return
+		}
 		out = append(out, row{Node: n, Depth: depth})
 		if !expanded[n.Path] {
 			return
 		}
-		for _, child := range sortedChildren(n.Children, order, descending, metrics) {
+		for _, child := range sortedChildren(n.Children, order, descending) {
 			walk(child, depth+1)
 		}
 	}
 
 	// Root is implicit; the tree starts at top-level znodes.
-	for _, child := range sortedChildren(root.Children, order, descending, metrics) {
+	for _, child := range sortedChildren(root.Children, order, descending) {
 		walk(child, 0)
 	}
 	return out
 }
 
+// filteredNodeSet returns the nodes accepted by filter together with all of
+// their ancestors.
+func filteredNodeSet(root *snapshot.Node, filter *treeFilter) map[*snapshot.Node]bool {
+	visible := make(map[*snapshot.Node]bool)
+	for _, node := range flattenAllNodes(root) {
+		if !filter.keep(node) {
+			continue
+		}
+		for n := node; n != nil && n != root && !visible[n]; n = n.Parent {
+			visible[n] = true
+		}
+	}
+	return visible
+}
+
 func flattenAllNodes(root *snapshot.Node) []*snapshot.Node {
 	out := make([]*snapshot.Node, 0, 256)
 	var walk func(n *snapshot.Node)
@@ -84,33 +126,34 @@ func flattenAllNodes(root *snapshot.Node) []*snapshot.Node {
 	return out
 }
 
-func sortedChildren(children []*snapshot.Node, order sortColumn, descending bool, metrics map[*snapshot.Node]treeMetrics) []*snapshot.Node {
+func sortedChildren(children []*snapshot.Node, order sortColumn, descending bool) []*snapshot.Node {
 	sorted := make([]*snapshot.Node, len(children))
 	copy(sorted, children)
 	sort.Slice(sorted, func(i, j int) bool {
-		return lessNodes(sorted[i], sorted[j], order, descending, metrics)
+		return lessNodes(sorted[i], sorted[j], order, descending)
 	})
 	return sorted
 }
 
-func lessNodes(left, right *snapshot.Node, order sortColumn, descending bool, metrics map[*snapshot.Node]treeMetrics) bool {
+func lessNodes(left, right *snapshot.Node, order sortColumn, descending bool) bool {
 	compare := 0
 	switch order {
 	case sortByNodeName:
 		compare = strings.Compare(left.ID, right.ID)
 	case sortByNodeSize:
-		compare = len(left.Data) - len(right.Data)
+		compare = left.DataLen() - right.DataLen()
 	case sortBySubtreeSize:
-		compare = metrics[left].subtreeSize - metrics[right].subtreeSize
+		compare = left.SubtreeSize() - right.SubtreeSize()
 	case sortByChildren:
 		compare = len(left.Children) - len(right.Children)
+	case sortByDescendants:
+		compare = left.DescendantCount() - right.DescendantCount()
 	case sortByModified:
-		switch {
-		case left.Stat.Mtime < right.Stat.Mtime:
-			compare = -1
-		case left.Stat.Mtime > right.Stat.Mtime:
-			compare = 1
-		}
+		compare = compareTimes(left.Stat.Mtime, right.Stat.Mtime)
+	case sortByCreated:
+		compare = compareTimes(left.Stat.Ctime, right.Stat.Ctime)
+	case sortByVersion:
+		compare = int(left.Stat.Version) - int(right.Stat.Version)
 	}
 
 	if compare != 0 {
@@ -132,22 +175,156 @@ func lessNodes(left, right *snapshot.Node, order sortColumn, descending bool, me
 	return left.Path < right.Path
 }
 
+func compareTimes(left, right int64) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	}
+	return 0
+}
+
+// Glyphs shown in the name column of the tree. Every glyph must be listed in
+// treeMarkers so the legend stays complete.
+const (
+	markerSelected   = ">"
+	markerCollapsed  = "+"
+	markerExpanded   = "-"
+	markerOversized  = "!"
+	markerViolation  = "x"
+	markerAdded      = "*"
+	markerChanged    = "~"
+	markerCompressed = "z"
+)
+
+type treeMarker struct {
+	glyph       string
+	description string
+}
+
+var treeMarkers = []treeMarker{
+	{glyph: markerSelected, description: "Selected node"},
+	{glyph: markerCollapsed, description: "Collapsed node with children"},
+	{glyph: markerExpanded, description: "Expanded node"},
+	{glyph: markerOversized, description: "Node data at or above the size warning threshold"},
+	{glyph: markerViolation, description: "Node violating the ACL policy"},
+	{glyph: markerAdded, description: "Node added since the baseline"},
+	{glyph: markerChanged, description: "Node whose data or ACL changed since the baseline"},
+	{glyph: markerCompressed, description: "Node data compressed with gzip, Snappy or zstd (ratio in the metadata)"},
+}
+
+// treeColor documents a style of node names in the tree legend.
+type treeColor struct {
+	style       lipgloss.Style
+	description string
+}
+
+var treeColors = []treeColor{
+	{style: treeNodeNameStyle, description: "Persistent node"},
+	{style: ephemeralNameStyle, description: "Ephemeral node, deleted when its session ends"},
+}
+
 func isFlatMode(order sortColumn) bool {
-	return order == sortByNodeSize || order == sortByModified
+	return order == sortByNodeSize || order == sortByModified || order == sortByCreated
+}
+
+// timeColumn returns the sort column and header of the time column, which
+// shows creation times while sorting by them and modification times
+// otherwise.
+func timeColumn(order sortColumn) (sortColumn, string) {
+	if order == sortByCreated {
+		return sortByCreated, "Created"
+	}
+	return sortByModified, "Modified"
+}
+
+// countColumn returns the sort column and header of the count column, which
+// shows data versions while sorting by them and child counts otherwise.
+func countColumn(order sortColumn) (sortColumn, string) {
+	if order == sortByVersion {
+		return sortByVersion, "Version"
+	}
+	return sortByChildren, "Children"
+}
+
+// countValue returns what the count column shows for node.
+func countValue(node *snapshot.Node, order sortColumn) int {
+	if order == sortByVersion {
+		return int(node.Stat.Version)
+	}
+	return len(node.Children)
 }
 
 var (
-	treeNodeNameStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Bold(true)
-	selectedRowStyle  = lipgloss.NewStyle().Reverse(true)
-	treeHeaderStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Bold(true)
+	treeNodeNameStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Bold(true)
+	ephemeralNameStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Bold(true)
+	selectedRowStyle   = lipgloss.NewStyle().Reverse(true)
+	treeHeaderStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Bold(true)
+	sortColumnStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("229"))
+	oversizedStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
 )
 
+// treeDisplay holds the user's display settings for the tree table.
+type treeDisplay struct {
+	times timeFormatter
+	// sizeWarning flags nodes whose data is at least this many bytes; zero
+	// disables the warning.
+	sizeWarning int
+	// violating marks the nodes breaking the ACL policy.
+	violating map[*snapshot.Node]bool
+	// baseline maps nodes that differ from the baseline to their marker.
+	baseline map[*snapshot.Node]string
+	// wrapNames continues names too long for the name column on extra lines
+	// instead of truncating them.
+	wrapNames bool
+	// sizeUnit is the unit of the node and subtree size columns.
+	sizeUnit sizeUnit
+	// showMzxid adds a column with the zxid that last modified each node.
+	showMzxid bool
+	// rowCache reuses rendered rows across frames; nil renders every row.
+	rowCache *rowCache
+	// generation tells rowCache when the rows were rebuilt, which may have
+	// changed their markers.
+	generation uint64
+	// compression marks nodes with compressed data; nil detects it on
+	// every render.
+	compression *compressionCache
+}
+
+// mzxidW fits a hex zxid: "0x" and 16 digits.
+const mzxidW = 18
+
+// tableWidth is the width left for the standard table columns once the
+// optional columns are laid out at the end of a width-wide tree.
+func (d treeDisplay) tableWidth(width int) int {
+	if d.mzxidShown(width) {
+		width -= mzxidW + 1
+	}
+	return width
+}
+
+// mzxidShown reports whether the mzxid column is on and a width-wide tree
+// still fits the standard columns next to it.
+func (d treeDisplay) mzxidShown(width int) bool {
+	return d.showMzxid && width-(mzxidW+1) >= minTableWidth()
+}
+
+// minTableWidth is the narrowest table that fits the standard columns
+// without the descendants column.
+func minTableWidth() int {
+	_, nodeW, subtreeW, childW, _, modifiedW := tableColumnWidths(0)
+	return minNameW + nodeW + subtreeW + childW + modifiedW + 4
+}
+
+var defaultTreeDisplay = treeDisplay{times: defaultTimeFormatter, sizeWarning: defaultSizeWarning}
+
 func renderTree(rows []row, selected *snapshot.Node, width int, expanded map[string]bool, order sortColumn, descending bool) string {
-	lines := renderTreeWindow(rows, selected, width, expanded, order, descending, nil, nil, "", 0, len(rows))
+	lines := renderTreeWindow(rows, selected, width, expanded, order, descending, defaultTreeDisplay, nil, "", 0, len(rows))
 	return strings.Join(lines, "\n")
 }
 
-func renderTreeWindow(rows []row, selected *snapshot.Node, width int, expanded map[string]bool, order sortColumn, descending bool, metrics map[*snapshot.Node]treeMetrics, matchNode *snapshot.Node, matchQuery string, offset, height int) []string {
+func renderTreeWindow(rows []row, selected *snapshot.Node, width int, expanded map[string]bool, order sortColumn, descending bool, display treeDisplay, matchNode *snapshot.Node, matchQuery string, offset, height int) []string {
 	if width < 10 {
 		width = 10
 	}
@@ -161,80 +338,203 @@ func renderTreeWindow(rows []row, selected *snapshot.Node, width int, expanded m
 	if maxOffset < 0 {
 		maxOffset = 0
 	}
-	if offset > maxOffset {
+	// Wrapped names make rows taller, so the offset that keeps the selection
+	// visible may be past the one-line-per-row maximum.
+	if offset > maxOffset && !display.wrapNames {
 		offset = maxOffset
 	}
 
-	if metrics == nil {
-		metrics = computeTreeMetrics(rows)
-	}
 	lines := make([]string, 0, height)
-	lines = append(lines, treeHeaderStyle.Render(formatTreeTableHeader(width, order, descending)))
+	header := formatTreeTableHeader(display.tableWidth(width), order, descending)
+	if display.mzxidShown(width) {
+		header += fmt.Sprintf(" %*s", mzxidW, "Mzxid")
+	}
+	lines = append(lines, treeHeaderStyle.Render(header))
 	dataHeight := height - 1
 	if dataHeight < 0 {
 		dataHeight = 0
 	}
-	for i := 0; i < dataHeight; i++ {
-		idx := offset + i
+	display.rowCache.prepare(rowCacheContext{
+		width:      width,
+		order:      order,
+		descending: descending,
+		times:      display.times,
+		sizeWarn:   display.sizeWarning,
+		wrapNames:  display.wrapNames,
+		sizeUnit:   display.sizeUnit,
+		showMzxid:  display.showMzxid,
+		generation: display.generation,
+	})
+	for idx := offset; len(lines)-1 < dataHeight; idx++ {
 		if idx >= len(rows) {
 			lines = append(lines, "")
 			continue
 		}
 		r := rows[idx]
-		prefix := "  "
-		if selected == r.Node {
-			prefix = "> "
+		key := rowCacheKey{
+			node:     r.Node,
+			depth:    r.Depth,
+			selected: selected == r.Node,
+			expanded: expanded[r.Node.Path],
 		}
-		indent := strings.Repeat("  ", r.Depth)
-		icon := " "
-		if !isFlatMode(order) {
-			if len(r.Node.Children) > 0 {
-				icon = "+"
-				if expanded[r.Node.Path] {
-					icon = "-"
-				}
-			}
+		if matchNode == r.Node {
+			key.matchQuery = matchQuery
+		}
+		rowLines, ok := display.rowCache.get(key)
+		if !ok {
+			rowLines = renderTreeRow(key, width, order, display)
+			display.rowCache.put(key, rowLines)
 		}
-		sizeInfo := sizeLabel(metrics[r.Node])
-		plainPrefix := prefix
-		displayName := fmt.Sprintf("%s%s%s %s", plainPrefix, indent, icon, r.Node.ID)
-		nameW, _, _, _, _ := tableColumnWidths(width)
-		nameCell := truncate(displayName, nameW)
-		if selected == r.Node {
-			if matchNode == r.Node && matchQuery != "" {
-				nameCell = styleNodeNameCell(nameCell, plainPrefix, indent, icon, matchQuery)
+		for _, line := range rowLines {
+			if len(lines)-1 >= dataHeight {
+				break
 			}
-			line := formatTreeTableRow(nameCell, sizeInfo, metrics[r.Node].subtreeSize, len(r.Node.Children), r.Node.Stat.Mtime, width)
-			line = selectedRowStyle.Width(width).Render(padToWidth(line, width))
 			lines = append(lines, line)
-		} else {
-			query := ""
-			if matchNode == r.Node {
-				query = matchQuery
+		}
+	}
+	return lines
+}
+
+// renderTreeRow renders the tree row described by key: the table row itself
+// followed by the continuation lines of a wrapped name.
+func renderTreeRow(key rowCacheKey, width int, order sortColumn, display treeDisplay) []string {
+	node := key.node
+	fullWidth := width
+	width = display.tableWidth(width)
+	nameW, nodeW, _, _, _, _ := tableColumnWidths(width)
+	mzxidCell := ""
+	if display.mzxidShown(fullWidth) {
+		mzxidCell = fmt.Sprintf(" %*s", mzxidW, fmt.Sprintf("0x%x", node.Stat.Mzxid))
+	}
+
+	prefix := " "
+	if key.selected {
+		prefix = markerSelected
+	}
+	if display.violating[node] {
+		prefix += markerViolation
+	} else if mark, ok := display.baseline[node]; ok {
+		prefix += mark
+	} else {
+		prefix += " "
+	}
+	indent := strings.Repeat("  ", key.depth)
+	icon := " "
+	if !isFlatMode(order) {
+		if len(node.Children) > 0 {
+			icon = markerCollapsed
+			if key.expanded {
+				icon = markerExpanded
 			}
-			nameCell = styleNodeNameCell(nameCell, plainPrefix, indent, icon, query)
-			line := formatTreeTableRow(nameCell, sizeInfo, metrics[r.Node].subtreeSize, len(r.Node.Children), r.Node.Stat.Mtime, width)
-			lines = append(lines, line)
 		}
 	}
+	var markers []string
+	oversized := display.sizeWarning > 0 && node.DataLen() >= display.sizeWarning
+	if oversized {
+		markers = append(markers, markerOversized)
+	}
+	if display.compression.info(node).ok {
+		markers = append(markers, markerCompressed)
+	}
+	sizeInfo := sizeLabel(markers, node.DataLen(), display.sizeUnit, nodeW)
+	subtreeInfo := display.sizeUnit.format(node.SubtreeSize())
+	displayName := fmt.Sprintf("%s%s%s %s", prefix, indent, icon, node.ID)
+	nameLines := []string{truncate(displayName, nameW)}
+	if display.wrapNames {
+		nameLines = wrapNameCell(displayName, nameW, lipgloss.Width(displayName)-lipgloss.Width(node.ID))
+	}
+	nameCell := nameLines[0]
+	stamp := node.Stat.Mtime
+	if order == sortByCreated {
+		stamp = node.Stat.Ctime
+	}
+	modified := display.times.format(stamp)
+
+	lines := make([]string, 0, len(nameLines))
+	if key.selected {
+		if key.matchQuery != "" {
+			nameCell = styleNodeNameCell(nameCell, prefix, indent, icon, key.matchQuery, treeNodeNameStyle)
+		}
+		line := formatTreeTableRow(nameCell, sizeInfo, subtreeInfo, countValue(node, order), node.DescendantCount(), modified, width, order, false)
+		lines = append(lines, selectedRowStyle.Width(fullWidth).Render(padToWidth(line+mzxidCell, fullWidth)))
+		for _, cont := range nameLines[1:] {
+			lines = append(lines, selectedRowStyle.Width(fullWidth).Render(padToWidth(cont, fullWidth)))
+		}
+		return lines
+	}
+
+	nameStyle := nodeNameStyle(node)
+	if oversized {
+		nameStyle = oversizedStyle
+		sizeInfo = oversizedStyle.Render(sizeInfo)
+	}
+	nameCell = styleNodeNameCell(nameCell, prefix, indent, icon, key.matchQuery, nameStyle)
+	line := formatTreeTableRow(nameCell, sizeInfo, subtreeInfo, countValue(node, order), node.DescendantCount(), modified, width, order, true)
+	lines = append(lines, line+mzxidCell)
+	for _, cont := range nameLines[1:] {
+		trimmed := strings.TrimLeft(cont, " ")
+		lines = append(lines, cont[:len(cont)-len(trimmed)]+nameStyle.Render(trimmed))
+	}
 	return lines
 }
 
+// wrapNameCell splits a name cell wider than nameW into its first line and
+// continuation lines indented by indentW, so that they line up under the
+// name. Without room for the name it falls back to truncating.
+func wrapNameCell(displayName string, nameW, indentW int) []string {
+	if lipgloss.Width(displayName) <= nameW || indentW >= nameW {
+		return []string{truncate(displayName, nameW)}
+	}
+	first, rest := splitAtWidth(displayName, nameW)
+	out := []string{first}
+	pad := strings.Repeat(" ", indentW)
+	for rest != "" {
+		var segment string
+		segment, rest = splitAtWidth(rest, nameW-indentW)
+		out = append(out, pad+segment)
+	}
+	return out
+}
+
+// splitAtWidth splits s after at most width cells, taking at least one rune.
+func splitAtWidth(s string, width int) (string, string) {
+	used := 0
+	for i, r := range s {
+		w := lipgloss.Width(string(r))
+		if used+w > width && i > 0 {
+			return s[:i], s[i:]
+		}
+		used += w
+	}
+	return s, ""
+}
+
+// treeRowHeight is the number of lines row r takes in a tree of the given
+// width, which is more than one when its wrapped name does not fit.
+func treeRowHeight(r row, width int, display treeDisplay) int {
+	if !display.wrapNames {
+		return 1
+	}
+	nameW, _, _, _, _, _ := tableColumnWidths(display.tableWidth(width))
+	indentW := 4 + 2*r.Depth
+	return len(wrapNameCell(strings.Repeat(" ", indentW)+r.Node.ID, nameW, indentW))
+}
+
 func formatTreeTableHeader(width int, order sortColumn, descending bool) string {
-	nameW, nodeW, subtreeW, childW, modifiedW := tableColumnWidths(width)
-	return fmt.Sprintf(
-		"%-*s %*s %*s %*s %*s",
-		nameW,
-		sortedHeaderLabel("Node name", sortByNodeName, order, descending),
-		nodeW,
-		sortedHeaderLabel("Node size", sortByNodeSize, order, descending),
-		subtreeW,
-		sortedHeaderLabel("Subtree size", sortBySubtreeSize, order, descending),
-		childW,
-		sortedHeaderLabel("Children", sortByChildren, order, descending),
-		modifiedW,
-		sortedHeaderLabel("Modified", sortByModified, order, descending),
-	)
+	nameW, nodeW, subtreeW, childW, descW, modifiedW := tableColumnWidths(width)
+	countCol, countLabel := countColumn(order)
+	timeCol, timeLabel := timeColumn(order)
+	cells := []string{
+		fmt.Sprintf("%-*s", nameW, sortedHeaderLabel("Node name", sortByNodeName, order, descending)),
+		fmt.Sprintf("%*s", nodeW, sortedHeaderLabel("Node size", sortByNodeSize, order, descending)),
+		fmt.Sprintf("%*s", subtreeW, sortedHeaderLabel("Subtree size", sortBySubtreeSize, order, descending)),
+		fmt.Sprintf("%*s", childW, sortedHeaderLabel(countLabel, countCol, order, descending)),
+	}
+	if descW > 0 {
+		cells = append(cells, fmt.Sprintf("%*s", descW, sortedHeaderLabel("Descendants", sortByDescendants, order, descending)))
+	}
+	cells = append(cells, fmt.Sprintf("%*s", modifiedW, sortedHeaderLabel(timeLabel, timeCol, order, descending)))
+	return strings.Join(cells, " ")
 }
 
 func sortedHeaderLabel(label string, col, active sortColumn, descending bool) string {
@@ -247,33 +547,53 @@ func sortedHeaderLabel(label string, col, active sortColumn, descending bool) st
 	return "  " + label
 }
 
-func formatTreeTableRow(name string, nodeSizeLabel string, subtreeSize, childCount int, mtime int64, width int) string {
-	nameW, nodeW, subtreeW, childW, modifiedW := tableColumnWidths(width)
-	nameCol := padToWidthANSI(name, nameW)
-	return fmt.Sprintf(
-		"%s %*s %*d %*d %-*s",
-		nameCol,
-		nodeW,
-		nodeSizeLabel,
-		subtreeW,
-		subtreeSize,
-		childW,
-		childCount,
-		modifiedW,
-		formatMTimeISO(mtime),
-	)
-}
-
-func tableColumnWidths(width int) (nameW, nodeW, subtreeW, childW, modifiedW int) {
+// formatTreeTableRow lays out one table row. With emphasize set, the cell of
+// the active sort column is highlighted; selected rows pass false because
+// nested styles would cancel their reverse video.
+func formatTreeTableRow(name, nodeSizeLabel, subtreeSizeLabel string, count, descendants int, modified string, width int, order sortColumn, emphasize bool) string {
+	nameW, nodeW, subtreeW, childW, descW, modifiedW := tableColumnWidths(width)
+	countCol, _ := countColumn(order)
+	timeCol, _ := timeColumn(order)
+	cell := func(col sortColumn, value string) string {
+		if emphasize && col == order {
+			return sortColumnStyle.Render(value)
+		}
+		return value
+	}
+	cells := []string{
+		padToWidthANSI(name, nameW),
+		cell(sortByNodeSize, padLeftANSI(nodeSizeLabel, nodeW)),
+		cell(sortBySubtreeSize, padLeftANSI(subtreeSizeLabel, subtreeW)),
+		cell(countCol, fmt.Sprintf("%*d", childW, count)),
+	}
+	if descW > 0 {
+		cells = append(cells, cell(sortByDescendants, fmt.Sprintf("%*d", descW, descendants)))
+	}
+	cells = append(cells, cell(timeCol, fmt.Sprintf("%-*s", modifiedW, modified)))
+	return strings.Join(cells, " ")
+}
+
+// minNameW fits the "  Node name" header.
+const minNameW = 11
+
+// tableColumnWidths lays out a table width cells wide. The descendants
+// column is dropped (descW 0) rather than squeezing the names below
+// minNameW.
+func tableColumnWidths(width int) (nameW, nodeW, subtreeW, childW, descW, modifiedW int) {
 	nodeW = 11    // "  Node size"
 	subtreeW = 14 // "  Subtree size"
 	childW = 10   // "  Children"
+	descW = 13    // "  Descendants"
 	modifiedW = 20
-	nameW = width - (nodeW + subtreeW + childW + modifiedW + 4)
-	if nameW < 11 { // "  Node name"
-		nameW = 11
+	nameW = width - (nodeW + subtreeW + childW + descW + modifiedW + 5)
+	if nameW < minNameW {
+		nameW += descW + 1
+		descW = 0
 	}
-	return nameW, nodeW, subtreeW, childW, modifiedW
+	if nameW < minNameW {
+		nameW = minNameW
+	}
+	return nameW, nodeW, subtreeW, childW, descW, modifiedW
 }
 
 func padToWidth(s string, width int) string {
@@ -287,7 +607,16 @@ func padToWidth(s string, width int) string {
 	return s + strings.Repeat(" ", width-w)
 }
 
-func styleNodeNameCell(nameCell, prefix string, depthIndent string, icon string, matchQuery string) string {
+// nodeNameStyle is the style of node's name in unselected rows: ephemeral
+// nodes stand out from persistent ones.
+func nodeNameStyle(node *snapshot.Node) lipgloss.Style {
+	if node.Stat.EphemeralOwner != 0 {
+		return ephemeralNameStyle
+	}
+	return treeNodeNameStyle
+}
+
+func styleNodeNameCell(nameCell, prefix string, depthIndent string, icon string, matchQuery string, nameStyle lipgloss.Style) string {
 	prefixText := fmt.Sprintf("%s%s%s ", prefix, depthIndent, icon)
 	if !strings.HasPrefix(nameCell, prefixText) {
 		return nameCell
@@ -297,74 +626,89 @@ func styleNodeNameCell(nameCell, prefix string, depthIndent string, icon string,
 		return nameCell
 	}
 	if matchQuery == "" {
-		return prefixText + treeNodeNameStyle.Render(visibleName)
+		return prefixText + nameStyle.Render(visibleName)
 	}
 	matchAt := strings.Index(visibleName, matchQuery)
 	if matchAt < 0 {
-		return prefixText + treeNodeNameStyle.Render(visibleName)
+		return prefixText + nameStyle.Render(visibleName)
 	}
 	matchEnd := matchAt + len(matchQuery)
 	if matchEnd > len(visibleName) {
 		matchEnd = len(visibleName)
 	}
-	before := treeNodeNameStyle.Render(visibleName[:matchAt])
-	matched := treeNodeNameStyle.Copy().
+	before := nameStyle.Render(visibleName[:matchAt])
+	matched := nameStyle.Copy().
 		Background(lipgloss.Color("226")).
 		Foreground(lipgloss.Color("0")).
 		Render(visibleName[matchAt:matchEnd])
-	after := treeNodeNameStyle.Render(visibleName[matchEnd:])
+	after := nameStyle.Render(visibleName[matchEnd:])
 	return prefixText + before + matched + after
 }
 
-func formatMTimeISO(millis int64) string {
-	return time.UnixMilli(millis).UTC().Format(time.RFC3339)
+func padLeftANSI(s string, width int) string {
+	if pad := width - lipgloss.Width(s); pad > 0 {
+		return strings.Repeat(" ", pad) + s
+	}
+	return s
 }
 
-func computeTreeMetrics(rows []row) map[*snapshot.Node]treeMetrics {
-	metrics := make(map[*snapshot.Node]treeMetrics, len(rows))
-	var fill func(node *snapshot.Node) treeMetrics
-	fill = func(node *snapshot.Node) treeMetrics {
-		if m, ok := metrics[node]; ok {
-			return m
-		}
-		total := len(node.Data)
-		for _, child := range node.Children {
-			total += fill(child).subtreeSize
-		}
-		m := treeMetrics{
-			nodeSize:    len(node.Data),
-			subtreeSize: total,
-		}
-		metrics[node] = m
-		return m
-	}
-	for _, r := range rows {
-		fill(r.Node)
-	}
-	return metrics
+// sizeUnit is the unit the tree's size columns are rendered in.
+type sizeUnit int
+
+const (
+	unitBytes sizeUnit = iota
+	unitKB
+	unitMB
+)
+
+// next cycles bytes, KB and MB.
+func (u sizeUnit) next() sizeUnit {
+	return (u + 1) % (unitMB + 1)
 }
 
-func buildTreeMetrics(root *snapshot.Node) map[*snapshot.Node]treeMetrics {
-	metrics := make(map[*snapshot.Node]treeMetrics)
-	var fill func(node *snapshot.Node) treeMetrics
-	fill = func(node *snapshot.Node) treeMetrics {
-		if m, ok := metrics[node]; ok {
-			return m
-		}
-		total := len(node.Data)
-		for _, child := range node.Children {
-			total += fill(child).subtreeSize
-		}
-		m := treeMetrics{nodeSize: len(node.Data), subtreeSize: total}
-		metrics[node] = m
-		return m
-	}
-	fill(root)
-	return metrics
+func (u sizeUnit) String() string {
+	switch u {
+	case unitKB:
+		return "KB"
+	case unitMB:
+		return "MB"
+	}
+	return "bytes"
+}
+
+// format renders n bytes in the unit. KB and MB always get two decimals so
+// the decimal points line up in a right-aligned column.
+func (u sizeUnit) format(n int) string {
+	switch u {
+	case unitKB:
+		return strconv.FormatFloat(float64(n)/(1<<10), 'f', 2, 64)
+	case unitMB:
+		return strconv.FormatFloat(float64(n)/(1<<20), 'f', 2, 64)
+	}
+	return strconv.Itoa(n)
+}
+
+// sizeLabel renders n bytes in unit after markers, falling back to a compact
+// size such as 256.0M when that would not fit width cells.
+func sizeLabel(markers []string, n int, unit sizeUnit, width int) string {
+	label := strings.Join(append(markers, unit.format(n)), " ")
+	if len(label) > width {
+		label = strings.Join(append(markers, compactSize(n)), " ")
+	}
+	return label
 }
 
-func sizeLabel(m treeMetrics) string {
-	return fmt.Sprintf("%d", m.nodeSize)
+// compactSize renders n bytes in at most 7 cells, e.g. 999, 1.5K or 256.0M.
+func compactSize(n int) string {
+	switch {
+	case n >= 1<<30:
+		return strconv.FormatFloat(float64(n)/(1<<30), 'f', 1, 64) + "G"
+	case n >= 1<<20:
+		return strconv.FormatFloat(float64(n)/(1<<20), 'f', 1, 64) + "M"
+	case n >= 1<<10:
+		return strconv.FormatFloat(float64(n)/(1<<10), 'f', 1, 64) + "K"
+	}
+	return strconv.Itoa(n)
 }
 
 func truncate(s string, max int) string {
@@ -1,11 +1,15 @@
 package tui
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/jowiho/zooxplorer/internal/snapshot"
+	"github.com/muesli/termenv"
 )
 
 func TestFlattenAndRenderTree(t *testing.T) {
@@ -16,7 +20,7 @@ func TestFlattenAndRenderTree(t *testing.T) {
 	root.Children = []*snapshot.Node{b, a}
 	a.Children = []*snapshot.Node{a1}
 
-	rows := flatten(root, map[string]bool{}, sortByNodeName, false, nil)
+	rows := flatten(root, map[string]bool{}, sortByNodeName, false)
 	if len(rows) != 2 {
 		t.Fatalf("expected 2 rows, got %d", len(rows))
 	}
@@ -24,7 +28,7 @@ func TestFlattenAndRenderTree(t *testing.T) {
 		t.Fatalf("unexpected row at index 0: %#v", rows[0])
 	}
 
-	rows = flatten(root, map[string]bool{"/a": true}, sortByNodeName, false, nil)
+	rows = flatten(root, map[string]bool{"/a": true}, sortByNodeName, false)
 	if len(rows) != 3 {
 		t.Fatalf("expected 3 rows after expand, got %d", len(rows))
 	}
@@ -32,7 +36,7 @@ func TestFlattenAndRenderTree(t *testing.T) {
 		t.Fatalf("unexpected row at index 1: %#v", rows[1])
 	}
 
-	view := renderTree(rows, a1, 80, map[string]bool{"/a": true}, sortByNodeName, false)
+	view := renderTree(rows, a1, 100, map[string]bool{"/a": true}, sortByNodeName, false)
 	view = stripANSI(view)
 	if !strings.Contains(view, "▲ Node name") || !strings.Contains(view, "Node size") || !strings.Contains(view, "Modified") {
 		t.Fatalf("expected table header in view:\n%s", view)
@@ -59,7 +63,7 @@ func TestFlattenSortByNodeSize(t *testing.T) {
 	root.Children = []*snapshot.Node{a, b}
 	a.Children = []*snapshot.Node{a1}
 
-	rows := flatten(root, map[string]bool{}, sortByNodeSize, true, nil)
+	rows := flatten(root, map[string]bool{}, sortByNodeSize, true)
 	if len(rows) != 3 {
 		t.Fatalf("expected 3 rows, got %d", len(rows))
 	}
@@ -80,7 +84,7 @@ func TestFlattenSortByModifiedIsGlobalAndFlat(t *testing.T) {
 	root.Children = []*snapshot.Node{a, b}
 	a.Children = []*snapshot.Node{a1}
 
-	rows := flatten(root, map[string]bool{}, sortByModified, false, nil)
+	rows := flatten(root, map[string]bool{}, sortByModified, false)
 	if len(rows) != 3 {
 		t.Fatalf("expected 3 rows, got %d", len(rows))
 	}
@@ -93,7 +97,260 @@ func TestFlattenSortByModifiedIsGlobalAndFlat(t *testing.T) {
 	}
 }
 
+func TestSortByCreatedIsFlatAndShowsCtime(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: "/"}
+	a := &snapshot.Node{ID: "a", Path: "/a", Parent: root, Stat: snapshot.StatPersisted{Ctime: 1_700_000_300_000, Mtime: 1_700_000_900_000}}
+	b := &snapshot.Node{ID: "b", Path: "/b", Parent: root, Stat: snapshot.StatPersisted{Ctime: 1_700_000_200_000}}
+	a1 := &snapshot.Node{ID: "a1", Path: "/a/a1", Parent: a, Stat: snapshot.StatPersisted{Ctime: 1_700_000_100_000}}
+	root.Children = []*snapshot.Node{a, b}
+	a.Children = []*snapshot.Node{a1}
+
+	rows := flatten(root, map[string]bool{}, sortByCreated, false)
+	if len(rows) != 3 || rows[0].Node != a1 || rows[1].Node != b || rows[2].Node != a {
+		t.Fatalf("expected oldest-created first across the tree, got %d rows", len(rows))
+	}
+	if !isFlatMode(sortByCreated) {
+		t.Fatal("expected sorting by creation time to be flat")
+	}
+
+	if header := formatTreeTableHeader(120, sortByCreated, false); !strings.Contains(header, "▲ Created") || strings.Contains(header, "Modified") {
+		t.Fatalf("expected a Created header, got %q", header)
+	}
+	lines := renderTreeWindow(rows, nil, 120, map[string]bool{}, sortByCreated, false, treeDisplay{times: defaultTimeFormatter}, nil, "", 0, 4)
+	if got := stripANSI(lines[3]); !strings.Contains(got, formatSnapshotTimeUTC(a.Stat.Ctime)) {
+		t.Fatalf("expected the creation time in the row, got %q", got)
+	}
+}
+
+func TestSortByVersionShowsVersionColumn(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: "/"}
+	a := &snapshot.Node{ID: "a", Path: "/a", Parent: root, Stat: snapshot.StatPersisted{Version: 3}}
+	b := &snapshot.Node{ID: "b", Path: "/b", Parent: root, Stat: snapshot.StatPersisted{Version: 41}}
+	a1 := &snapshot.Node{ID: "a1", Path: "/a/a1", Parent: a}
+	root.Children = []*snapshot.Node{a, b}
+	a.Children = []*snapshot.Node{a1}
+
+	rows := flatten(root, map[string]bool{}, sortByVersion, true)
+	if len(rows) != 2 || rows[0].Node != b || rows[1].Node != a {
+		t.Fatalf("expected the most rewritten sibling first, got %d rows", len(rows))
+	}
+
+	if header := formatTreeTableHeader(120, sortByVersion, true); !strings.Contains(header, "▼ Version") || strings.Contains(header, "Children") {
+		t.Fatalf("expected a Version header, got %q", header)
+	}
+	lines := renderTreeWindow(rows, nil, 120, map[string]bool{}, sortByVersion, true, treeDisplay{times: defaultTimeFormatter}, nil, "", 0, 3)
+	if got := stripANSI(lines[1]); !strings.Contains(got, " 41 ") {
+		t.Fatalf("expected the version in the row, got %q", got)
+	}
+}
+
+func TestSortByDescendantsKeepsHierarchy(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: "/"}
+	a := &snapshot.Node{ID: "a", Path: "/a", Parent: root}
+	b := &snapshot.Node{ID: "b", Path: "/b", Parent: root}
+	b1 := &snapshot.Node{ID: "b1", Path: "/b/b1", Parent: b}
+	b2 := &snapshot.Node{ID: "b2", Path: "/b/b2", Parent: b}
+	b11 := &snapshot.Node{ID: "b11", Path: "/b/b1/b11", Parent: b1}
+	root.Children = []*snapshot.Node{a, b}
+	b.Children = []*snapshot.Node{b1, b2}
+	b1.Children = []*snapshot.Node{b11}
+
+	rows := flatten(root, map[string]bool{"/b": true}, sortByDescendants, true)
+	if len(rows) != 4 || rows[0].Node != b || rows[1].Node != b1 || rows[2].Node != b2 || rows[3].Node != a {
+		t.Fatalf("expected the biggest subtrees first within each level, got %d rows", len(rows))
+	}
+	if isFlatMode(sortByDescendants) {
+		t.Fatal("expected sorting by descendants to keep the hierarchy")
+	}
+
+	if header := formatTreeTableHeader(100, sortByDescendants, true); !strings.Contains(header, "▼ Descendants") || !strings.Contains(header, "Children") {
+		t.Fatalf("expected a Descendants header next to Children, got %q", header)
+	}
+	lines := renderTreeWindow(rows, nil, 100, map[string]bool{"/b": true}, sortByDescendants, true, defaultTreeDisplay, nil, "", 0, 2)
+	if got := stripANSI(lines[1]); !regexp.MustCompile(`\b2\s+3\s`).MatchString(got) {
+		t.Fatalf("expected 2 children and 3 descendants in the row, got %q", got)
+	}
+}
+
+func TestTreeTableDropsDescendantsWhenNarrow(t *testing.T) {
+	if _, _, _, _, descW, _ := tableColumnWidths(77); descW != 0 {
+		t.Fatalf("expected no descendants column at width 77, got %d cells", descW)
+	}
+	header := formatTreeTableHeader(77, sortByNodeName, false)
+	if strings.Contains(header, "Descendants") || lipgloss.Width(header) != 77 {
+		t.Fatalf("expected a 77-cell header without descendants, got %q", header)
+	}
+
+	for _, width := range []int{150, 160, 170, 180} {
+		var model tea.Model = NewModel(sampleSnapshotTree())
+		model, _ = model.Update(tea.WindowSizeMsg{Width: width, Height: 30})
+		if got := strings.Count(model.View(), "\n") + 1; got != 30 {
+			t.Fatalf("expected a 30-line view at width %d, got %d lines", width, got)
+		}
+	}
+}
+
+func TestEphemeralNodesAreColored(t *testing.T) {
+	withANSIColors(t)
+	root := &snapshot.Node{ID: "/", Path: "/"}
+	eph := &snapshot.Node{ID: "lock", Path: "/lock", Parent: root, Stat: snapshot.StatPersisted{EphemeralOwner: 7}}
+	per := &snapshot.Node{ID: "config", Path: "/config", Parent: root}
+	root.Children = []*snapshot.Node{per, eph}
+	rows := flatten(root, map[string]bool{}, sortByNodeName, false)
+
+	lines := renderTreeWindow(rows, nil, 120, map[string]bool{}, sortByNodeName, false, defaultTreeDisplay, nil, "", 0, 3)
+	if !strings.Contains(lines[1], treeNodeNameStyle.Render("config")) {
+		t.Fatalf("expected the persistent node in the default style, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], ephemeralNameStyle.Render("lock")) {
+		t.Fatalf("expected the ephemeral node in its own style, got %q", lines[2])
+	}
+
+	lines = renderTreeWindow(rows, eph, 120, map[string]bool{}, sortByNodeName, false, defaultTreeDisplay, nil, "", 0, 3)
+	if strings.Contains(lines[2], ephemeralNameStyle.Render("lock")) {
+		t.Fatalf("expected the selected row's reverse video to take precedence, got %q", lines[2])
+	}
+}
+
+func TestFlattenFilteredKeepsAncestorsOfMatches(t *testing.T) {
+	root, _, b, _, b1 := sampleTree()
+	filter := &treeFilter{keep: func(n *snapshot.Node) bool { return n == b1 }}
+
+	rows := flattenFiltered(root, map[string]bool{"/b": true}, sortByNodeName, false, filter)
+	if len(rows) != 2 || rows[0].Node != b || rows[1].Node != b1 {
+		t.Fatalf("expected only /b and /b/b1, got %d rows", len(rows))
+	}
+
+	rows = flattenFiltered(root, map[string]bool{}, sortByModified, false, filter)
+	if len(rows) != 1 || rows[0].Node != b1 {
+		t.Fatalf("expected flat mode to keep only matches, got %d rows", len(rows))
+	}
+}
+
+func TestFormatTreeTableRowEmphasizesSortColumn(t *testing.T) {
+	withANSIColors(t)
+	_, nodeW, subtreeW, _, _, _ := tableColumnWidths(80)
+	sizeCell := sortColumnStyle.Render(fmt.Sprintf("%*s", nodeW, "4"))
+	subtreeCell := sortColumnStyle.Render(fmt.Sprintf("%*d", subtreeW, 6))
+
+	line := formatTreeTableRow("a", "4", "6", 1, 3, formatSnapshotTimeUTC(0), 80, sortByNodeSize, true)
+	if !strings.Contains(line, sizeCell) {
+		t.Fatalf("expected emphasized node-size cell in %q", line)
+	}
+	if strings.Contains(line, subtreeCell) {
+		t.Fatalf("expected only the sort column to be emphasized in %q", line)
+	}
+
+	line = formatTreeTableRow("a", "4", "6", 1, 3, formatSnapshotTimeUTC(0), 80, sortByNodeSize, false)
+	if strings.Contains(line, sizeCell) {
+		t.Fatalf("expected no emphasis when disabled in %q", line)
+	}
+}
+
+// withANSIColors makes lipgloss emit color codes for the rest of the test.
+func withANSIColors(t *testing.T) {
+	t.Helper()
+	previous := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(previous) })
+}
+
 func stripANSI(s string) string {
 	re := regexp.MustCompile(`\x1b\[[0-9;]*m`)
 	return re.ReplaceAllString(s, "")
 }
+
+func TestRenderTreeWindowWrapsLongNames(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	long := "a-" + strings.Repeat("segment-", 4) + "end"
+	child := &snapshot.Node{ID: long, Path: "/" + long, Parent: root}
+	next := &snapshot.Node{ID: "next", Path: "/next", Parent: root}
+	root.Children = []*snapshot.Node{child, next}
+	rows := flatten(root, map[string]bool{}, sortByNodeName, false)
+
+	display := defaultTreeDisplay
+	lines := renderTreeWindow(rows, nil, 100, map[string]bool{}, sortByNodeName, false, display, nil, "", 0, 6)
+	if !strings.Contains(stripANSI(lines[2]), "next") {
+		t.Fatalf("expected no continuation line with wrapping off, got %q", lines[2])
+	}
+
+	display.wrapNames = true
+	lines = renderTreeWindow(rows, nil, 100, map[string]bool{}, sortByNodeName, false, display, nil, "", 0, 6)
+	name := strings.Fields(stripANSI(lines[1]))[0]
+	for i := 2; i < 1+treeRowHeight(rows[0], 100, display); i++ {
+		cont := stripANSI(lines[i])
+		if !strings.HasPrefix(cont, "    ") || strings.TrimSpace(cont) == "" {
+			t.Fatalf("expected an indented continuation line, got %q", cont)
+		}
+		name += strings.TrimSpace(cont)
+	}
+	if name != long {
+		t.Fatalf("expected the wrapped lines to spell %q, got %q", long, name)
+	}
+	if treeRowHeight(rows[0], 100, display) < 2 || treeRowHeight(rows[1], 100, display) != 1 {
+		t.Fatal("expected only the long name to wrap")
+	}
+}
+
+func TestRenderTreeWindowSizesInKB(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	big := &snapshot.Node{ID: "big", Path: "/big", Parent: root, Data: make([]byte, 2<<20)}
+	small := &snapshot.Node{ID: "small", Path: "/small", Parent: root, Data: make([]byte, 512)}
+	root.Children = []*snapshot.Node{big, small}
+	rows := flatten(root, map[string]bool{}, sortByNodeName, false)
+
+	display := treeDisplay{times: defaultTimeFormatter, sizeUnit: unitKB}
+	lines := renderTreeWindow(rows, nil, 80, map[string]bool{}, sortByNodeName, false, display, nil, "", 0, 3)
+	bigLine, smallLine := stripANSI(lines[1]), stripANSI(lines[2])
+	if !strings.Contains(bigLine, " 2048.00 ") || !strings.Contains(smallLine, " 0.50 ") {
+		t.Fatalf("expected both sizes in KB, got %q and %q", bigLine, smallLine)
+	}
+	if strings.Index(bigLine, "2048.00")+len("2048") != strings.Index(smallLine, "0.50")+len("0") {
+		t.Fatalf("expected aligned decimal points:\n%s\n%s", bigLine, smallLine)
+	}
+}
+
+func TestRenderTreeWindowMzxidColumn(t *testing.T) {
+	root := &snapshot.Node{ID: "/", Path: ""}
+	child := &snapshot.Node{ID: "a", Path: "/a", Parent: root, Stat: snapshot.StatPersisted{Mzxid: 0x1000002a}}
+	root.Children = []*snapshot.Node{child}
+	rows := flatten(root, map[string]bool{}, sortByNodeName, false)
+
+	display := defaultTreeDisplay
+	lines := renderTreeWindow(rows, nil, 120, map[string]bool{}, sortByNodeName, false, display, nil, "", 0, 2)
+	if strings.Contains(stripANSI(lines[0]), "Mzxid") || strings.Contains(stripANSI(lines[1]), "0x1000002a") {
+		t.Fatalf("expected no mzxid column by default, got %q", lines[1])
+	}
+
+	display.showMzxid = true
+	lines = renderTreeWindow(rows, nil, 120, map[string]bool{}, sortByNodeName, false, display, nil, "", 0, 2)
+	header, line := stripANSI(lines[0]), stripANSI(lines[1])
+	if !strings.HasSuffix(header, " Mzxid") || !strings.HasSuffix(line, " 0x1000002a") {
+		t.Fatalf("expected the mzxid column in hex, got:\n%s\n%s", header, line)
+	}
+	if lipgloss.Width(line) != 120 {
+		t.Fatalf("expected the row to keep the tree width, got %d", lipgloss.Width(line))
+	}
+
+	lines = renderTreeWindow(rows, nil, 80, map[string]bool{}, sortByNodeName, false, display, nil, "", 0, 2)
+	if strings.Contains(stripANSI(lines[0]), "Mzxid") || lipgloss.Width(lines[1]) != 80 {
+		t.Fatalf("expected no mzxid column without room for it, got:\n%s\n%s", lines[0], lines[1])
+	}
+}
+
+func TestMzxidColumnKeepsTheViewHeight(t *testing.T) {
+	for _, width := range []int{150, 160, 200} {
+		var model tea.Model = NewModel(sampleSnapshotTree())
+		model, _ = model.Update(tea.WindowSizeMsg{Width: width, Height: 30})
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+		if got := strings.Count(model.View(), "\n") + 1; got != 30 {
+			t.Fatalf("expected a 30-line view at width %d, got %d lines", width, got)
+		}
+	}
+	var model tea.Model = NewModel(sampleSnapshotTree())
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 160, Height: 30})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if hint := model.(Model).keyHint; hint != "the tree is too narrow for the mzxid column" {
+		t.Fatalf("expected a hint that the column does not fit, got %q", hint)
+	}
+}